@@ -1,16 +1,37 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"os/user"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"gopherpay/internal/audit"
 	"gopherpay/internal/billing"
+	"gopherpay/internal/chain"
 	"gopherpay/internal/config"
 	"gopherpay/internal/db"
+	"gopherpay/internal/pii"
+	"gopherpay/internal/privacy"
+	"gopherpay/internal/sanctions"
+	"gopherpay/internal/scheduler"
+	"gopherpay/internal/wallet"
+
+	"github.com/google/uuid"
 )
 
 func main() {
@@ -23,6 +44,23 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Key management and verification work without a database
+	if len(os.Args) >= 2 {
+		switch os.Args[1] {
+		case "keygen":
+			runKeygen(cfg, os.Args[2:])
+			return
+		case "verify":
+			runVerify(os.Args[2:])
+			return
+		case "pii":
+			if len(os.Args) >= 3 && os.Args[2] == "newkey" {
+				runPIINewKey()
+				return
+			}
+		}
+	}
+
 	// Connect DB
 	database, err := db.NewPostgresConnection(ctx, cfg)
 	if err != nil {
@@ -30,9 +68,26 @@ func main() {
 	}
 	defer database.Close()
 
+	keyring, err := pii.LoadKeyring(cfg.PIIKeyringFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Changes made from the CLI are audited like those made through the API
+	recorder := audit.NewRecorder(audit.NewPostgresRepository(database), cliSource())
+
 	// Billing
+	accountRepo := wallet.NewPostgresRepository(database, keyring)
 	reportRepo := billing.NewPostgresReportRepository(database)
-	reportService := billing.NewReportService(reportRepo)
+	reportService := billing.NewReportService(reportRepo, accountRepo, cfg.Currency)
+
+	s3Config := billing.S3Config{
+		Endpoint:        cfg.S3Endpoint,
+		Region:          cfg.S3Region,
+		AccessKeyID:     cfg.S3AccessKeyID,
+		SecretAccessKey: cfg.S3SecretAccessKey,
+		PathStyle:       cfg.S3PathStyle,
+	}
 
 	// Check command
 	if len(os.Args) < 2 {
@@ -51,36 +106,120 @@ func main() {
 		reportCmd := flag.NewFlagSet("report", flag.ExitOnError)
 
 		user := reportCmd.String("user", "", "Account number")
-		output := reportCmd.String("output", "", "Output CSV filename")
+		all := reportCmd.Bool("all", false, "Generate statements for every account")
+		filter := reportCmd.String("filter", "", "With --all: account number pattern, e.g. ACC1*")
+		workers := reportCmd.Int("workers", 4, "With --all: statements generated in parallel")
+		output := reportCmd.String("output", "", "Output filename (directory name with --all); s3:// or file:// URLs choose the store")
+		format := reportCmd.String("format", billing.FormatCSV, "Output format: "+strings.Join(billing.Formats, ", "))
+		fromFlag := reportCmd.String("from", "", "Statement start date YYYY-MM-DD (inclusive)")
+		toFlag := reportCmd.String("to", "", "Statement end date YYYY-MM-DD (exclusive, default now)")
+		compress := reportCmd.String("compress", billing.CompressionNone, "Compress output: gzip or zstd")
+		sign := reportCmd.Bool("sign", false, "Write a detached signature with REPORT_SIGNING_KEY")
+
+		var encryptTo []string
+		reportCmd.Func("encrypt-to", "Encrypt to an age recipient (age1...) or @recipients-file; repeatable", func(v string) error {
+			encryptTo = append(encryptTo, v)
+			return nil
+		})
 
 		reportCmd.Parse(os.Args[2:])
 
-		if *user == "" {
+		if !billing.IsFormat(*format) {
+			fmt.Println("unsupported --format:", *format)
+			os.Exit(1)
+		}
+
+		outputOpts := billing.OutputOptions{Compression: *compress}
+		if err := outputOpts.Validate(); err != nil {
+			fmt.Println("invalid --compress:", err)
+			os.Exit(1)
+		}
+		if outputOpts.Recipients, err = billing.ParseRecipients(encryptTo); err != nil {
+			fmt.Println("invalid --encrypt-to:", err)
+			os.Exit(1)
+		}
+		if *sign {
+			if outputOpts.SigningKey, err = billing.LoadSigningKey(cfg.ReportSigningKey); err != nil {
+				fmt.Println("cannot load signing key:", err)
+				fmt.Println("create one with: gopherpay keygen")
+				os.Exit(1)
+			}
+		}
+
+		if (*user == "") == !*all {
 			fmt.Println("Usage:")
-			fmt.Println("  report --user=ACC1001")
+			fmt.Println("  report --user=ACC1001 [--from=2026-01-01] [--to=2026-02-01]")
+			fmt.Println("  report --all [--filter=ACC1*] [--workers=4] [--output=dirname]")
+			os.Exit(1)
+		}
+
+		from, err := parseDate(*fromFlag)
+		if err != nil {
+			fmt.Println("invalid --from:", err)
+			os.Exit(1)
+		}
+		to, err := parseDate(*toFlag)
+		if err != nil {
+			fmt.Println("invalid --to:", err)
 			os.Exit(1)
 		}
 
-		// Ensure Reports directory exists and write file there
-		outDir := "Reports"
-		if err := os.MkdirAll(outDir, 0o755); err != nil {
-			fmt.Println("failed to create Reports dir:", err)
+		// Reports go to REPORT_STORE unless --output is a URL
+		store, err := billing.OpenReportStore(cfg.ReportStore, s3Config)
+		if err != nil {
+			fmt.Println(err)
 			os.Exit(1)
 		}
 
+		if *all {
+			dir := bulkDirName(*output, *format, from, to)
+			if isURL(*output) {
+				if store, err = billing.OpenReportStore(*output, s3Config); err != nil {
+					fmt.Println("invalid --output:", err)
+					os.Exit(1)
+				}
+				dir = ""
+			}
+
+			runBulkReport(ctx, reportService, billing.BulkOptions{
+				Store:   store,
+				Dir:     dir,
+				Filter:  *filter,
+				Format:  *format,
+				From:    from,
+				To:      to,
+				Workers: *workers,
+				Output:  outputOpts,
+			})
+			return
+		}
+
 		filename := *output
 		if filename == "" {
-			filename = fmt.Sprintf("%s_report.csv", *user)
+			filename = fmt.Sprintf("%s_report.%s%s", *user, billing.FileExtension(*format), outputOpts.Suffix())
 		}
 
-		// sanitize and place inside Reports directory
-		filename = filepath.Base(filename)
-		fullpath := filepath.Join(outDir, filename)
+		if isURL(filename) {
+			location, name := path.Split(filename)
+			if store, err = billing.OpenReportStore(location, s3Config); err != nil {
+				fmt.Println("invalid --output:", err)
+				os.Exit(1)
+			}
+			filename = name
+		} else {
+			// sanitize and place inside the report store
+			filename = filepath.Base(filename)
+		}
 
-		err := reportService.GenerateReport(
+		err = reportService.GenerateReport(
 			ctx,
 			*user,
-			fullpath,
+			from,
+			to,
+			*format,
+			outputOpts,
+			store,
+			filename,
 		)
 
 		if err != nil {
@@ -88,7 +227,175 @@ func main() {
 			os.Exit(1)
 		}
 
-		fmt.Println("Report generated:", fullpath)
+		fmt.Println("Report generated:", store.Location(filename))
+		if *sign {
+			fmt.Println("Signature:", store.Location(filename+billing.SignatureExt))
+		}
+
+	// ========================================
+	// SUMMARY
+	// ========================================
+
+	case "summary":
+
+		summaryCmd := flag.NewFlagSet("summary", flag.ExitOnError)
+
+		fromFlag := summaryCmd.String("from", "", "Start date YYYY-MM-DD (inclusive)")
+		toFlag := summaryCmd.String("to", "", "End date YYYY-MM-DD (exclusive)")
+		accounts := summaryCmd.String("accounts", "", "Limit top counterparties to accounts matching this pattern, e.g. ACC1*")
+		top := summaryCmd.Int("top", billing.DefaultTopCounterparties, "Counterparties listed per account")
+		format := summaryCmd.String("format", billing.SummaryCSV, "Output format: csv or json")
+		output := summaryCmd.String("output", "", "Write to this file in the report store (or s3:// URL) instead of stdout")
+
+		summaryCmd.Parse(os.Args[2:])
+
+		if !billing.IsSummaryFormat(*format) {
+			fmt.Println("unsupported --format:", *format)
+			os.Exit(1)
+		}
+
+		filter := billing.SummaryFilter{Accounts: *accounts, Top: *top}
+		if filter.From, err = parseDate(*fromFlag); err != nil {
+			fmt.Println("invalid --from:", err)
+			os.Exit(1)
+		}
+		if filter.To, err = parseDate(*toFlag); err != nil {
+			fmt.Println("invalid --to:", err)
+			os.Exit(1)
+		}
+
+		summary, err := reportService.Summary(ctx, filter)
+		if err != nil {
+			fmt.Println("Summary failed:", err)
+			os.Exit(1)
+		}
+
+		if *output == "" {
+			if err := billing.WriteSummary(os.Stdout, summary, *format); err != nil {
+				fmt.Println("Summary failed:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		location, name := cfg.ReportStore, filepath.Base(*output)
+		if isURL(*output) {
+			location, name = path.Split(*output)
+		}
+		store, err := billing.OpenReportStore(location, s3Config)
+		if err != nil {
+			fmt.Println("invalid --output:", err)
+			os.Exit(1)
+		}
+
+		obj, err := store.Create(ctx, name)
+		if err != nil {
+			fmt.Println("Summary failed:", err)
+			os.Exit(1)
+		}
+		if err := billing.WriteSummary(obj, summary, *format); err != nil {
+			obj.Abort()
+			fmt.Println("Summary failed:", err)
+			os.Exit(1)
+		}
+		if err := obj.Commit(); err != nil {
+			fmt.Println("Summary failed:", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Summary written:", store.Location(name))
+
+	// ========================================
+	// SCHEDULE
+	// ========================================
+
+	case "schedule":
+
+		scheduleRepo := scheduler.NewPostgresRepository(database)
+		runScheduleCommand(ctx, scheduleRepo, os.Args[2:])
+
+	// ========================================
+	// LIMITS
+	// ========================================
+
+	case "limits":
+
+		limits, err := wallet.LoadLimitPolicy(cfg.LimitsFile)
+		if err != nil {
+			fmt.Println("invalid LIMITS_FILE:", err)
+			os.Exit(1)
+		}
+		walletService := wallet.NewWalletService(database, accountRepo, nil, limits, nil, nil, nil, 0, cfg.AdjustmentAccount)
+		runLimitsCommand(ctx, walletService, recorder, os.Args[2:])
+
+	// ========================================
+	// ADJUST
+	// ========================================
+
+	case "adjust":
+
+		walletService := wallet.NewWalletService(database, accountRepo, nil, nil, nil, nil, nil, 0, cfg.AdjustmentAccount)
+		runAdjustCommand(ctx, walletService, recorder, os.Args[2:])
+
+	// ========================================
+	// KYC
+	// ========================================
+
+	case "kyc":
+
+		kyc, err := wallet.LoadKYCPolicy(cfg.KYCPolicyFile)
+		if err != nil {
+			fmt.Println("invalid KYC_POLICY_FILE:", err)
+			os.Exit(1)
+		}
+		walletService := wallet.NewWalletService(database, accountRepo, nil, nil, kyc, nil, nil, 0, cfg.AdjustmentAccount)
+		runKYCCommand(ctx, walletService, recorder, os.Args[2:])
+
+	// ========================================
+	// REVIEW
+	// ========================================
+
+	case "review":
+
+		// Approved reviews are executed by the server's worker pool
+		walletService := wallet.NewWalletService(database, accountRepo, nil, nil, nil, nil, nil, 0, cfg.AdjustmentAccount)
+		runReviewCommand(ctx, walletService, recorder, os.Args[2:])
+
+	// ========================================
+	// SANCTIONS
+	// ========================================
+
+	case "sanctions":
+
+		walletService := wallet.NewWalletService(database, accountRepo, nil, nil, nil, nil, nil, 0, cfg.AdjustmentAccount)
+		runSanctionsCommand(ctx, cfg, database, walletService, recorder, os.Args[2:])
+
+	// ========================================
+	// AUDIT
+	// ========================================
+
+	case "audit":
+
+		runAuditCommand(ctx, cfg, chain.NewPostgresRepository(database), recorder, os.Args[2:])
+
+	// ========================================
+	// GDPR
+	// ========================================
+
+	case "gdpr":
+
+		walletService := wallet.NewWalletService(database, accountRepo, nil, nil, nil, nil, nil, 0, cfg.AdjustmentAccount)
+		exporter := privacy.NewExporter(walletService, reportService, recorder)
+		runGDPRCommand(ctx, walletService, exporter, recorder, os.Args[2:])
+
+	// ========================================
+	// PII
+	// ========================================
+
+	case "pii":
+
+		walletService := wallet.NewWalletService(database, accountRepo, nil, nil, nil, nil, nil, 0, cfg.AdjustmentAccount)
+		runPIICommand(ctx, keyring, walletService, recorder, os.Args[2:])
 
 	// ========================================
 	// UNKNOWN
@@ -114,4 +421,1199 @@ func printUsage() {
 	fmt.Println("")
 	fmt.Println("Generate report with custom filename:")
 	fmt.Println("  gopherpay report --user=ACC1001 --output=myreport.csv")
+	fmt.Println("")
+	fmt.Println("Generate statement for a period:")
+	fmt.Println("  gopherpay report --user=ACC1001 --from=2026-01-01 --to=2026-02-01")
+	fmt.Println("")
+	fmt.Println("Generate printable PDF statement:")
+	fmt.Println("  gopherpay report --user=ACC1001 --format=pdf")
+	fmt.Println("")
+	fmt.Println("Generate bank statement for accounting software (camt053 or mt940):")
+	fmt.Println("  gopherpay report --user=ACC1001 --format=camt053")
+	fmt.Println("")
+	fmt.Println("Generate OFX file for personal finance apps:")
+	fmt.Println("  gopherpay report --user=ACC1001 --format=ofx")
+	fmt.Println("")
+	fmt.Println("Compress, encrypt and sign a report:")
+	fmt.Println("  gopherpay report --user=ACC1001 --compress=zstd --encrypt-to=age1... --sign")
+	fmt.Println("")
+	fmt.Println("Finance summary (volumes, failure reasons, top counterparties):")
+	fmt.Println("  gopherpay summary --from=2026-01-01 --to=2026-02-01 [--format=json] [--top=10] [--output=summary.csv]")
+	fmt.Println("")
+	fmt.Println("Write a report to an S3-compatible bucket:")
+	fmt.Println("  gopherpay report --user=ACC1001 --output=s3://statements/2026-01/ACC1001.csv")
+	fmt.Println("")
+	fmt.Println("Create the report signing key and check a signature:")
+	fmt.Println("  gopherpay keygen")
+	fmt.Println("  gopherpay verify --file=Reports/ACC1001_report.csv --pubkey=report-signing.pub.pem")
+	fmt.Println("")
+	fmt.Println("Generate statements for every account (resumable):")
+	fmt.Println("  gopherpay report --all --from=2026-01-01 --to=2026-02-01 [--filter=ACC1*] [--workers=8]")
+	fmt.Println("")
+	fmt.Println("Show or override an account's transfer limits:")
+	fmt.Println("  gopherpay limits get --user=ACC1001")
+	fmt.Println("  gopherpay limits set --user=ACC1001 --daily=500000 --hourly=20")
+	fmt.Println("")
+	fmt.Println("Correct a balance against ADJUSTMENT_ACCOUNT (negative amounts debit):")
+	fmt.Println(`  gopherpay adjust --user=ACC1001 --amount=-2500 --reason="duplicate card refund" --as=alice`)
+	fmt.Println("")
+	fmt.Println("Record a KYC document and verify an account:")
+	fmt.Println("  gopherpay kyc add-document --user=ACC1001 --type=passport --reference=X1234567 --as=alice")
+	fmt.Println("  gopherpay kyc set --user=ACC1001 --tier=basic --reason=\"passport checked\" --as=alice")
+	fmt.Println("")
+	fmt.Println("Review held transfers (approval needs a second principal):")
+	fmt.Println("  gopherpay review list [--status=pending]")
+	fmt.Println("  gopherpay review approve --id=42 --as=alice [--note=...]")
+	fmt.Println("")
+	fmt.Println("Check a name against the sanctions list and handle alerts:")
+	fmt.Println(`  gopherpay sanctions check --name="Ivan Petrov"`)
+	fmt.Println("  gopherpay sanctions alerts [--status=open]")
+	fmt.Println("")
+	fmt.Println("Verify the transaction hash chain and export anchors to keep elsewhere:")
+	fmt.Println("  gopherpay audit verify [--anchors=anchors.jsonl]")
+	fmt.Println("  gopherpay audit anchors --output=anchors.jsonl --sign")
+	fmt.Println("")
+	fmt.Println("Search the audit log of administrative actions:")
+	fmt.Println("  gopherpay audit events --target-id=ACC1001 [--action=account.*] [--actor=alice] [--from=2026-10-01]")
+	fmt.Println("")
+	fmt.Println("Export or erase an account holder's data (erasure cannot be undone):")
+	fmt.Println("  gopherpay gdpr export --user=ACC1001 --output=ACC1001-export.json --as=alice")
+	fmt.Println(`  gopherpay gdpr erase --user=ACC1001 --reason="erasure request 2026-10-01" --as=alice --confirm=ACC1001`)
+	fmt.Println("")
+	fmt.Println("Encrypt account PII with the active keyring key (after rotating keys):")
+	fmt.Println("  gopherpay pii newkey")
+	fmt.Println("  gopherpay pii rewrap [--batch=500]")
+	fmt.Println("")
+	fmt.Println("Schedule monthly statements for all accounts, run by the server:")
+	fmt.Println(`  gopherpay schedule add --name=monthly --cron="0 2 1 * *" --period=previous_month`)
+}
+
+// runKeygen creates the Ed25519 key pair used to sign reports
+func runKeygen(cfg *config.Config, args []string) {
+	cmd := flag.NewFlagSet("keygen", flag.ExitOnError)
+	out := cmd.String("out", cfg.ReportSigningKey, "Private key file; the public key is written next to it as .pub.pem")
+	cmd.Parse(args)
+
+	if _, err := os.Stat(*out); err == nil {
+		fmt.Println("refusing to overwrite existing key:", *out)
+		os.Exit(1)
+	}
+
+	pubPath := strings.TrimSuffix(*out, ".pem") + ".pub.pem"
+	if err := billing.GenerateSigningKey(*out, pubPath); err != nil {
+		fmt.Println("Key generation failed:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Private key:", *out)
+	fmt.Println("Public key: ", pubPath, "(give this to report recipients)")
+}
+
+// runVerify checks a report against its detached signature
+func runVerify(args []string) {
+	cmd := flag.NewFlagSet("verify", flag.ExitOnError)
+	file := cmd.String("file", "", "Report file")
+	sigFile := cmd.String("sig", "", "Signature file (default <file>.sig)")
+	pubkey := cmd.String("pubkey", "report-signing.pub.pem", "Trusted signer public key")
+	cmd.Parse(args)
+
+	if *file == "" {
+		fmt.Println("Usage: verify --file=report.csv [--sig=report.csv.sig] [--pubkey=report-signing.pub.pem]")
+		os.Exit(1)
+	}
+	if *sigFile == "" {
+		*sigFile = *file + billing.SignatureExt
+	}
+
+	pub, err := billing.LoadVerifyKey(*pubkey)
+	if err != nil {
+		fmt.Println("cannot load public key:", err)
+		os.Exit(1)
+	}
+	sig, err := os.ReadFile(*sigFile)
+	if err != nil {
+		fmt.Println("cannot read signature:", err)
+		os.Exit(1)
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		fmt.Println("cannot open report:", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	if err := billing.VerifySignature(f, sig, pub); err != nil {
+		fmt.Println("Verification FAILED:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Signature OK:", *file)
+}
+
+// runScheduleCommand manages the report schedules run by the server
+func runScheduleCommand(ctx context.Context, repo *scheduler.PostgresRepository, args []string) {
+	if len(args) < 1 {
+		printScheduleUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+
+	case "add":
+		cmd := flag.NewFlagSet("schedule add", flag.ExitOnError)
+		name := cmd.String("name", "", "Unique schedule name")
+		cronExpr := cmd.String("cron", "", `Cron expression, e.g. "0 2 1 * *"`)
+		format := cmd.String("format", billing.FormatCSV, "Output format: "+strings.Join(billing.Formats, ", "))
+		filter := cmd.String("filter", "", "Account number pattern, e.g. ACC1* (default all accounts)")
+		period := cmd.String("period", scheduler.PeriodPreviousMonth, "previous_day, previous_week, previous_month or all")
+		cmd.Parse(args[1:])
+
+		if *name == "" || *cronExpr == "" {
+			printScheduleUsage()
+			os.Exit(1)
+		}
+		if _, err := scheduler.ParseCron(*cronExpr); err != nil {
+			fmt.Println("invalid --cron:", err)
+			os.Exit(1)
+		}
+		if !billing.IsFormat(*format) {
+			fmt.Println("unsupported --format:", *format)
+			os.Exit(1)
+		}
+		if _, _, err := scheduler.PeriodBounds(*period, time.Now()); err != nil {
+			fmt.Println("invalid --period:", err)
+			os.Exit(1)
+		}
+
+		sch := &scheduler.Schedule{
+			Name:          *name,
+			CronExpr:      *cronExpr,
+			Format:        *format,
+			AccountFilter: *filter,
+			Period:        *period,
+			Enabled:       true,
+		}
+		if err := repo.CreateSchedule(ctx, sch); err != nil {
+			fmt.Println("Create schedule failed:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Schedule created:", sch.Name)
+
+	case "list":
+		schedules, err := repo.ListSchedules(ctx)
+		if err != nil {
+			fmt.Println("List schedules failed:", err)
+			os.Exit(1)
+		}
+		for _, sch := range schedules {
+			next := "pending"
+			if sch.NextRunAt != nil {
+				next = sch.NextRunAt.Local().Format(time.RFC3339)
+			}
+			fmt.Printf("%-20s %-15s %-8s %-15s filter=%q enabled=%t next=%s\n",
+				sch.Name, sch.CronExpr, sch.Format, sch.Period, sch.AccountFilter, sch.Enabled, next)
+		}
+
+	case "delete":
+		cmd := flag.NewFlagSet("schedule delete", flag.ExitOnError)
+		name := cmd.String("name", "", "Schedule name")
+		cmd.Parse(args[1:])
+
+		if err := repo.DeleteSchedule(ctx, *name); err != nil {
+			fmt.Println("Delete schedule failed:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Schedule deleted:", *name)
+
+	case "runs":
+		cmd := flag.NewFlagSet("schedule runs", flag.ExitOnError)
+		name := cmd.String("name", "", "Only runs of this schedule")
+		limit := cmd.Int("limit", 20, "Number of runs to show")
+		cmd.Parse(args[1:])
+
+		runs, err := repo.ListRuns(ctx, *name, *limit)
+		if err != nil {
+			fmt.Println("List runs failed:", err)
+			os.Exit(1)
+		}
+		for _, run := range runs {
+			took := "-"
+			if run.FinishedAt != nil {
+				took = run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String()
+			}
+			fmt.Printf("#%d schedule=%d slot=%s status=%s statements=%d took=%s dir=%s %s\n",
+				run.ID, run.ScheduleID, run.ScheduledFor.Local().Format(time.RFC3339),
+				run.Status, run.Statements, took, run.OutputDir, run.Error)
+		}
+
+	default:
+		printScheduleUsage()
+		os.Exit(1)
+	}
+}
+
+func printScheduleUsage() {
+	fmt.Println("Usage:")
+	fmt.Println(`  schedule add --name=monthly --cron="0 2 1 * *" [--format=pdf] [--filter=ACC1*] [--period=previous_month]`)
+	fmt.Println("  schedule list")
+	fmt.Println("  schedule delete --name=monthly")
+	fmt.Println("  schedule runs [--name=monthly] [--limit=20]")
+}
+
+// runLimitsCommand shows and overrides per-account transfer limits
+func runLimitsCommand(ctx context.Context, service *wallet.WalletService, recorder *audit.Recorder, args []string) {
+	if len(args) < 1 {
+		printLimitsUsage()
+		os.Exit(1)
+	}
+
+	cmd := flag.NewFlagSet("limits "+args[0], flag.ExitOnError)
+	user := cmd.String("user", "", "Account number")
+	as := cmd.String("as", defaultPrincipal(), "Acting principal")
+	maxAmount := cmd.Int64("max-amount", 0, "Maximum single transfer (cents, 0 = unlimited)")
+	daily := cmd.Int64("daily", 0, "Daily outgoing total (cents, 0 = unlimited)")
+	monthly := cmd.Int64("monthly", 0, "Monthly outgoing total (cents, 0 = unlimited)")
+	hourly := cmd.Int64("hourly", 0, "Transfers per hour (0 = unlimited)")
+	cmd.Parse(args[1:])
+
+	if *user == "" {
+		printLimitsUsage()
+		os.Exit(1)
+	}
+
+	var (
+		before *wallet.AccountLimits
+		limits *wallet.AccountLimits
+		action string
+		err    error
+	)
+
+	switch args[0] {
+
+	case "get":
+		limits, err = service.GetLimits(ctx, *user)
+
+	case "set":
+		// Only the flags given change; the rest of the override is kept
+		current, getErr := service.GetLimits(ctx, *user)
+		if getErr != nil {
+			fmt.Println("Set limits failed:", getErr)
+			os.Exit(1)
+		}
+		before = current
+
+		// Copied, so the audit diff still sees the old values
+		override := &wallet.LimitOverride{}
+		if current.Override != nil {
+			*override = *current.Override
+		}
+
+		cmd.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "max-amount":
+				override.MaxAmount = maxAmount
+			case "daily":
+				override.DailyTotal = daily
+			case "monthly":
+				override.MonthlyTotal = monthly
+			case "hourly":
+				override.HourlyCount = hourly
+			}
+		})
+
+		limits, err = service.SetLimitOverride(ctx, *user, override)
+		action = audit.ActionLimitsSet
+
+	case "clear":
+		before, _ = service.GetLimits(ctx, *user)
+		limits, err = service.SetLimitOverride(ctx, *user, nil)
+		action = audit.ActionLimitsClear
+
+	default:
+		printLimitsUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Println("Limits failed:", err)
+		os.Exit(1)
+	}
+
+	if action != "" {
+		event := cliEvent(*as, action, audit.TargetAccount, *user)
+		if before != nil {
+			event.Diff = audit.Diff(before.Override, limits.Override)
+		}
+		recorder.Record(ctx, event)
+	}
+
+	show := func(v int64) string {
+		if v == 0 {
+			return "unlimited"
+		}
+		return strconv.FormatInt(v, 10)
+	}
+	fmt.Printf("%s (tier %s, override %t)\n", limits.AccountNumber, limits.Tier, limits.Override != nil)
+	fmt.Println("  max amount:   ", show(limits.Effective.MaxAmount))
+	fmt.Println("  daily total:  ", show(limits.Effective.DailyTotal))
+	fmt.Println("  monthly total:", show(limits.Effective.MonthlyTotal))
+	fmt.Println("  hourly count: ", show(limits.Effective.HourlyCount))
+}
+
+func printLimitsUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  limits get --user=ACC1001")
+	fmt.Println("  limits set --user=ACC1001 [--max-amount=N] [--daily=N] [--monthly=N] [--hourly=N] [--as=alice]")
+	fmt.Println("  limits clear --user=ACC1001 [--as=alice]")
+}
+
+// runAdjustCommand books a balance adjustment, the only way to change a
+// balance outside a transfer
+func runAdjustCommand(ctx context.Context, service *wallet.WalletService, recorder *audit.Recorder, args []string) {
+	cmd := flag.NewFlagSet("adjust", flag.ExitOnError)
+	user := cmd.String("user", "", "Account number")
+	amount := cmd.Int64("amount", 0, "Cents to credit, or debit when negative")
+	reason := cmd.String("reason", "", "Why the balance is corrected")
+	as := cmd.String("as", defaultPrincipal(), "Acting principal")
+	cmd.Parse(args)
+
+	if *user == "" || *amount == 0 {
+		fmt.Println("Usage:")
+		fmt.Println(`  adjust --user=ACC1001 --amount=N --reason="..." [--as=alice]`)
+		os.Exit(1)
+	}
+
+	adj, err := service.AdjustBalance(ctx, *user, *amount, *as, *reason, uuid.New().String())
+	if err != nil {
+		fmt.Println("Adjustment failed:", err)
+		os.Exit(1)
+	}
+
+	event := cliEvent(*as, audit.ActionBalanceAdjust, audit.TargetAccount, adj.AccountNumber)
+	event.RequestID = adj.RequestID
+	event.Diff = audit.Diff(map[string]any{"balance": adj.BalanceBefore}, map[string]any{"balance": adj.Balance})
+	event.Details = map[string]any{"amount": adj.Amount, "reason": adj.Reason}
+	recorder.Record(ctx, event)
+
+	fmt.Printf("%s balance %d -> %d (request %s)\n", adj.AccountNumber, adj.BalanceBefore, adj.Balance, adj.RequestID)
+}
+
+// runKYCCommand shows and changes an account's KYC tier and documents
+func runKYCCommand(ctx context.Context, service *wallet.WalletService, recorder *audit.Recorder, args []string) {
+	if len(args) < 1 {
+		printKYCUsage()
+		os.Exit(1)
+	}
+
+	cmd := flag.NewFlagSet("kyc "+args[0], flag.ExitOnError)
+	user := cmd.String("user", "", "Account number")
+	as := cmd.String("as", defaultPrincipal(), "Acting principal")
+	tier := cmd.String("tier", "", "With set: "+strings.Join(wallet.KYCTiers, ", "))
+	reason := cmd.String("reason", "", "With set: reason recorded in the tier history")
+	docType := cmd.String("type", "", "With add-document: document type, e.g. passport")
+	reference := cmd.String("reference", "", "With add-document: document number")
+	country := cmd.String("country", "", "With add-document: issuing country")
+	expires := cmd.String("expires", "", "With add-document: expiry date YYYY-MM-DD")
+	cmd.Parse(args[1:])
+
+	if *user == "" {
+		printKYCUsage()
+		os.Exit(1)
+	}
+
+	var err error
+
+	switch args[0] {
+
+	case "get":
+
+	case "set":
+		before, getErr := service.GetKYC(ctx, *user)
+		if getErr != nil {
+			fmt.Println("KYC failed:", getErr)
+			os.Exit(1)
+		}
+		var after *wallet.AccountKYC
+		if after, err = service.SetKYCTier(ctx, *user, *tier, *as, *reason); err == nil {
+			event := cliEvent(*as, audit.ActionKYCTier, audit.TargetAccount, *user)
+			event.Diff = audit.Diff(map[string]any{"kyc_tier": before.Tier}, map[string]any{"kyc_tier": after.Tier})
+			event.Details = map[string]any{"reason": *reason}
+			recorder.Record(ctx, event)
+		}
+
+	case "add-document":
+		doc := &wallet.KYCDocument{Type: *docType, Reference: *reference, Country: *country}
+		if *expires != "" {
+			expiresAt, parseErr := time.Parse("2006-01-02", *expires)
+			if parseErr != nil {
+				fmt.Println("invalid --expires:", parseErr)
+				os.Exit(1)
+			}
+			doc.ExpiresAt = &expiresAt
+		}
+		if doc, err = service.AddKYCDocument(ctx, *user, doc, *as); err == nil {
+			event := cliEvent(*as, audit.ActionKYCDocument, audit.TargetAccount, *user)
+			event.Diff = audit.Diff(nil, map[string]any{
+				"type":      doc.Type,
+				"reference": doc.Reference,
+				"country":   doc.Country,
+			})
+			event.Details = map[string]any{"document_id": doc.ID}
+			recorder.Record(ctx, event)
+		}
+
+	default:
+		printKYCUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Println("KYC failed:", err)
+		os.Exit(1)
+	}
+
+	kyc, err := service.GetKYC(ctx, *user)
+	if err != nil {
+		fmt.Println("KYC failed:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s (KYC tier %s)\n", kyc.AccountNumber, kyc.Tier)
+	for _, doc := range kyc.Documents {
+		expiry := "-"
+		if doc.ExpiresAt != nil {
+			expiry = doc.ExpiresAt.Format("2006-01-02")
+		}
+		fmt.Printf("  document #%d %s %s country=%s expires=%s recorded_by=%q\n",
+			doc.ID, doc.Type, doc.Reference, doc.Country, expiry, doc.RecordedBy)
+	}
+	for _, change := range kyc.History {
+		fmt.Printf("  %s %s -> %s by %q %s\n",
+			change.CreatedAt.Local().Format(time.RFC3339), change.FromTier, change.ToTier, change.ChangedBy, change.Reason)
+	}
+}
+
+func printKYCUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  kyc get --user=ACC1001")
+	fmt.Println("  kyc add-document --user=ACC1001 --type=passport --reference=X1234567 [--country=IN] [--expires=2030-01-31] [--as=alice]")
+	fmt.Println("  kyc set --user=ACC1001 --tier=unverified|basic|full [--reason=...] [--as=alice]")
+}
+
+// runReviewCommand lists and decides transfers held for review. The
+// acting principal is --as, GOPHERPAY_PRINCIPAL or the OS user.
+func runReviewCommand(ctx context.Context, service *wallet.WalletService, recorder *audit.Recorder, args []string) {
+	if len(args) < 1 {
+		printReviewUsage()
+		os.Exit(1)
+	}
+
+	cmd := flag.NewFlagSet("review "+args[0], flag.ExitOnError)
+	id := cmd.Int64("id", 0, "Review ID")
+	as := cmd.String("as", defaultPrincipal(), "Acting principal")
+	note := cmd.String("note", "", "Note recorded with the decision")
+	amount := cmd.Int64("amount", 0, "New amount (cents) for adjust")
+	status := cmd.String("status", wallet.ReviewPending, "With list: pending, approved, rejected, executed or all")
+	limit := cmd.Int("limit", 50, "With list: number of reviews to show")
+	cmd.Parse(args[1:])
+
+	if args[0] == "list" {
+		if *status == "all" {
+			*status = ""
+		}
+		reviews, err := service.ListReviews(ctx, *status, *limit)
+		if err != nil {
+			fmt.Println("List reviews failed:", err)
+			os.Exit(1)
+		}
+		for _, review := range reviews {
+			printReview(&review)
+		}
+		return
+	}
+
+	if *id == 0 {
+		printReviewUsage()
+		os.Exit(1)
+	}
+
+	var (
+		review *wallet.Review
+		action string
+		err    error
+	)
+
+	before, _ := service.GetReview(ctx, *id)
+
+	switch args[0] {
+
+	case "show":
+		review, err = service.GetReview(ctx, *id)
+
+	case "approve":
+		review, err = service.ApproveReview(ctx, *id, *as, *note)
+		action = audit.ActionReviewApprove
+
+	case "reject":
+		review, err = service.RejectReview(ctx, *id, *as, *note)
+		action = audit.ActionReviewReject
+
+	case "adjust":
+		review, err = service.AdjustReview(ctx, *id, *amount, *as)
+		action = audit.ActionReviewAdjust
+
+	default:
+		printReviewUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Println("Review failed:", err)
+		os.Exit(1)
+	}
+
+	if action != "" {
+		event := cliEvent(*as, action, audit.TargetReview, strconv.FormatInt(review.ID, 10))
+		event.Diff = audit.Diff(audit.ReviewState(before), audit.ReviewState(review))
+		event.Details = map[string]any{
+			"from_account": review.FromAccount,
+			"to_account":   review.ToAccount,
+		}
+		recorder.Record(ctx, event)
+	}
+
+	printReview(review)
+}
+
+func printReview(review *wallet.Review) {
+	fmt.Printf("#%d %s %s -> %s amount=%d reason=%s status=%s initiated_by=%q",
+		review.ID, review.RequestID, review.FromAccount, review.ToAccount,
+		review.Amount, review.Reason, review.Status, review.InitiatedBy)
+	if len(review.AdjustedBy) > 0 {
+		fmt.Printf(" adjusted_by=%s", strings.Join(review.AdjustedBy, ","))
+	}
+	if review.DecidedBy != "" {
+		fmt.Printf(" decided_by=%q", review.DecidedBy)
+	}
+	if review.Note != "" {
+		fmt.Printf(" note=%q", review.Note)
+	}
+	fmt.Println()
+}
+
+// defaultPrincipal identifies the CLI user for the four-eyes check
+func defaultPrincipal() string {
+	if p := os.Getenv("GOPHERPAY_PRINCIPAL"); p != "" {
+		return p
+	}
+	if u, err := user.Current(); err == nil {
+		return "cli:" + u.Username
+	}
+	return ""
+}
+
+func printReviewUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  review list [--status=pending|approved|rejected|executed|all] [--limit=50]")
+	fmt.Println("  review show --id=42")
+	fmt.Println("  review adjust --id=42 --amount=N [--as=bob]")
+	fmt.Println("  review approve --id=42 [--as=alice] [--note=...]")
+	fmt.Println("  review reject --id=42 [--as=alice] [--note=...]")
+}
+
+// runSanctionsCommand checks names against SANCTIONS_LIST_FILE, resolves
+// alerts and freezes or unfreezes accounts
+func runSanctionsCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	database *sql.DB,
+	service *wallet.WalletService,
+	recorder *audit.Recorder,
+	args []string,
+) {
+
+	if len(args) < 1 {
+		printSanctionsUsage()
+		os.Exit(1)
+	}
+
+	cmd := flag.NewFlagSet("sanctions "+args[0], flag.ExitOnError)
+	name := cmd.String("name", "", "With check: name to screen")
+	id := cmd.Int64("id", 0, "Alert ID")
+	userFlag := cmd.String("user", "", "Account number to freeze or unfreeze")
+	as := cmd.String("as", defaultPrincipal(), "Acting principal")
+	note := cmd.String("note", "", "Note recorded with the resolution")
+	status := cmd.String("status", sanctions.AlertOpen, "With alerts: open, dismissed, confirmed or all")
+	limit := cmd.Int("limit", 50, "With alerts: number of alerts to show")
+	cmd.Parse(args[1:])
+
+	// Freezing works without a list
+	switch args[0] {
+	case "freeze", "unfreeze":
+		if *userFlag == "" {
+			printSanctionsUsage()
+			os.Exit(1)
+		}
+		before, _ := service.GetAccountByNumber(ctx, *userFlag)
+		acc, err := service.SetAccountFrozen(ctx, *userFlag, args[0] == "freeze")
+		if err != nil {
+			fmt.Println("Freeze failed:", err)
+			os.Exit(1)
+		}
+
+		action := audit.ActionAccountUnfreeze
+		if acc.Frozen {
+			action = audit.ActionAccountFreeze
+		}
+		event := cliEvent(*as, action, audit.TargetAccount, acc.AccountNumber)
+		if before != nil {
+			event.Diff = audit.Diff(map[string]any{"frozen": before.Frozen}, map[string]any{"frozen": acc.Frozen})
+		}
+		recorder.Record(ctx, event)
+
+		fmt.Printf("%s frozen=%t\n", acc.AccountNumber, acc.Frozen)
+		return
+	}
+
+	if cfg.SanctionsListFile == "" {
+		fmt.Println("SANCTIONS_LIST_FILE is not set")
+		os.Exit(1)
+	}
+	screener, err := sanctions.NewScreenerFromFile(
+		sanctions.NewPostgresRepository(database),
+		cfg.SanctionsListFile,
+		cfg.SanctionsMinScore,
+		cfg.SanctionsFreeze,
+	)
+	if err != nil {
+		fmt.Println("invalid sanctions list:", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+
+	case "check":
+		if *name == "" {
+			printSanctionsUsage()
+			os.Exit(1)
+		}
+		matches := screener.Check(*name)
+		if len(matches) == 0 {
+			fmt.Printf("No match for %q among %d entries\n", *name, screener.Len())
+			return
+		}
+		for _, m := range matches {
+			fmt.Printf("%.3f %s %q matched %q programs=%s\n",
+				m.Score, m.Entry.UID, m.Entry.Name, m.MatchedName, strings.Join(m.Entry.Programs, ";"))
+		}
+
+	case "alerts":
+		if *status == "all" {
+			*status = ""
+		}
+		alerts, err := screener.ListAlerts(ctx, *status, *limit)
+		if err != nil {
+			fmt.Println("List alerts failed:", err)
+			os.Exit(1)
+		}
+		for _, alert := range alerts {
+			printSanctionsAlert(&alert)
+		}
+
+	case "dismiss", "confirm":
+		if *id == 0 {
+			printSanctionsUsage()
+			os.Exit(1)
+		}
+		resolution := sanctions.AlertDismissed
+		if args[0] == "confirm" {
+			resolution = sanctions.AlertConfirmed
+		}
+		alert, err := screener.ResolveAlert(ctx, *id, resolution, *as, *note)
+		if err != nil {
+			fmt.Println("Resolve alert failed:", err)
+			os.Exit(1)
+		}
+
+		action := audit.ActionAlertDismiss
+		if resolution == sanctions.AlertConfirmed {
+			action = audit.ActionAlertConfirm
+		}
+		event := cliEvent(*as, action, audit.TargetAlert, strconv.FormatInt(alert.ID, 10))
+		event.Diff = audit.Diff(map[string]any{"status": sanctions.AlertOpen}, map[string]any{"status": alert.Status})
+		event.Details = map[string]any{"account_number": alert.AccountNumber, "note": *note}
+		recorder.Record(ctx, event)
+
+		printSanctionsAlert(alert)
+
+	default:
+		printSanctionsUsage()
+		os.Exit(1)
+	}
+}
+
+func printSanctionsAlert(alert *sanctions.Alert) {
+	fmt.Printf("#%d %s %q matched %s %q score=%.3f trigger=%s status=%s",
+		alert.ID, alert.AccountNumber, alert.ScreenedName, alert.EntryUID,
+		alert.MatchedName, alert.Score, alert.Trigger, alert.Status)
+	if alert.ResolvedBy != "" {
+		fmt.Printf(" resolved_by=%q", alert.ResolvedBy)
+	}
+	if alert.Note != "" {
+		fmt.Printf(" note=%q", alert.Note)
+	}
+	fmt.Println()
+}
+
+func printSanctionsUsage() {
+	fmt.Println("Usage:")
+	fmt.Println(`  sanctions check --name="Ivan Petrov"`)
+	fmt.Println("  sanctions alerts [--status=open|dismissed|confirmed|all] [--limit=50]")
+	fmt.Println("  sanctions dismiss --id=7 [--as=alice] [--note=...]")
+	fmt.Println("  sanctions confirm --id=7 [--as=alice] [--note=...]")
+	fmt.Println("  sanctions freeze --user=ACC1001 [--as=alice]")
+	fmt.Println("  sanctions unfreeze --user=ACC1001 [--as=alice]")
+}
+
+// runAuditCommand checks the transaction hash chain, manages its anchors
+// and searches the audit log. verify exits with status 2 when the chain
+// is broken.
+func runAuditCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	repo *chain.PostgresRepository,
+	recorder *audit.Recorder,
+	args []string,
+) {
+
+	if len(args) < 1 {
+		printAuditUsage()
+		os.Exit(1)
+	}
+
+	cmd := flag.NewFlagSet("audit "+args[0], flag.ExitOnError)
+	anchorsFile := cmd.String("anchors", "", "With verify: exported anchors to check the chain against")
+	output := cmd.String("output", "", "With anchors: file to write (default stdout)")
+	since := cmd.String("since", "", "With anchors: only anchors taken from YYYY-MM-DD")
+	sign := cmd.Bool("sign", false, "With anchors: write a detached signature with REPORT_SIGNING_KEY")
+	actor := cmd.String("actor", "", "With events: only actions by this principal")
+	action := cmd.String("action", "", "With events: only this action, or a prefix ending in *, e.g. account.*")
+	targetType := cmd.String("target-type", "", "With events: account, review, sanctions_alert, route or pii_keyring")
+	targetID := cmd.String("target-id", "", "With events: e.g. an account number")
+	requestID := cmd.String("request-id", "", "With events: only events of this API request")
+	from := cmd.String("from", "", "With events: from YYYY-MM-DD")
+	to := cmd.String("to", "", "With events: before YYYY-MM-DD")
+	limit := cmd.Int("limit", audit.DefaultLimit, "With events: number of events to show")
+	beforeID := cmd.Int64("before-id", 0, "With events: only events older than this ID")
+	cmd.Parse(args[1:])
+
+	switch args[0] {
+
+	case "verify":
+		var anchors []chain.Anchor
+		if *anchorsFile != "" {
+			var err error
+			if anchors, err = chain.ReadAnchorsFile(*anchorsFile); err != nil {
+				fmt.Println("invalid --anchors:", err)
+				os.Exit(1)
+			}
+		}
+
+		report, err := chain.Verify(ctx, repo, anchors)
+		if err != nil {
+			fmt.Println("Verify failed:", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Checked %d links (head %d %s), %d of %d anchors matched\n",
+			report.Checked, report.Head.Seq, report.Head.Hash, report.Anchors, len(anchors))
+		fmt.Printf("Not in the chain: %d rows from before it started, %d held for review\n",
+			report.Unsealed.Legacy, report.Unsealed.Held)
+
+		if brk := report.Break; brk != nil {
+			fmt.Println("CHAIN BROKEN:", brk.Problem)
+			if brk.Seq != 0 {
+				fmt.Println("  link:       ", brk.Seq)
+			}
+			if brk.TransactionID != 0 {
+				fmt.Println("  transaction:", brk.TransactionID)
+			}
+			os.Exit(2)
+		}
+
+		fmt.Println("Chain OK")
+
+	case "anchor":
+		anchor, err := repo.CreateAnchor(ctx)
+		if err != nil {
+			fmt.Println("Anchor failed:", err)
+			os.Exit(1)
+		}
+		if anchor == nil {
+			fmt.Println("The head is already anchored, or the chain is empty")
+			return
+		}
+		chain.WriteAnchors(os.Stdout, []chain.Anchor{*anchor})
+
+	case "anchors":
+		from, err := parseDate(*since)
+		if err != nil {
+			fmt.Println("invalid --since:", err)
+			os.Exit(1)
+		}
+		if *sign && *output == "" {
+			fmt.Println("--sign needs --output")
+			os.Exit(1)
+		}
+
+		anchors, err := repo.ListAnchors(ctx, from)
+		if err != nil {
+			fmt.Println("List anchors failed:", err)
+			os.Exit(1)
+		}
+
+		if *output == "" {
+			chain.WriteAnchors(os.Stdout, anchors)
+			return
+		}
+
+		var buf bytes.Buffer
+		chain.WriteAnchors(&buf, anchors)
+		if err := os.WriteFile(*output, buf.Bytes(), 0o644); err != nil {
+			fmt.Println("Export failed:", err)
+			os.Exit(1)
+		}
+
+		if *sign {
+			key, err := billing.LoadSigningKey(cfg.ReportSigningKey)
+			if err != nil {
+				fmt.Println("cannot load signing key:", err)
+				os.Exit(1)
+			}
+			sig, err := billing.SignDetached(bytes.NewReader(buf.Bytes()), key)
+			if err != nil {
+				fmt.Println("Signing failed:", err)
+				os.Exit(1)
+			}
+			if err := os.WriteFile(*output+billing.SignatureExt, sig, 0o644); err != nil {
+				fmt.Println("Signing failed:", err)
+				os.Exit(1)
+			}
+		}
+
+		fmt.Printf("Exported %d anchors to %s\n", len(anchors), *output)
+
+	case "events":
+		filter := audit.Filter{
+			Actor:      *actor,
+			Action:     *action,
+			TargetType: *targetType,
+			TargetID:   *targetID,
+			RequestID:  *requestID,
+			BeforeID:   *beforeID,
+			Limit:      *limit,
+		}
+		var err error
+		if filter.From, err = parseDate(*from); err != nil {
+			fmt.Println("invalid --from:", err)
+			os.Exit(1)
+		}
+		if filter.To, err = parseDate(*to); err != nil {
+			fmt.Println("invalid --to:", err)
+			os.Exit(1)
+		}
+
+		events, err := recorder.List(ctx, filter)
+		if err != nil {
+			fmt.Println("List events failed:", err)
+			os.Exit(1)
+		}
+		for _, event := range events {
+			printAuditEvent(&event)
+		}
+
+	default:
+		printAuditUsage()
+		os.Exit(1)
+	}
+}
+
+func printAuditUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  audit verify [--anchors=anchors.jsonl]")
+	fmt.Println("  audit anchor")
+	fmt.Println("  audit anchors [--since=2026-10-01] [--output=anchors.jsonl] [--sign]")
+	fmt.Println("  audit events [--actor=alice] [--action=account.*] [--target-type=account] [--target-id=ACC1001]")
+	fmt.Println("               [--request-id=...] [--from=2026-10-01] [--to=2026-11-01] [--limit=100] [--before-id=N]")
+}
+
+func printAuditEvent(event *audit.Event) {
+	fmt.Printf("#%d %s %s %s %s/%s source=%s",
+		event.ID, event.CreatedAt.Local().Format(time.RFC3339), event.Actor,
+		event.Action, event.TargetType, event.TargetID, event.Source)
+	if event.SourceIP != "" {
+		fmt.Printf(" ip=%s", event.SourceIP)
+	}
+	if event.RequestID != "" {
+		fmt.Printf(" request_id=%s", event.RequestID)
+	}
+	fmt.Println()
+
+	fields := make([]string, 0, len(event.Diff))
+	for field := range event.Diff {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	for _, field := range fields {
+		change := event.Diff[field]
+		fmt.Printf("  %s: %v -> %v\n", field, change.Before, change.After)
+	}
+	if len(event.Details) > 0 {
+		details, _ := json.Marshal(event.Details)
+		fmt.Printf("  details: %s\n", details)
+	}
+}
+
+// cliSource tells CLI events apart from API ones, and hosts apart
+func cliSource() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "cli"
+	}
+	return "cli:" + host
+}
+
+// cliEvent starts an audit event for an action taken by the CLI user
+func cliEvent(actor, action, targetType, targetID string) *audit.Event {
+	return &audit.Event{
+		Actor:      actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+	}
+}
+
+// runGDPRCommand serves data subject requests: export writes the holder's
+// data bundle as JSON, erase pseudonymizes their PII for good
+func runGDPRCommand(
+	ctx context.Context,
+	service *wallet.WalletService,
+	exporter *privacy.Exporter,
+	recorder *audit.Recorder,
+	args []string,
+) {
+
+	if len(args) < 1 {
+		printGDPRUsage()
+		os.Exit(1)
+	}
+
+	cmd := flag.NewFlagSet("gdpr "+args[0], flag.ExitOnError)
+	user := cmd.String("user", "", "Account number")
+	as := cmd.String("as", defaultPrincipal(), "Acting principal")
+	output := cmd.String("output", "", "With export: bundle file (default stdout)")
+	reason := cmd.String("reason", "", "With erase: why the account is erased, e.g. the request reference")
+	confirm := cmd.String("confirm", "", "With erase: the account number again")
+	cmd.Parse(args[1:])
+
+	if *user == "" {
+		printGDPRUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+
+	case "export":
+		bundle, err := exporter.Export(ctx, *user, *as)
+		if err != nil {
+			fmt.Println("Export failed:", err)
+			os.Exit(1)
+		}
+
+		event := cliEvent(*as, audit.ActionAccountExport, audit.TargetAccount, *user)
+		event.Details = map[string]any{"transactions": len(bundle.Transactions)}
+		recorder.Record(ctx, event)
+
+		out := os.Stdout
+		if *output != "" {
+			// The bundle holds unmasked PII
+			f, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if err != nil {
+				fmt.Println("Export failed:", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(bundle); err != nil {
+			fmt.Println("Export failed:", err)
+			os.Exit(1)
+		}
+
+		if *output != "" {
+			fmt.Printf("Exported %s (%d transactions) to %s\n", *user, len(bundle.Transactions), *output)
+		}
+
+	case "erase":
+		if *confirm != *user {
+			fmt.Println("Erasure cannot be undone; pass --confirm with the account number to proceed")
+			os.Exit(1)
+		}
+
+		before, _ := service.GetAccountByNumber(ctx, *user)
+		erasure, err := service.EraseAccount(ctx, *user, *as, *reason)
+		if err != nil {
+			fmt.Println("Erase failed:", err)
+			os.Exit(1)
+		}
+		after, _ := service.GetAccountByNumber(ctx, *user)
+
+		event := cliEvent(*as, audit.ActionAccountErase, audit.TargetAccount, *user)
+		event.Diff = audit.Diff(audit.AccountState(before), audit.AccountState(after))
+		event.Details = map[string]any{"reason": erasure.Reason, "erasure_id": erasure.ID}
+		recorder.Record(ctx, event)
+
+		fmt.Printf("Erased %s as %s (erasure #%d by %q)\n",
+			erasure.AccountNumber, erasure.Pseudonym, erasure.ID, erasure.ErasedBy)
+
+	default:
+		printGDPRUsage()
+		os.Exit(1)
+	}
+}
+
+func printGDPRUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  gdpr export --user=ACC1001 [--output=ACC1001-export.json] [--as=alice]")
+	fmt.Println("  gdpr erase --user=ACC1001 --reason=... --confirm=ACC1001 [--as=alice]")
+}
+
+// runPIINewKey prints a fresh random key for the PII keyring file
+func runPIINewKey() {
+	key, err := pii.NewKey()
+	if err != nil {
+		fmt.Println("Key generation failed:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(key)
+}
+
+// runPIICommand re-encrypts account PII under the active keyring key, in
+// batches so it can run against a live database
+func runPIICommand(
+	ctx context.Context,
+	keyring *pii.Keyring,
+	service *wallet.WalletService,
+	recorder *audit.Recorder,
+	args []string,
+) {
+
+	if len(args) < 1 || args[0] != "rewrap" {
+		printPIIUsage()
+		os.Exit(1)
+	}
+
+	cmd := flag.NewFlagSet("pii rewrap", flag.ExitOnError)
+	batch := cmd.Int("batch", 500, "Accounts re-encrypted per transaction")
+	as := cmd.String("as", defaultPrincipal(), "Acting principal")
+	cmd.Parse(args[1:])
+
+	if keyring == nil {
+		fmt.Println("PII_KEYRING_FILE is not set")
+		os.Exit(1)
+	}
+	if *batch <= 0 {
+		printPIIUsage()
+		os.Exit(1)
+	}
+
+	total := 0
+	for {
+		n, err := service.RewrapPII(ctx, *batch)
+		if err != nil {
+			fmt.Println("Rewrap failed:", err)
+			os.Exit(1)
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+		fmt.Printf("Re-encrypted %d accounts\n", total)
+	}
+
+	event := cliEvent(*as, audit.ActionPIIRewrap, audit.TargetKeyring, keyring.ActiveKeyID())
+	event.Details = map[string]any{"accounts": total}
+	recorder.Record(ctx, event)
+
+	fmt.Printf("All accounts use key %s (%d re-encrypted)\n", keyring.ActiveKeyID(), total)
+}
+
+func printPIIUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  pii newkey")
+	fmt.Println("  pii rewrap [--batch=500] [--as=alice]")
+}
+
+// runBulkReport generates statements for all matching accounts, printing
+// progress as it goes. Ctrl-C stops the run; rerunning the same command
+// resumes where it left off.
+func runBulkReport(ctx context.Context, reportService *billing.ReportService, opts billing.BulkOptions) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts.Progress = func(done, total int, accountNumber string, err error) {
+		status := "ok"
+		if err != nil {
+			status = "FAILED: " + err.Error()
+		}
+		fmt.Printf("[%d/%d] %s %s\n", done, total, accountNumber, status)
+	}
+
+	manifest, err := reportService.GenerateAll(ctx, opts)
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("Interrupted; rerun the same command to resume.")
+		os.Exit(130)
+	}
+	if err != nil {
+		fmt.Println("Bulk report failed:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Generated %d statements in %s\n", len(manifest.Entries), opts.Store.Location(opts.Dir))
+	fmt.Println("Manifest:", opts.Store.Location(path.Join(opts.Dir, billing.ManifestFile)))
+}
+
+// bulkDirName picks the output directory for --all. The default is derived
+// from the run parameters so that rerunning the same command resumes it.
+func bulkDirName(output, format string, from, to time.Time) string {
+	if output != "" {
+		return filepath.Base(output)
+	}
+
+	period := "all"
+	if !from.IsZero() {
+		period = from.Format("20060102")
+	}
+	if !to.IsZero() {
+		period += "-" + to.Format("20060102")
+	}
+
+	return fmt.Sprintf("statements_%s_%s", period, format)
+}
+
+// isURL reports whether an --output value names a store by URL
+func isURL(output string) bool {
+	return strings.Contains(output, "://")
+}
+
+// parseDate parses an optional YYYY-MM-DD flag value
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", value)
 }
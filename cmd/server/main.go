@@ -5,12 +5,22 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"gopherpay/internal/api"
+	"gopherpay/internal/audit"
 	"gopherpay/internal/billing"
+	"gopherpay/internal/chain"
 	"gopherpay/internal/config"
 	"gopherpay/internal/db"
 	"gopherpay/internal/logger"
+	"gopherpay/internal/pii"
+	"gopherpay/internal/privacy"
+	"gopherpay/internal/reportjob"
+	"gopherpay/internal/risk"
+	"gopherpay/internal/sanctions"
+	"gopherpay/internal/scheduler"
 	"gopherpay/internal/wallet"
 	"gopherpay/internal/worker"
 )
@@ -46,8 +56,92 @@ func main() {
 	// =====================================
 	// Initialize wallet service
 	// =====================================
-	repo := wallet.NewPostgresRepository(database)
-	service := wallet.NewWalletService(database, repo)
+	fees, err := wallet.LoadFeeSchedule(cfg.FeeScheduleFile)
+	if err != nil {
+		slog.Error("invalid fee schedule", "error", err)
+		os.Exit(1)
+	}
+
+	limits, err := wallet.LoadLimitPolicy(cfg.LimitsFile)
+	if err != nil {
+		slog.Error("invalid transfer limits", "error", err)
+		os.Exit(1)
+	}
+
+	kyc, err := wallet.LoadKYCPolicy(cfg.KYCPolicyFile)
+	if err != nil {
+		slog.Error("invalid KYC policy", "error", err)
+		os.Exit(1)
+	}
+
+	riskConfig, err := risk.LoadConfig(cfg.RiskRulesFile)
+	if err != nil {
+		slog.Error("invalid risk rules", "error", err)
+		os.Exit(1)
+	}
+
+	// Screening is off unless RISK_RULES_FILE is set
+	var riskEvaluator wallet.RiskEvaluator
+	if riskConfig != nil {
+		riskEvaluator = risk.NewEngine(risk.NewPostgresRepository(database), riskConfig)
+	}
+
+	// Sanctions screening is off unless SANCTIONS_LIST_FILE is set
+	var (
+		screener          *sanctions.Screener
+		sanctionsScreener wallet.SanctionsScreener
+	)
+	if cfg.SanctionsListFile != "" {
+		screener, err = sanctions.NewScreenerFromFile(
+			sanctions.NewPostgresRepository(database),
+			cfg.SanctionsListFile,
+			cfg.SanctionsMinScore,
+			cfg.SanctionsFreeze,
+		)
+		if err != nil {
+			slog.Error("invalid sanctions list", "error", err)
+			os.Exit(1)
+		}
+		sanctionsScreener = screener
+
+		slog.Info("sanctions list loaded",
+			"file", cfg.SanctionsListFile,
+			"entries", screener.Len(),
+			"freeze", cfg.SanctionsFreeze,
+		)
+	}
+
+	// PII is stored in plaintext unless PII_KEYRING_FILE is set
+	keyring, err := pii.LoadKeyring(cfg.PIIKeyringFile)
+	if err != nil {
+		slog.Error("invalid PII keyring", "error", err)
+		os.Exit(1)
+	}
+	if keyring != nil {
+		slog.Info("PII keyring loaded",
+			"file", cfg.PIIKeyringFile,
+			"active_key", keyring.ActiveKeyID(),
+		)
+		go reloadKeyringOnHangup(keyring)
+	}
+
+	repo := wallet.NewPostgresRepository(database, keyring)
+	service := wallet.NewWalletService(
+		database,
+		repo,
+		fees,
+		limits,
+		kyc,
+		riskEvaluator,
+		sanctionsScreener,
+		cfg.ReviewThreshold,
+		cfg.AdjustmentAccount,
+	)
+
+	if err := service.CheckFeeSchedule(ctx); err != nil {
+		slog.Error("invalid fee schedule", "error", err)
+		os.Exit(1)
+	}
 
 	// =====================================
 	// Start worker pool
@@ -59,25 +153,72 @@ func main() {
 	// Initialize billing/report service
 	// =====================================
 	reportRepo := billing.NewPostgresReportRepository(database)
-	reportService := billing.NewReportService(reportRepo)
+	reportService := billing.NewReportService(reportRepo, repo, cfg.Currency)
+
+	reportStore, err := billing.OpenReportStore(cfg.ReportStore, billing.S3Config{
+		Endpoint:        cfg.S3Endpoint,
+		Region:          cfg.S3Region,
+		AccessKeyID:     cfg.S3AccessKeyID,
+		SecretAccessKey: cfg.S3SecretAccessKey,
+		PathStyle:       cfg.S3PathStyle,
+	})
+	if err != nil {
+		slog.Error("invalid report store", "error", err)
+		os.Exit(1)
+	}
+
+	// =====================================
+	// Start report job workers (separate from the transfer pool)
+	// =====================================
+	reportJobs := reportjob.NewRunner(
+		reportjob.NewPostgresRepository(database),
+		reportService,
+		reportStore,
+		cfg.ReportJobWorkers,
+		cfg.ReportJobTimeout,
+	)
+	reportJobs.Start(ctx)
+
+	// =====================================
+	// Start report scheduler
+	// =====================================
+	if cfg.SchedulerEnabled {
+		scheduleRepo := scheduler.NewPostgresRepository(database)
+		reportScheduler := scheduler.NewScheduler(
+			scheduleRepo,
+			reportService,
+			reportStore,
+			cfg.SchedulerInterval,
+			cfg.SchedulerWorkers,
+		)
+		reportScheduler.Start(ctx)
+	}
+
+	// =====================================
+	// Anchor the transaction hash chain
+	// =====================================
+	if cfg.ChainAnchorInterval > 0 {
+		chain.NewAnchorer(chain.NewPostgresRepository(database), cfg.ChainAnchorInterval).Start(ctx)
+	}
 
 	// =====================================
 	// Setup HTTP server
 	// =====================================
+	recorder := audit.NewRecorder(audit.NewPostgresRepository(database), "api")
+
 	handler := &api.Handler{
-		Pool:   pool,
-		Wallet: service,
-		Report: reportService,
+		Pool:      pool,
+		Wallet:    service,
+		Report:    reportService,
+		Jobs:      reportJobs,
+		Sanctions: screener,
+		Privacy:   privacy.NewExporter(service, reportService, recorder),
+		Audit:     recorder,
 	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/transfer", handler.Transfer)
-	mux.HandleFunc("/accounts", handler.CreateAccount)
-	mux.HandleFunc("/admin/transactions", handler.AdminTransactions)
-
 	server := http.Server{
 		Addr:    cfg.ServerHost + ":" + cfg.ServerPort,
-		Handler: api.RequestIDMiddleware(mux),
+		Handler: api.RequestIDMiddleware(handler.Routes()),
 	}
 
 	slog.Info("server started",
@@ -94,3 +235,19 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// reloadKeyringOnHangup re-reads the keyring file on SIGHUP, so keys can
+// be added and the active key switched without a restart. A bad file is
+// logged and the keys in use are kept.
+func reloadKeyringOnHangup(keyring *pii.Keyring) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	for range hangup {
+		if err := keyring.Reload(); err != nil {
+			slog.Error("PII keyring reload failed", "error", err)
+			continue
+		}
+		slog.Info("PII keyring reloaded", "active_key", keyring.ActiveKeyID())
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"gopherpay/internal/audit"
+	"gopherpay/internal/pii"
+	"gopherpay/internal/wallet"
+)
+
+// ScopesHeader carries the caller's granted scopes, space separated. Like
+// X-Principal it is set by the authenticating proxy in front of the API.
+const ScopesHeader = "X-Scopes"
+
+// ScopePIIRead lets a caller see account PII unmasked
+const ScopePIIRead = "pii:read"
+
+// AccountResponse is an account as served by the API. Email, phone and
+// date of birth are masked unless the caller holds ScopePIIRead.
+type AccountResponse struct {
+	ID            int64      `json:"id"`
+	AccountNumber string     `json:"account_number"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Phone         string     `json:"phone"`
+	DOB           string     `json:"dob"`
+	Balance       int64      `json:"balance"`
+	Tier          string     `json:"tier"`
+	KYCTier       string     `json:"kyc_tier"`
+	Frozen        bool       `json:"frozen"`
+	ErasedAt      *time.Time `json:"erased_at,omitempty"`
+	Version       int64      `json:"version"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Masked        bool       `json:"masked"`
+}
+
+func newAccountResponse(acc *wallet.Account, unmasked bool) AccountResponse {
+	resp := AccountResponse{
+		ID:            acc.ID,
+		AccountNumber: acc.AccountNumber,
+		Name:          acc.Name,
+		Email:         acc.Email,
+		Phone:         acc.Phone,
+		Balance:       acc.Balance,
+		Tier:          acc.Tier,
+		KYCTier:       acc.KYCTier,
+		Frozen:        acc.Frozen,
+		ErasedAt:      acc.ErasedAt,
+		Version:       acc.Version,
+		CreatedAt:     acc.CreatedAt,
+		UpdatedAt:     acc.UpdatedAt,
+		Masked:        !unmasked,
+	}
+
+	if !acc.DOB.IsZero() {
+		resp.DOB = acc.DOB.Format("2006-01-02")
+	}
+
+	if !unmasked {
+		resp.Email = pii.MaskEmail(acc.Email)
+		resp.Phone = pii.MaskPhone(acc.Phone)
+		if resp.DOB != "" {
+			resp.DOB = "****-**-**"
+		}
+	}
+
+	return resp
+}
+
+func newAccountResponses(accounts []wallet.Account, unmasked bool) []AccountResponse {
+	resp := make([]AccountResponse, len(accounts))
+	for i := range accounts {
+		resp[i] = newAccountResponse(&accounts[i], unmasked)
+	}
+	return resp
+}
+
+// maskKYC hides document references (passport and ID numbers) unless
+// unmasked
+func maskKYC(kyc *wallet.AccountKYC, unmasked bool) *wallet.AccountKYC {
+	for i := range kyc.Documents {
+		maskDocument(&kyc.Documents[i], unmasked)
+	}
+	return kyc
+}
+
+func maskDocument(doc *wallet.KYCDocument, unmasked bool) *wallet.KYCDocument {
+	if !unmasked {
+		doc.Reference = pii.MaskTail(doc.Reference, 4)
+	}
+	return doc
+}
+
+func hasScope(r *http.Request, scope string) bool {
+	for _, granted := range strings.Fields(r.Header.Get(ScopesHeader)) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// unmaskPII reports whether the caller may see PII in clear, and audits
+// every request that does
+func (h *Handler) unmaskPII(r *http.Request) bool {
+	if !hasScope(r, ScopePIIRead) {
+		return false
+	}
+
+	event := newAuditEvent(r, audit.ActionPIIRead, audit.TargetRoute, r.URL.Path)
+	event.Details = map[string]any{"method": r.Method}
+	h.Audit.Record(r.Context(), event)
+
+	return true
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"gopherpay/internal/audit"
+	"gopherpay/internal/wallet"
+)
+
+type AdjustBalanceRequest struct {
+	Amount int64  `json:"amount"` // cents; negative debits the account
+	Reason string `json:"reason"`
+}
+
+// AdjustAccountBalance credits or debits an account against the
+// adjustment account, booking an adjustment transaction
+// POST /v1/admin/accounts/{number}/adjustments
+func (h *Handler) AdjustAccountBalance(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	var req AdjustBalanceRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	requestID := r.Context().Value(RequestIDKey).(string)
+
+	adj, err := h.Wallet.AdjustBalance(r.Context(), acctNum, req.Amount, principalFrom(r), req.Reason, requestID)
+	if err != nil {
+		writeAdjustmentError(w, err, acctNum)
+		return
+	}
+
+	event := newAuditEvent(r, audit.ActionBalanceAdjust, audit.TargetAccount, acctNum)
+	event.Diff = audit.Diff(map[string]any{"balance": adj.BalanceBefore}, map[string]any{"balance": adj.Balance})
+	event.Details = map[string]any{"amount": adj.Amount, "reason": adj.Reason}
+	h.Audit.Record(r.Context(), event)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(adj)
+}
+
+func writeAdjustmentError(w http.ResponseWriter, err error, acctNum string) {
+	switch {
+	case errors.Is(err, wallet.ErrAccountNotFound):
+		http.Error(w, "account not found", http.StatusNotFound)
+	case errors.Is(err, wallet.ErrAdjustmentsDisabled):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, wallet.ErrAdjustmentAmount),
+		errors.Is(err, wallet.ErrAdjustmentReasonRequired),
+		errors.Is(err, wallet.ErrAdjustmentAccount):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, wallet.ErrAdjustmentOverdraw),
+		errors.Is(err, wallet.ErrAccountErased):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, wallet.ErrPrincipalRequired):
+		http.Error(w, PrincipalHeader+" header is required", http.StatusUnauthorized)
+	default:
+		slog.Error("balance adjustment failed", "error", err, "account_number", acctNum)
+		http.Error(w, "failed to adjust balance", http.StatusInternalServerError)
+	}
+}
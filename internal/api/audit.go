@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"gopherpay/internal/audit"
+	"gopherpay/internal/wallet"
+)
+
+// newAuditEvent starts an event for the request's principal, request ID
+// and source address
+func newAuditEvent(r *http.Request, action, targetType, targetID string) *audit.Event {
+	requestID, _ := r.Context().Value(RequestIDKey).(string)
+
+	return &audit.Event{
+		Actor:      principalFrom(r),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		RequestID:  requestID,
+		SourceIP:   sourceIP(r),
+	}
+}
+
+// sourceIP is the client address. Like X-Principal, X-Forwarded-For is
+// trusted to come from the proxy in front of the API.
+func sourceIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		first, _, _ := strings.Cut(fwd, ",")
+		return strings.TrimSpace(first)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// auditAccount records an action on an account, diffing the states
+// before and after it
+func (h *Handler) auditAccount(r *http.Request, action, accountNumber string, before, after *wallet.Account) {
+	event := newAuditEvent(r, action, audit.TargetAccount, accountNumber)
+	event.Diff = audit.Diff(audit.AccountState(before), audit.AccountState(after))
+	h.Audit.Record(r.Context(), event)
+}
+
+// auditAdminQueries records every read of an admin route. Changes are
+// audited by their handlers, with a diff.
+func (h *Handler) auditAdminQueries(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isAdmin := strings.HasPrefix(r.URL.Path, "/v1/admin/") || strings.HasPrefix(r.URL.Path, "/admin/")
+		if r.Method != http.MethodGet || !isAdmin {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		query := map[string]any{}
+		for key, values := range r.URL.Query() {
+			query[key] = audit.Redact(key, strings.Join(values, ","))
+		}
+
+		event := newAuditEvent(r, audit.ActionAdminQuery, audit.TargetRoute, r.URL.Path)
+		event.Details = map[string]any{"status": sw.status}
+		if len(query) > 0 {
+			event.Details["query"] = query
+		}
+		h.Audit.Record(r.Context(), event)
+	})
+}
+
+// statusWriter remembers the response status for auditAdminQueries
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush keeps streamed exports working through the wrapper
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ListAuditEvents returns audit events newest first. Pass the last ID of
+// a page as before_id to fetch the next one.
+// GET /v1/admin/audit-events?actor=alice&action=account.*&target_type=account&target_id=ACC1001&from=2026-01-01
+func (h *Handler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter := audit.Filter{
+		Actor:      q.Get("actor"),
+		Action:     q.Get("action"),
+		TargetType: q.Get("target_type"),
+		TargetID:   q.Get("target_id"),
+		RequestID:  q.Get("request_id"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(q, "from"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTimeParam(q, "to"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.BeforeID, err = parseInt64Param(q, "before_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := parseInt64Param(q, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if limit < 0 {
+		http.Error(w, "limit must be positive", http.StatusBadRequest)
+		return
+	}
+	filter.Limit = int(limit)
+	if filter.Limit == 0 {
+		filter.Limit = audit.DefaultLimit
+	}
+	filter.Limit = min(filter.Limit, audit.MaxLimit)
+
+	events, err := h.Audit.List(r.Context(), filter)
+	if err != nil {
+		slog.Error("list audit events failed", "error", err)
+		http.Error(w, "failed to fetch audit events", http.StatusInternalServerError)
+		return
+	}
+
+	resp := map[string]any{"events": events}
+	if len(events) > 0 && len(events) == filter.Limit {
+		resp["next_before_id"] = events[len(events)-1].ID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gopherpay/internal/wallet"
+)
+
+var (
+	errMissingIfMatch = errors.New("If-Match header is required")
+	errInvalidIfMatch = errors.New("If-Match must be a strong ETag returned by GET")
+)
+
+// accountETag derives a strong entity tag from the account version
+func accountETag(acc *wallet.Account) string {
+	return `"` + strconv.FormatInt(acc.Version, 10) + `"`
+}
+
+// ifMatchVersion resolves the If-Match header to the account version the
+// caller expects. "*" matches whatever version is currently stored.
+func (h *Handler) ifMatchVersion(ctx context.Context, r *http.Request, accountNumber string) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, errMissingIfMatch
+	}
+
+	if header == "*" {
+		acc, err := h.Wallet.GetAccountByNumber(ctx, accountNumber)
+		if err != nil {
+			return 0, wallet.ErrAccountNotFound
+		}
+		return acc.Version, nil
+	}
+
+	// Weak validators cannot be used for If-Match (RFC 9110 13.1.1)
+	if strings.HasPrefix(header, "W/") || len(header) < 2 ||
+		header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, errInvalidIfMatch
+	}
+
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil {
+		return 0, errInvalidIfMatch
+	}
+
+	return version, nil
+}
+
+// writePreconditionError maps If-Match and versioning errors to HTTP status codes
+func writePreconditionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errMissingIfMatch):
+		http.Error(w, err.Error(), http.StatusPreconditionRequired)
+	case errors.Is(err, errInvalidIfMatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, wallet.ErrAccountNotFound):
+		http.Error(w, "account not found", http.StatusNotFound)
+	case errors.Is(err, wallet.ErrAccountErased):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, wallet.ErrVersionConflict):
+		http.Error(w, "account has changed, fetch it again and retry", http.StatusPreconditionFailed)
+	default:
+		http.Error(w, "failed to update account", http.StatusInternalServerError)
+	}
+}
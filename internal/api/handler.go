@@ -2,11 +2,22 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
+	"gopherpay/internal/audit"
 	"gopherpay/internal/billing"
+	"gopherpay/internal/privacy"
+	"gopherpay/internal/reportjob"
+	"gopherpay/internal/sanctions"
 	"gopherpay/internal/wallet"
 	"gopherpay/internal/worker"
 )
@@ -15,35 +26,54 @@ type Handler struct {
 	Pool   *worker.WorkerPool
 	Wallet *wallet.WalletService
 	Report *billing.ReportService
-}
+	Jobs   *reportjob.Runner
 
-// AdminTransactions returns all transactions or those for a specific account
-// GET /admin/transactions?account_number=ACC1234
-func (h *Handler) AdminTransactions(w http.ResponseWriter, r *http.Request) {
-	acctNum := r.URL.Query().Get("account_number")
+	// Sanctions is nil when screening is disabled
+	Sanctions *sanctions.Screener
 
-	txs, err := h.Report.FetchTransactions(r.Context(), acctNum)
-	if err != nil {
-		http.Error(w, "failed to fetch transactions", http.StatusInternalServerError)
-		return
-	}
+	Privacy *privacy.Exporter
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(txs)
+	// Audit records administrative actions
+	Audit *audit.Recorder
 }
 
 type TransferRequest struct {
 	FromAccount string `json:"from_account"`
 	ToAccount   string `json:"to_account"`
 	Amount      int64  `json:"amount"`
+	Type        string `json:"type,omitempty"` // selects the fee schedule; default "standard"
 }
 
 type TransferResponse struct {
-	RequestID string `json:"request_id"`
-	Status    string `json:"status"`
-	Message   string `json:"message,omitempty"`
+	RequestID string        `json:"request_id"`
+	Status    string        `json:"status"`
+	Message   string        `json:"message,omitempty"`
+	Breakdown *FeeBreakdown `json:"breakdown,omitempty"`
+}
+
+// FeeBreakdown shows what a transfer costs the sender. For a pending
+// transfer it is a quote; the fee is fixed when the transfer runs.
+type FeeBreakdown struct {
+	Amount     int64  `json:"amount"`
+	Fee        int64  `json:"fee"`
+	FeeKind    string `json:"fee_kind,omitempty"`
+	TotalDebit int64  `json:"total_debit"`
+}
+
+func newFeeBreakdown(result *wallet.TransferResult) *FeeBreakdown {
+	if result == nil {
+		return nil
+	}
+	return &FeeBreakdown{
+		Amount:     result.Amount,
+		Fee:        result.Fee,
+		FeeKind:    result.FeeKind,
+		TotalDebit: result.TotalDebit,
+	}
 }
 
+// Transfer moves money between two accounts, asynchronously unless ?sync=1
+// POST /v1/transfers
 func (h *Handler) Transfer(w http.ResponseWriter, r *http.Request) {
 
 	var req TransferRequest
@@ -74,16 +104,44 @@ func (h *Handler) Transfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Type == "" {
+		req.Type = wallet.DefaultTransferType
+	}
+
+	// The type picks the fee rule, so only types the schedule prices
+	if !slices.Contains(h.Wallet.TransferTypes(), req.Type) {
+		http.Error(w, "type must be one of: "+strings.Join(h.Wallet.TransferTypes(), ", "), http.StatusBadRequest)
+		return
+	}
+
 	requestID := r.Context().Value(RequestIDKey).(string)
 
 	// If caller requests synchronous processing (e.g. ?sync=1), run transfer inline
 	if r.URL.Query().Get("sync") == "1" {
-		err := h.Wallet.Transfer(r.Context(), req.FromAccount, req.ToAccount, req.Amount, requestID)
+		result, err := h.Wallet.Transfer(r.Context(), req.FromAccount, req.ToAccount, req.Amount, req.Type, requestID, principalFrom(r))
+		if errors.Is(err, wallet.ErrHeldForReview) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(TransferResponse{
+				RequestID: requestID,
+				Status:    wallet.StatusReview,
+				Message:   err.Error(),
+			})
+			return
+		}
+
 		if err != nil {
 			// Map common errors to HTTP status codes
 			status := http.StatusInternalServerError
 			msg := err.Error()
-			if msg == "insufficient funds" {
+			if errors.Is(err, wallet.ErrLimitExceeded) {
+				status = http.StatusUnprocessableEntity
+			} else if errors.Is(err, wallet.ErrRiskBlocked) ||
+				errors.Is(err, wallet.ErrKYCRequired) ||
+				errors.Is(err, wallet.ErrAccountFrozen) ||
+				errors.Is(err, wallet.ErrSanctionsHit) {
+				status = http.StatusForbidden
+			} else if msg == "insufficient funds" {
 				status = http.StatusBadRequest
 			} else if msg == "from account not found" || msg == "to account not found" {
 				status = http.StatusNotFound
@@ -107,6 +165,7 @@ func (h *Handler) Transfer(w http.ResponseWriter, r *http.Request) {
 			RequestID: requestID,
 			Status:    "completed",
 			Message:   "transfer completed",
+			Breakdown: newFeeBreakdown(result),
 		})
 		return
 	}
@@ -117,8 +176,13 @@ func (h *Handler) Transfer(w http.ResponseWriter, r *http.Request) {
 		FromAccountNumber: req.FromAccount,
 		ToAccountNumber:   req.ToAccount,
 		Amount:            req.Amount,
+		Type:              req.Type,
+		Principal:         principalFrom(r),
 	}
 
+	// An unknown sender is reported when the job runs, as before
+	quote, _ := h.Wallet.QuoteFee(r.Context(), req.FromAccount, req.Type, req.Amount)
+
 	// Backpressure: Check if queue is full, return 429 if so
 	select {
 	case h.Pool.JobQueue <- job:
@@ -126,6 +190,7 @@ func (h *Handler) Transfer(w http.ResponseWriter, r *http.Request) {
 		resp := TransferResponse{
 			RequestID: requestID,
 			Status:    "pending",
+			Breakdown: newFeeBreakdown(quote),
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -146,153 +211,337 @@ type CreateAccountRequest struct {
 	Name          string `json:"name"`
 	Email         string `json:"email"`
 	Phone         string `json:"phone"`
-	DOB           string `json:"dob"` // date-only YYYY-MM-DD
-	Balance       int64  `json:"balance"`
+	DOB           string `json:"dob"`     // date-only YYYY-MM-DD
+	Balance       int64  `json:"balance"` // must be 0; fund the account with an adjustment
+	Tier          string `json:"tier"`    // pricing tier; only "standard", staff set others
 }
 
+// ReplaceAccountRequest is the body of PUT. Balance and Tier cannot be
+// changed here: balances move through transfers and adjustments, and
+// staff set the tier. Both are accepted unchanged, so the body of a GET
+// can be sent back.
+type ReplaceAccountRequest struct {
+	AccountNumber string  `json:"account_number"`
+	Name          string  `json:"name"`
+	Email         string  `json:"email"`
+	Phone         string  `json:"phone"`
+	DOB           string  `json:"dob"` // date-only YYYY-MM-DD
+	Balance       *int64  `json:"balance"`
+	Tier          *string `json:"tier"`
+}
+
+var (
+	// errBalanceReadOnly rejects balance in account updates
+	errBalanceReadOnly = errors.New("balance cannot be changed here; use POST /v1/admin/accounts/{number}/adjustments")
+
+	// errOpeningBalance rejects money created along with an account, which
+	// no transaction would account for
+	errOpeningBalance = errors.New("accounts open with a zero balance; fund them with POST /v1/admin/accounts/{number}/adjustments")
+
+	// errTierReadOnly rejects a pricing tier chosen by the account holder
+	errTierReadOnly = errors.New("tier cannot be changed here; staff use PUT /v1/admin/accounts/{number}/tier")
+)
+
 type CreateAccountResponse struct {
 	ID            int64  `json:"id"`
 	AccountNumber string `json:"account_number"`
 	Message       string `json:"message,omitempty"`
 }
 
+// CreateAccount creates a new account
+// POST /v1/accounts
 func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
-	// Support multiple HTTP methods on /accounts
-	switch r.Method {
-	case http.MethodPost:
-		var req CreateAccountRequest
+	var req CreateAccountRequest
 
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "invalid request", http.StatusBadRequest)
-			return
-		}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
 
-		if req.AccountNumber == "" || req.Name == "" {
-			http.Error(w, "account_number and name are required", http.StatusBadRequest)
-			return
-		}
+	if req.AccountNumber == "" || req.Name == "" {
+		http.Error(w, "account_number and name are required", http.StatusBadRequest)
+		return
+	}
 
-		var dob time.Time
-		if req.DOB != "" {
-			t, err := time.Parse("2006-01-02", req.DOB)
-			if err != nil {
-				http.Error(w, "dob must be YYYY-MM-DD", http.StatusBadRequest)
-				return
-			}
-			dob = t
-		}
+	if req.Balance != 0 {
+		http.Error(w, errOpeningBalance.Error(), http.StatusBadRequest)
+		return
+	}
 
-		acc := &wallet.Account{
-			AccountNumber: req.AccountNumber,
-			Name:          req.Name,
-			Email:         req.Email,
-			Phone:         req.Phone,
-			DOB:           dob,
-			Balance:       req.Balance,
-		}
+	// The tier prices transfers and sets limits, so holders get the default
+	if req.Tier != "" && req.Tier != wallet.DefaultTier {
+		http.Error(w, errTierReadOnly.Error(), http.StatusBadRequest)
+		return
+	}
 
-		if err := h.Wallet.CreateAccount(r.Context(), acc); err != nil {
-			slog.Error("create account failed", "error", err, "account_number", acc.AccountNumber)
-			http.Error(w, "failed to create account", http.StatusInternalServerError)
-			return
-		}
+	dob, err := parseDOB(req.DOB)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-		resp := CreateAccountResponse{
-			ID:            acc.ID,
-			AccountNumber: acc.AccountNumber,
-			Message:       "account created",
-		}
+	acc := &wallet.Account{
+		AccountNumber: req.AccountNumber,
+		Name:          req.Name,
+		Email:         req.Email,
+		Phone:         req.Phone,
+		DOB:           dob,
+		Tier:          req.Tier,
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
+	if err := h.Wallet.CreateAccount(r.Context(), acc); err != nil {
+		slog.Error("create account failed", "error", err, "account_number", acc.AccountNumber)
+		http.Error(w, "failed to create account", http.StatusInternalServerError)
+		return
+	}
 
-	case http.MethodGet:
-		// GET /accounts?account_number=ACC123
-		acctNum := r.URL.Query().Get("account_number")
-		if acctNum == "" {
-			http.Error(w, "account_number query param required", http.StatusBadRequest)
-			return
-		}
+	h.auditAccount(r, audit.ActionAccountCreate, acc.AccountNumber, nil, acc)
 
-		acc, err := h.Wallet.GetAccountByNumber(r.Context(), acctNum)
-		if err != nil {
-			http.Error(w, "account not found", http.StatusNotFound)
-			return
-		}
+	resp := CreateAccountResponse{
+		ID:            acc.ID,
+		AccountNumber: acc.AccountNumber,
+		Message:       "account created",
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(acc)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/v1/accounts/"+url.PathEscape(acc.AccountNumber))
+	json.NewEncoder(w).Encode(resp)
+}
 
-	case http.MethodPut:
-		// Update account via body (must include account_number)
-		var req CreateAccountRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "invalid request", http.StatusBadRequest)
-			return
-		}
+// GetAccount returns a single account
+// GET /v1/accounts/{number}
+func (h *Handler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
 
-		if req.AccountNumber == "" {
-			http.Error(w, "account_number is required", http.StatusBadRequest)
-			return
-		}
+	acc, err := h.Wallet.GetAccountByNumber(r.Context(), acctNum)
+	if err != nil {
+		http.Error(w, "account not found", http.StatusNotFound)
+		return
+	}
 
-		var dob time.Time
-		if req.DOB != "" {
-			t, err := time.Parse("2006-01-02", req.DOB)
-			if err != nil {
-				http.Error(w, "dob must be YYYY-MM-DD", http.StatusBadRequest)
-				return
-			}
-			dob = t
-		}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", accountETag(acc))
+	json.NewEncoder(w).Encode(newAccountResponse(acc, h.unmaskPII(r)))
+}
 
-		acc := &wallet.Account{
-			AccountNumber: req.AccountNumber,
-			Name:          req.Name,
-			Email:         req.Email,
-			Phone:         req.Phone,
-			DOB:           dob,
-			Balance:       req.Balance,
-		}
+// ReplaceAccount overwrites every field of an account
+// PUT /v1/accounts/{number}
+func (h *Handler) ReplaceAccount(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
 
-		if err := h.Wallet.UpdateAccount(r.Context(), acc); err != nil {
-			slog.Error("update account failed", "error", err, "account_number", acc.AccountNumber)
-			http.Error(w, "failed to update account", http.StatusInternalServerError)
-			return
-		}
+	var req ReplaceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
 
-		// Return success message and account id
-		resp := CreateAccountResponse{
+	// account_number in the body is optional but must not contradict the path
+	if req.AccountNumber != "" && req.AccountNumber != acctNum {
+		http.Error(w, "account_number cannot be changed", http.StatusBadRequest)
+		return
+	}
 
-			AccountNumber: acc.AccountNumber,
-			Message:       "account updated",
-		}
+	dob, err := parseDOB(req.DOB)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
+	version, err := h.ifMatchVersion(r.Context(), r, acctNum)
+	if err != nil {
+		writePreconditionError(w, err)
+		return
+	}
 
-	case http.MethodDelete:
-		acctNum := r.URL.Query().Get("account_number")
-		if acctNum == "" {
-			http.Error(w, "account_number query param required", http.StatusBadRequest)
-			return
-		}
+	acc := &wallet.Account{
+		AccountNumber: acctNum,
+		Name:          req.Name,
+		Email:         req.Email,
+		Phone:         req.Phone,
+		DOB:           dob,
+		Version:       version,
+	}
+
+	// For the audit diff; UpdateAccount decides whether the write happens
+	before, _ := h.Wallet.GetAccountByNumber(r.Context(), acctNum)
+
+	if req.Balance != nil && before != nil && *req.Balance != before.Balance {
+		http.Error(w, errBalanceReadOnly.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Tier != nil && before != nil && *req.Tier != before.Tier {
+		http.Error(w, errTierReadOnly.Error(), http.StatusBadRequest)
+		return
+	}
 
-		if err := h.Wallet.DeleteAccount(r.Context(), acctNum); err != nil {
-			slog.Error("delete account failed", "error", err, "account_number", acctNum)
-			http.Error(w, "failed to delete account", http.StatusInternalServerError)
+	if err := h.Wallet.UpdateAccount(r.Context(), acc); err != nil {
+		slog.Error("update account failed", "error", err, "account_number", acc.AccountNumber)
+		writePreconditionError(w, err)
+		return
+	}
+
+	h.auditAccount(r, audit.ActionAccountUpdate, acctNum, before, acc)
+
+	resp := CreateAccountResponse{
+		ID:            acc.ID,
+		AccountNumber: acc.AccountNumber,
+		Message:       "account updated",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", accountETag(acc))
+	json.NewEncoder(w).Encode(resp)
+}
+
+// DeleteAccount removes an account
+// DELETE /v1/accounts/{number}
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	before, _ := h.Wallet.GetAccountByNumber(r.Context(), acctNum)
+
+	if err := h.Wallet.DeleteAccount(r.Context(), acctNum); err != nil {
+		if errors.Is(err, wallet.ErrAccountHasRecords) {
+			http.Error(w, err.Error(), http.StatusConflict)
 			return
 		}
+		slog.Error("delete account failed", "error", err, "account_number", acctNum)
+		http.Error(w, "failed to delete account", http.StatusInternalServerError)
+		return
+	}
+
+	h.auditAccount(r, audit.ActionAccountDelete, acctNum, before, nil)
+
+	resp := CreateAccountResponse{
+		AccountNumber: acctNum,
+		Message:       "account deleted",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// parseDOB parses an optional date-only YYYY-MM-DD value
+func parseDOB(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
 
-		resp := CreateAccountResponse{
-			AccountNumber: acctNum,
-			Message:       "account deleted",
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New("dob must be YYYY-MM-DD")
+	}
+
+	return t, nil
+}
+
+// PatchAccount applies a JSON Merge Patch (RFC 7396) to an account.
+// PATCH /v1/accounts/{number}
+//
+// The request must carry the ETag from a previous GET in If-Match; a stale
+// ETag is rejected with 412 instead of overwriting a concurrent change.
+func (h *Handler) PatchAccount(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		http.Error(w, "content type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	patch, err := decodeAccountMergePatch(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.ifMatchVersion(r.Context(), r, acctNum)
+	if err != nil {
+		writePreconditionError(w, err)
+		return
+	}
+
+	before, _ := h.Wallet.GetAccountByNumber(r.Context(), acctNum)
+
+	acc, err := h.Wallet.PatchAccount(r.Context(), acctNum, patch, version)
+	if err != nil {
+		if !errors.Is(err, wallet.ErrVersionConflict) && !errors.Is(err, wallet.ErrAccountNotFound) {
+			slog.Error("patch account failed", "error", err, "account_number", acctNum)
 		}
+		writePreconditionError(w, err)
+		return
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
+	h.auditAccount(r, audit.ActionAccountUpdate, acctNum, before, acc)
 
-	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", accountETag(acc))
+	json.NewEncoder(w).Encode(newAccountResponse(acc, h.unmaskPII(r)))
+}
+
+// decodeAccountMergePatch turns a merge patch document into an AccountPatch.
+// Members that are absent are left untouched; null resets a field to its
+// empty value. account_number is the resource identity and cannot change.
+func decodeAccountMergePatch(body io.Reader) (wallet.AccountPatch, error) {
+	var patch wallet.AccountPatch
+	var doc map[string]json.RawMessage
+
+	if err := json.NewDecoder(body).Decode(&doc); err != nil || doc == nil {
+		return patch, errors.New("merge patch must be a JSON object")
+	}
+
+	for field, raw := range doc {
+		isNull := string(raw) == "null"
+
+		switch field {
+		case "name":
+			var name string
+			if isNull || json.Unmarshal(raw, &name) != nil || name == "" {
+				return patch, errors.New("name must be a non-empty string")
+			}
+			patch.Name = &name
+
+		case "email", "phone":
+			var value string
+			if !isNull {
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return patch, fmt.Errorf("%s must be a string or null", field)
+				}
+			}
+			if field == "email" {
+				patch.Email = &value
+			} else {
+				patch.Phone = &value
+			}
+
+		case "dob":
+			var dob time.Time
+			if !isNull {
+				var value string
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return patch, errors.New("dob must be YYYY-MM-DD or null")
+				}
+				t, err := time.Parse("2006-01-02", value)
+				if err != nil {
+					return patch, errors.New("dob must be YYYY-MM-DD or null")
+				}
+				dob = t
+			}
+			patch.DOB = &dob
+
+		case "balance":
+			return patch, errBalanceReadOnly
+
+		case "tier":
+			return patch, errTierReadOnly
+
+		case "account_number":
+			return patch, errors.New("account_number cannot be changed")
+
+		default:
+			return patch, fmt.Errorf("unknown field %q", field)
+		}
 	}
+
+	return patch, nil
 }
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"gopherpay/internal/audit"
+	"gopherpay/internal/wallet"
+)
+
+type SetKYCTierRequest struct {
+	Tier   string `json:"tier"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type KYCDocumentRequest struct {
+	Type      string `json:"type"`
+	Reference string `json:"reference"`
+	Country   string `json:"country,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"` // YYYY-MM-DD
+}
+
+// GetAccountKYC returns an account's KYC tier, documents and tier history
+// GET /v1/admin/accounts/{number}/kyc
+func (h *Handler) GetAccountKYC(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	kyc, err := h.Wallet.GetKYC(r.Context(), acctNum)
+	if err != nil {
+		writeKYCError(w, err, acctNum)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(maskKYC(kyc, h.unmaskPII(r)))
+}
+
+// SetAccountKYCTier moves an account between KYC tiers; the caller in
+// X-Principal is recorded in the tier history
+// PUT /v1/admin/accounts/{number}/kyc
+func (h *Handler) SetAccountKYCTier(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	var req SetKYCTierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	before, _ := h.Wallet.GetKYC(r.Context(), acctNum)
+
+	kyc, err := h.Wallet.SetKYCTier(r.Context(), acctNum, req.Tier, principalFrom(r), req.Reason)
+	if err != nil {
+		writeKYCError(w, err, acctNum)
+		return
+	}
+
+	event := newAuditEvent(r, audit.ActionKYCTier, audit.TargetAccount, acctNum)
+	if before != nil {
+		event.Diff = audit.Diff(map[string]any{"kyc_tier": before.Tier}, map[string]any{"kyc_tier": kyc.Tier})
+	}
+	event.Details = map[string]any{"reason": req.Reason}
+	h.Audit.Record(r.Context(), event)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(maskKYC(kyc, h.unmaskPII(r)))
+}
+
+// AddAccountKYCDocument records the metadata of a verification document
+// POST /v1/admin/accounts/{number}/kyc/documents
+func (h *Handler) AddAccountKYCDocument(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	var req KYCDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	doc := &wallet.KYCDocument{
+		Type:      req.Type,
+		Reference: req.Reference,
+		Country:   req.Country,
+	}
+	if req.ExpiresAt != "" {
+		expires, err := parseTimeValue("expires_at", req.ExpiresAt)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		doc.ExpiresAt = &expires
+	}
+
+	doc, err := h.Wallet.AddKYCDocument(r.Context(), acctNum, doc, principalFrom(r))
+	if err != nil {
+		writeKYCError(w, err, acctNum)
+		return
+	}
+
+	event := newAuditEvent(r, audit.ActionKYCDocument, audit.TargetAccount, acctNum)
+	event.Diff = audit.Diff(nil, map[string]any{
+		"type":      doc.Type,
+		"reference": doc.Reference,
+		"country":   doc.Country,
+	})
+	event.Details = map[string]any{"document_id": doc.ID}
+	h.Audit.Record(r.Context(), event)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(maskDocument(doc, h.unmaskPII(r)))
+}
+
+func writeKYCError(w http.ResponseWriter, err error, acctNum string) {
+	switch {
+	case errors.Is(err, wallet.ErrAccountNotFound):
+		http.Error(w, "account not found", http.StatusNotFound)
+	case errors.Is(err, wallet.ErrInvalidKYCTier),
+		errors.Is(err, wallet.ErrKYCDocumentIncomplete):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, wallet.ErrKYCDocumentRequired):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, wallet.ErrVersionConflict):
+		http.Error(w, "KYC tier changed concurrently, retry", http.StatusConflict)
+	case errors.Is(err, wallet.ErrPrincipalRequired):
+		http.Error(w, PrincipalHeader+" header is required", http.StatusUnauthorized)
+	default:
+		slog.Error("account KYC failed", "error", err, "account_number", acctNum)
+		http.Error(w, "failed to update KYC", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"gopherpay/internal/audit"
+	"gopherpay/internal/wallet"
+)
+
+// GetAccountLimits returns an account's tier limits, its override and the
+// limits in effect
+// GET /v1/admin/accounts/{number}/limits
+func (h *Handler) GetAccountLimits(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	limits, err := h.Wallet.GetLimits(r.Context(), acctNum)
+	if err != nil {
+		writeLimitsError(w, err, acctNum)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(limits)
+}
+
+// SetAccountLimits replaces an account's limit override. Omitted or null
+// fields inherit the tier limit; 0 lifts the limit for this account.
+// PUT /v1/admin/accounts/{number}/limits
+func (h *Handler) SetAccountLimits(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	var override wallet.LimitOverride
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&override); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := override.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before, _ := h.Wallet.GetLimits(r.Context(), acctNum)
+
+	limits, err := h.Wallet.SetLimitOverride(r.Context(), acctNum, &override)
+	if err != nil {
+		writeLimitsError(w, err, acctNum)
+		return
+	}
+
+	h.auditLimits(r, audit.ActionLimitsSet, acctNum, before, limits)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(limits)
+}
+
+// DeleteAccountLimits removes an account's override, so its tier limits
+// apply again
+// DELETE /v1/admin/accounts/{number}/limits
+func (h *Handler) DeleteAccountLimits(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	before, _ := h.Wallet.GetLimits(r.Context(), acctNum)
+
+	limits, err := h.Wallet.SetLimitOverride(r.Context(), acctNum, nil)
+	if err != nil {
+		writeLimitsError(w, err, acctNum)
+		return
+	}
+
+	h.auditLimits(r, audit.ActionLimitsClear, acctNum, before, limits)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(limits)
+}
+
+// auditLimits records a change to an account's override
+func (h *Handler) auditLimits(r *http.Request, action, acctNum string, before, after *wallet.AccountLimits) {
+	var from, to *wallet.LimitOverride
+	if before != nil {
+		from = before.Override
+	}
+	if after != nil {
+		to = after.Override
+	}
+
+	event := newAuditEvent(r, action, audit.TargetAccount, acctNum)
+	event.Diff = audit.Diff(from, to)
+	h.Audit.Record(r.Context(), event)
+}
+
+func writeLimitsError(w http.ResponseWriter, err error, acctNum string) {
+	if errors.Is(err, wallet.ErrAccountNotFound) {
+		http.Error(w, "account not found", http.StatusNotFound)
+		return
+	}
+
+	slog.Error("account limits failed", "error", err, "account_number", acctNum)
+	http.Error(w, "failed to update limits", http.StatusInternalServerError)
+}
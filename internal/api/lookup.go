@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"gopherpay/internal/wallet"
+)
+
+// FindAccounts looks accounts up by exact email or phone number. Both
+// work whether or not PII is encrypted at rest; the matches are masked
+// like any other account response.
+// GET /v1/admin/accounts?email=... or ?phone=...
+func (h *Handler) FindAccounts(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	phone := r.URL.Query().Get("phone")
+
+	if (email == "") == (phone == "") {
+		http.Error(w, "exactly one of email or phone is required", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		accounts []wallet.Account
+		err      error
+	)
+	if email != "" {
+		accounts, err = h.Wallet.FindAccountsByEmail(r.Context(), email)
+	} else {
+		accounts, err = h.Wallet.FindAccountsByPhone(r.Context(), phone)
+	}
+	if err != nil {
+		slog.Error("account lookup failed", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"accounts": newAccountResponses(accounts, h.unmaskPII(r))})
+}
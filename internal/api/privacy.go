@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"gopherpay/internal/audit"
+	"gopherpay/internal/wallet"
+)
+
+type EraseAccountRequest struct {
+	Reason string `json:"reason"`
+
+	// Confirm must repeat the account number, as erasure cannot be undone
+	Confirm string `json:"confirm"`
+}
+
+// ExportAccountData returns everything held about an account holder as a
+// JSON bundle. It contains unmasked PII, so the pii:read scope is required.
+// GET /v1/admin/accounts/{number}/export
+func (h *Handler) ExportAccountData(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	if !hasScope(r, ScopePIIRead) {
+		http.Error(w, "the "+ScopePIIRead+" scope is required", http.StatusForbidden)
+		return
+	}
+
+	bundle, err := h.Privacy.Export(r.Context(), acctNum, principalFrom(r))
+	if err != nil {
+		writeErasureError(w, err, acctNum)
+		return
+	}
+
+	event := newAuditEvent(r, audit.ActionAccountExport, audit.TargetAccount, acctNum)
+	event.Details = map[string]any{"transactions": len(bundle.Transactions)}
+	h.Audit.Record(r.Context(), event)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+acctNum+`-export.json"`)
+	json.NewEncoder(w).Encode(bundle)
+}
+
+// EraseAccount irreversibly pseudonymizes an account holder's PII. The
+// account and its transactions are kept, frozen.
+// POST /v1/admin/accounts/{number}/erase
+func (h *Handler) EraseAccount(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	var req EraseAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Confirm != acctNum {
+		http.Error(w, "confirm must repeat the account number", http.StatusBadRequest)
+		return
+	}
+
+	before, _ := h.Wallet.GetAccountByNumber(r.Context(), acctNum)
+
+	erasure, err := h.Wallet.EraseAccount(r.Context(), acctNum, principalFrom(r), req.Reason)
+	if err != nil {
+		writeErasureError(w, err, acctNum)
+		return
+	}
+
+	after, _ := h.Wallet.GetAccountByNumber(r.Context(), acctNum)
+
+	event := newAuditEvent(r, audit.ActionAccountErase, audit.TargetAccount, acctNum)
+	event.Diff = audit.Diff(audit.AccountState(before), audit.AccountState(after))
+	event.Details = map[string]any{"reason": erasure.Reason, "erasure_id": erasure.ID}
+	h.Audit.Record(r.Context(), event)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(erasure)
+}
+
+func writeErasureError(w http.ResponseWriter, err error, acctNum string) {
+	switch {
+	case errors.Is(err, wallet.ErrAccountNotFound):
+		http.Error(w, "account not found", http.StatusNotFound)
+	case errors.Is(err, wallet.ErrErasureReasonRequired):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, wallet.ErrAccountErased),
+		errors.Is(err, wallet.ErrErasureBalance):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, wallet.ErrPrincipalRequired):
+		http.Error(w, PrincipalHeader+" header is required", http.StatusUnauthorized)
+	default:
+		slog.Error("data subject request failed", "error", err, "account_number", acctNum)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gopherpay/internal/billing"
+	"gopherpay/internal/reportjob"
+)
+
+type CreateReportRequest struct {
+	AccountNumber string `json:"account_number"`
+	Format        string `json:"format"`
+	From          string `json:"from"` // RFC 3339 or YYYY-MM-DD, optional
+	To            string `json:"to"`
+}
+
+type ReportJobResponse struct {
+	ID            string     `json:"id"`
+	AccountNumber string     `json:"account_number"`
+	Format        string     `json:"format"`
+	From          *time.Time `json:"from,omitempty"`
+	To            *time.Time `json:"to,omitempty"`
+	Status        string     `json:"status"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+	StatusURL     string     `json:"status_url"`
+	DownloadURL   string     `json:"download_url,omitempty"`
+}
+
+// CreateReport queues a statement export and returns its job
+// POST /v1/reports
+func (h *Handler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	var req CreateReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.AccountNumber == "" {
+		http.Error(w, "account_number is required", http.StatusBadRequest)
+		return
+	}
+	if req.Format == "" {
+		req.Format = billing.FormatCSV
+	}
+	if !billing.IsFormat(req.Format) {
+		http.Error(w, "format must be one of "+strings.Join(billing.Formats, ", "), http.StatusBadRequest)
+		return
+	}
+
+	from, err := parseTimeValue("from", req.From)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeValue("to", req.To)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.Wallet.GetAccountByNumber(r.Context(), req.AccountNumber); err != nil {
+		http.Error(w, "account not found", http.StatusNotFound)
+		return
+	}
+
+	job, err := h.Jobs.Submit(r.Context(), req.AccountNumber, req.Format, from, to)
+	if err != nil {
+		slog.Error("report job submit failed", "error", err, "account_number", req.AccountNumber)
+		http.Error(w, "failed to queue report", http.StatusInternalServerError)
+		return
+	}
+
+	resp := reportJobResponse(job)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", resp.StatusURL)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetReport returns a report job's status; download_url is set once it
+// has succeeded
+// GET /v1/reports/{id}
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
+	id, ok := reportJobID(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.Jobs.Get(r.Context(), id)
+	if errors.Is(err, reportjob.ErrJobNotFound) {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("report job lookup failed", "error", err, "job_id", id)
+		http.Error(w, "failed to fetch report", http.StatusInternalServerError)
+		return
+	}
+
+	if job.Status == reportjob.StatusQueued || job.Status == reportjob.StatusRunning {
+		w.Header().Set("Retry-After", "2")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reportJobResponse(job))
+}
+
+// DownloadReport streams the file of a succeeded report job. Jobs that
+// are still running or have failed give 409 Conflict.
+// GET /v1/reports/{id}/download
+func (h *Handler) DownloadReport(w http.ResponseWriter, r *http.Request) {
+	id, ok := reportJobID(w, r)
+	if !ok {
+		return
+	}
+
+	job, file, err := h.Jobs.Open(r.Context(), id)
+	switch {
+	case errors.Is(err, reportjob.ErrJobNotFound):
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	case errors.Is(err, reportjob.ErrJobNotReady):
+		http.Error(w, "report is "+job.Status, http.StatusConflict)
+		return
+	case errors.Is(err, billing.ErrReportNotFound):
+		http.Error(w, "report file is no longer available", http.StatusGone)
+		return
+	case err != nil:
+		slog.Error("report download failed", "error", err, "job_id", id)
+		http.Error(w, "failed to fetch report", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", billing.ContentType(job.Format))
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="%s_statement.%s"`, job.AccountNumber, billing.FileExtension(job.Format)))
+
+	if _, err := io.Copy(w, file); err != nil {
+		slog.Error("report download interrupted", "error", err, "job_id", id)
+	}
+}
+
+// reportJobID reads the {id} path value; anything that is not a UUID
+// cannot name a job
+func reportJobID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return "", false
+	}
+	return id, true
+}
+
+func reportJobResponse(job *reportjob.Job) ReportJobResponse {
+	resp := ReportJobResponse{
+		ID:            job.ID,
+		AccountNumber: job.AccountNumber,
+		Format:        job.Format,
+		Status:        job.Status,
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt,
+		StartedAt:     job.StartedAt,
+		FinishedAt:    job.FinishedAt,
+		StatusURL:     "/v1/reports/" + job.ID,
+	}
+
+	if !job.From.IsZero() {
+		from := job.From
+		resp.From = &from
+	}
+	if !job.To.IsZero() {
+		to := job.To
+		resp.To = &to
+	}
+	if job.Status == reportjob.StatusSucceeded {
+		resp.DownloadURL = resp.StatusURL + "/download"
+	}
+
+	return resp
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gopherpay/internal/audit"
+	"gopherpay/internal/wallet"
+)
+
+// PrincipalHeader identifies who is calling, for the four-eyes check on
+// reviews. Authentication is expected in front of the API.
+const PrincipalHeader = "X-Principal"
+
+func principalFrom(r *http.Request) string {
+	return r.Header.Get(PrincipalHeader)
+}
+
+type DecisionRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+type ReviewAdjustRequest struct {
+	Amount int64 `json:"amount"`
+}
+
+// ListReviews returns held transfers oldest first
+// GET /v1/admin/reviews?status=pending&limit=100
+func (h *Handler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	limit, err := parseInt64Param(q, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reviews, err := h.Wallet.ListReviews(r.Context(), q.Get("status"), int(limit))
+	if err != nil {
+		slog.Error("list reviews failed", "error", err)
+		http.Error(w, "failed to fetch reviews", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"reviews": reviews})
+}
+
+// GetReview returns one review
+// GET /v1/admin/reviews/{id}
+func (h *Handler) GetReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := reviewID(w, r)
+	if !ok {
+		return
+	}
+
+	review, err := h.Wallet.GetReview(r.Context(), id)
+	if err != nil {
+		writeReviewError(w, err, id)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// AdjustReview changes the amount of a pending review; the caller can then
+// no longer approve it
+// PATCH /v1/admin/reviews/{id}
+func (h *Handler) AdjustReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := reviewID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ReviewAdjustRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.Amount <= 0 {
+		http.Error(w, "amount must be a positive integer (cents)", http.StatusBadRequest)
+		return
+	}
+
+	before, _ := h.Wallet.GetReview(r.Context(), id)
+
+	review, err := h.Wallet.AdjustReview(r.Context(), id, req.Amount, principalFrom(r))
+	if err != nil {
+		writeReviewError(w, err, id)
+		return
+	}
+
+	h.auditReview(r, audit.ActionReviewAdjust, before, review)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// ApproveReview approves a held transfer and hands it to the worker pool.
+// The caller must not be the initiator or an adjuster of the transfer.
+// POST /v1/admin/reviews/{id}/approve
+func (h *Handler) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	h.decideReview(w, r, audit.ActionReviewApprove, h.Wallet.ApproveReview)
+}
+
+// RejectReview rejects a held transfer; it is recorded as failed
+// POST /v1/admin/reviews/{id}/reject
+func (h *Handler) RejectReview(w http.ResponseWriter, r *http.Request) {
+	h.decideReview(w, r, audit.ActionReviewReject, h.Wallet.RejectReview)
+}
+
+func (h *Handler) decideReview(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	decide func(ctx context.Context, id int64, principal, note string) (*wallet.Review, error),
+) {
+
+	id, ok := reviewID(w, r)
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req DecisionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	before, _ := h.Wallet.GetReview(r.Context(), id)
+
+	review, err := decide(r.Context(), id, principalFrom(r), req.Note)
+	if err != nil {
+		writeReviewError(w, err, id)
+		return
+	}
+
+	h.auditReview(r, action, before, review)
+
+	if review.Status == wallet.ReviewApproved {
+		h.Pool.WakeDispatcher()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// auditReview records a decision on or adjustment of a review
+func (h *Handler) auditReview(r *http.Request, action string, before, after *wallet.Review) {
+	event := newAuditEvent(r, action, audit.TargetReview, strconv.FormatInt(after.ID, 10))
+	event.Diff = audit.Diff(audit.ReviewState(before), audit.ReviewState(after))
+	event.Details = map[string]any{
+		"from_account": after.FromAccount,
+		"to_account":   after.ToAccount,
+	}
+	h.Audit.Record(r.Context(), event)
+}
+
+func reviewID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid review id", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeReviewError(w http.ResponseWriter, err error, id int64) {
+	switch {
+	case errors.Is(err, wallet.ErrReviewNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, wallet.ErrReviewNotPending):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, wallet.ErrSamePrincipal),
+		errors.Is(err, wallet.ErrUnknownInitiator):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, wallet.ErrPrincipalRequired):
+		http.Error(w, PrincipalHeader+" header is required", http.StatusUnauthorized)
+	default:
+		slog.Error("review failed", "error", err, "review_id", id)
+		http.Error(w, "failed to update review", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// Routes registers the versioned API and the deprecated unversioned routes.
+// Reads of admin routes are audited.
+func (h *Handler) Routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// =====================================
+	// v1
+	// =====================================
+	mux.HandleFunc("POST /v1/accounts", h.CreateAccount)
+	mux.HandleFunc("GET /v1/accounts/{number}", h.GetAccount)
+	mux.HandleFunc("PUT /v1/accounts/{number}", h.ReplaceAccount)
+	mux.HandleFunc("PATCH /v1/accounts/{number}", h.PatchAccount)
+	mux.HandleFunc("DELETE /v1/accounts/{number}", h.DeleteAccount)
+	mux.HandleFunc("GET /v1/accounts/{number}/transactions", h.AccountTransactions)
+	mux.HandleFunc("GET /v1/accounts/{number}/statement", h.AccountStatement)
+	mux.HandleFunc("POST /v1/transfers", h.Transfer)
+	mux.HandleFunc("POST /v1/reports", h.CreateReport)
+	mux.HandleFunc("GET /v1/reports/{id}", h.GetReport)
+	mux.HandleFunc("GET /v1/reports/{id}/download", h.DownloadReport)
+	mux.HandleFunc("GET /v1/admin/transactions", h.AdminTransactions)
+	mux.HandleFunc("GET /v1/admin/accounts", h.FindAccounts)
+	mux.HandleFunc("GET /v1/admin/accounts/{number}/limits", h.GetAccountLimits)
+	mux.HandleFunc("PUT /v1/admin/accounts/{number}/limits", h.SetAccountLimits)
+	mux.HandleFunc("DELETE /v1/admin/accounts/{number}/limits", h.DeleteAccountLimits)
+	mux.HandleFunc("POST /v1/admin/accounts/{number}/adjustments", h.AdjustAccountBalance)
+	mux.HandleFunc("PUT /v1/admin/accounts/{number}/tier", h.SetAccountTier)
+	mux.HandleFunc("GET /v1/admin/accounts/{number}/kyc", h.GetAccountKYC)
+	mux.HandleFunc("PUT /v1/admin/accounts/{number}/kyc", h.SetAccountKYCTier)
+	mux.HandleFunc("POST /v1/admin/accounts/{number}/kyc/documents", h.AddAccountKYCDocument)
+	mux.HandleFunc("GET /v1/admin/accounts/{number}/export", h.ExportAccountData)
+	mux.HandleFunc("POST /v1/admin/accounts/{number}/erase", h.EraseAccount)
+	mux.HandleFunc("POST /v1/admin/accounts/{number}/freeze", h.FreezeAccount)
+	mux.HandleFunc("POST /v1/admin/accounts/{number}/unfreeze", h.UnfreezeAccount)
+	mux.HandleFunc("GET /v1/admin/sanctions/alerts", h.ListSanctionsAlerts)
+	mux.HandleFunc("POST /v1/admin/sanctions/alerts/{id}/dismiss", h.DismissSanctionsAlert)
+	mux.HandleFunc("POST /v1/admin/sanctions/alerts/{id}/confirm", h.ConfirmSanctionsAlert)
+	mux.HandleFunc("GET /v1/admin/reviews", h.ListReviews)
+	mux.HandleFunc("GET /v1/admin/reviews/{id}", h.GetReview)
+	mux.HandleFunc("PATCH /v1/admin/reviews/{id}", h.AdjustReview)
+	mux.HandleFunc("POST /v1/admin/reviews/{id}/approve", h.ApproveReview)
+	mux.HandleFunc("POST /v1/admin/reviews/{id}/reject", h.RejectReview)
+	mux.HandleFunc("GET /v1/admin/reports/summary", h.ReportSummary)
+	mux.HandleFunc("GET /v1/admin/audit-events", h.ListAuditEvents)
+
+	// =====================================
+	// Legacy (deprecated, kept for existing clients)
+	// =====================================
+	mux.HandleFunc("/transfer", deprecated("/v1/transfers", h.Transfer))
+	mux.HandleFunc("/accounts", deprecated("/v1/accounts", h.legacyAccounts))
+	mux.HandleFunc("PATCH /accounts/{number}", deprecated("/v1/accounts/{number}", h.PatchAccount))
+	mux.HandleFunc("/admin/transactions", deprecated("/v1/admin/transactions", h.AdminTransactions))
+
+	return h.auditAdminQueries(mux)
+}
+
+// deprecated marks responses from a legacy route so clients can migrate
+// to its successor before the route is removed
+func deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		w.Header().Set("Warning", `299 gopherpay "deprecated API, use `+successor+`"`)
+
+		slog.Info("deprecated route called",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"successor", successor,
+		)
+
+		next(w, r)
+	}
+}
+
+// legacyAccounts dispatches the old /accounts route, where the account was
+// identified by ?account_number= or by the request body, to the v1 handlers
+func (h *Handler) legacyAccounts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.CreateAccount(w, r)
+
+	case http.MethodGet, http.MethodDelete:
+		acctNum := r.URL.Query().Get("account_number")
+		if acctNum == "" {
+			http.Error(w, "account_number query param required", http.StatusBadRequest)
+			return
+		}
+
+		r.SetPathValue("number", acctNum)
+		if r.Method == http.MethodGet {
+			h.GetAccount(w, r)
+		} else {
+			h.DeleteAccount(w, r)
+		}
+
+	case http.MethodPut:
+		// Update account via body (must include account_number)
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		var req ReplaceAccountRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if req.AccountNumber == "" {
+			http.Error(w, "account_number is required", http.StatusBadRequest)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.SetPathValue("number", req.AccountNumber)
+		h.ReplaceAccount(w, r)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
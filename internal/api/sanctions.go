@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gopherpay/internal/audit"
+	"gopherpay/internal/sanctions"
+	"gopherpay/internal/wallet"
+)
+
+// ListSanctionsAlerts returns sanctions list matches newest first
+// GET /v1/admin/sanctions/alerts?status=open&limit=100
+func (h *Handler) ListSanctionsAlerts(w http.ResponseWriter, r *http.Request) {
+	if h.Sanctions == nil {
+		http.Error(w, "sanctions screening is disabled", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+
+	limit, err := parseInt64Param(q, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	alerts, err := h.Sanctions.ListAlerts(r.Context(), q.Get("status"), int(limit))
+	if err != nil {
+		slog.Error("list sanctions alerts failed", "error", err)
+		http.Error(w, "failed to fetch alerts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"alerts": alerts})
+}
+
+// DismissSanctionsAlert closes an alert as a false positive; it is not
+// raised again for the same name. A frozen account stays frozen.
+// POST /v1/admin/sanctions/alerts/{id}/dismiss
+func (h *Handler) DismissSanctionsAlert(w http.ResponseWriter, r *http.Request) {
+	h.resolveSanctionsAlert(w, r, sanctions.AlertDismissed)
+}
+
+// ConfirmSanctionsAlert closes an alert as a true match
+// POST /v1/admin/sanctions/alerts/{id}/confirm
+func (h *Handler) ConfirmSanctionsAlert(w http.ResponseWriter, r *http.Request) {
+	h.resolveSanctionsAlert(w, r, sanctions.AlertConfirmed)
+}
+
+func (h *Handler) resolveSanctionsAlert(w http.ResponseWriter, r *http.Request, status string) {
+	if h.Sanctions == nil {
+		http.Error(w, "sanctions screening is disabled", http.StatusNotFound)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid alert id", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional
+	var req DecisionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	alert, err := h.Sanctions.ResolveAlert(r.Context(), id, status, principalFrom(r), req.Note)
+	switch {
+	case errors.Is(err, sanctions.ErrAlertNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, sanctions.ErrAlertNotOpen):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, wallet.ErrPrincipalRequired):
+		http.Error(w, PrincipalHeader+" header is required", http.StatusUnauthorized)
+		return
+	case err != nil:
+		slog.Error("resolve sanctions alert failed", "error", err, "alert_id", id)
+		http.Error(w, "failed to update alert", http.StatusInternalServerError)
+		return
+	}
+
+	action := audit.ActionAlertDismiss
+	if status == sanctions.AlertConfirmed {
+		action = audit.ActionAlertConfirm
+	}
+	event := newAuditEvent(r, action, audit.TargetAlert, strconv.FormatInt(id, 10))
+	event.Diff = audit.Diff(map[string]any{"status": sanctions.AlertOpen}, map[string]any{"status": alert.Status})
+	event.Details = map[string]any{"account_number": alert.AccountNumber, "note": req.Note}
+	h.Audit.Record(r.Context(), event)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alert)
+}
+
+// FreezeAccount stops an account from sending or receiving transfers
+// POST /v1/admin/accounts/{number}/freeze
+func (h *Handler) FreezeAccount(w http.ResponseWriter, r *http.Request) {
+	h.setAccountFrozen(w, r, true)
+}
+
+// UnfreezeAccount lifts a freeze, e.g. after a dismissed alert
+// POST /v1/admin/accounts/{number}/unfreeze
+func (h *Handler) UnfreezeAccount(w http.ResponseWriter, r *http.Request) {
+	h.setAccountFrozen(w, r, false)
+}
+
+func (h *Handler) setAccountFrozen(w http.ResponseWriter, r *http.Request, frozen bool) {
+	acctNum := r.PathValue("number")
+
+	before, _ := h.Wallet.GetAccountByNumber(r.Context(), acctNum)
+
+	acc, err := h.Wallet.SetAccountFrozen(r.Context(), acctNum, frozen)
+	if errors.Is(err, wallet.ErrAccountNotFound) {
+		http.Error(w, "account not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, wallet.ErrAccountErased) {
+		http.Error(w, "erased accounts stay frozen", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		slog.Error("freeze account failed", "error", err, "account_number", acctNum)
+		http.Error(w, "failed to update account", http.StatusInternalServerError)
+		return
+	}
+
+	action := audit.ActionAccountUnfreeze
+	if frozen {
+		action = audit.ActionAccountFreeze
+	}
+	event := newAuditEvent(r, action, audit.TargetAccount, acctNum)
+	if before != nil {
+		event.Diff = audit.Diff(map[string]any{"frozen": before.Frozen}, map[string]any{"frozen": acc.Frozen})
+	}
+	h.Audit.Record(r.Context(), event)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", accountETag(acc))
+	json.NewEncoder(w).Encode(newAccountResponse(acc, h.unmaskPII(r)))
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+
+	"gopherpay/internal/billing"
+)
+
+// ReportSummary returns aggregated transfer figures for finance: daily and
+// monthly counts and volumes, failure rates by reason, top counterparties
+// per account and average ticket size
+// GET /v1/admin/reports/summary?from=2026-01-01&to=2026-02-01&accounts=ACC1*&top=5&format=json|csv
+func (h *Handler) ReportSummary(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if format == "" {
+		format = billing.SummaryJSON
+	}
+	if !billing.IsSummaryFormat(format) {
+		http.Error(w, "format must be json or csv", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		filter billing.SummaryFilter
+		err    error
+	)
+
+	if filter.From, err = parseTimeParam(q, "from"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTimeParam(q, "to"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	top, err := parseInt64Param(q, "top")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Top = int(top)
+	filter.Accounts = q.Get("accounts")
+
+	if err := filter.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.Report.Summary(r.Context(), filter)
+	if err != nil {
+		slog.Error("report summary failed", "error", err)
+		http.Error(w, "failed to build summary", http.StatusInternalServerError)
+		return
+	}
+
+	// Summaries are small; buffer so an encoding error can still be a 500
+	var buf bytes.Buffer
+	if err := billing.WriteSummary(&buf, summary, format); err != nil {
+		slog.Error("report summary encoding failed", "error", err)
+		http.Error(w, "failed to build summary", http.StatusInternalServerError)
+		return
+	}
+
+	if format == billing.SummaryCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="summary.csv"`)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Write(buf.Bytes())
+}
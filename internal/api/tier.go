@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"gopherpay/internal/audit"
+	"gopherpay/internal/wallet"
+)
+
+type SetTierRequest struct {
+	Tier string `json:"tier"`
+}
+
+// SetAccountTier moves an account to another pricing tier, which selects
+// its fees and limits. The tier must be named by the fee schedule or the
+// limit policy.
+// PUT /v1/admin/accounts/{number}/tier
+func (h *Handler) SetAccountTier(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	var req SetTierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	before, _ := h.Wallet.GetAccountByNumber(r.Context(), acctNum)
+
+	acc, err := h.Wallet.SetAccountTier(r.Context(), acctNum, req.Tier)
+	switch {
+	case errors.Is(err, wallet.ErrUnknownTier):
+		http.Error(w, "tier must be one of: "+strings.Join(h.Wallet.AccountTiers(), ", "), http.StatusBadRequest)
+		return
+	case errors.Is(err, wallet.ErrAccountNotFound):
+		http.Error(w, "account not found", http.StatusNotFound)
+		return
+	case errors.Is(err, wallet.ErrAccountErased):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		slog.Error("set account tier failed", "error", err, "account_number", acctNum)
+		http.Error(w, "failed to update account", http.StatusInternalServerError)
+		return
+	}
+
+	event := newAuditEvent(r, audit.ActionAccountTier, audit.TargetAccount, acctNum)
+	if before != nil {
+		event.Diff = audit.Diff(map[string]any{"tier": before.Tier}, map[string]any{"tier": acc.Tier})
+	}
+	h.Audit.Record(r.Context(), event)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", accountETag(acc))
+	json.NewEncoder(w).Encode(newAccountResponse(acc, h.unmaskPII(r)))
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gopherpay/internal/billing"
+)
+
+// AdminTransactions returns a page of transactions across all accounts,
+// or for one account when account_number is given
+// GET /v1/admin/transactions?account_number=ACC1234&status=completed&limit=100&cursor=...
+func (h *Handler) AdminTransactions(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTransactionFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.AccountNumber = r.URL.Query().Get("account_number")
+
+	h.writeTransactionPage(w, r, filter)
+}
+
+// AccountTransactions returns a page of an account holder's own history
+// GET /v1/accounts/{number}/transactions?direction=sent&from=2026-01-01
+func (h *Handler) AccountTransactions(w http.ResponseWriter, r *http.Request) {
+	acctNum := r.PathValue("number")
+
+	if _, err := h.Wallet.GetAccountByNumber(r.Context(), acctNum); err != nil {
+		http.Error(w, "account not found", http.StatusNotFound)
+		return
+	}
+
+	filter, err := parseTransactionFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.AccountNumber = acctNum
+
+	h.writeTransactionPage(w, r, filter)
+}
+
+func (h *Handler) writeTransactionPage(w http.ResponseWriter, r *http.Request, filter billing.TransactionFilter) {
+	if err := filter.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.Report.ListTransactions(r.Context(), filter)
+	if err != nil {
+		slog.Error("list transactions failed", "error", err, "account_number", filter.AccountNumber)
+		http.Error(w, "failed to fetch transactions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// parseTransactionFilter reads the listing filters shared by the admin and
+// account holder endpoints. Dates accept RFC 3339 or YYYY-MM-DD.
+func parseTransactionFilter(q url.Values) (billing.TransactionFilter, error) {
+	var (
+		filter billing.TransactionFilter
+		err    error
+	)
+
+	filter.Status = q.Get("status")
+	filter.Direction = q.Get("direction")
+	filter.Counterparty = q.Get("counterparty")
+
+	if filter.From, err = parseTimeParam(q, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTimeParam(q, "to"); err != nil {
+		return filter, err
+	}
+	if filter.MinAmount, err = parseInt64Param(q, "min_amount"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = parseInt64Param(q, "max_amount"); err != nil {
+		return filter, err
+	}
+
+	limit, err := parseInt64Param(q, "limit")
+	if err != nil {
+		return filter, err
+	}
+	filter.Limit = int(limit)
+
+	if c := q.Get("cursor"); c != "" {
+		cursor, err := billing.DecodeCursor(c)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = &cursor
+	}
+
+	return filter, nil
+}
+
+func parseTimeParam(q url.Values, key string) (time.Time, error) {
+	return parseTimeValue(key, q.Get(key))
+}
+
+// parseTimeValue parses an optional RFC 3339 or YYYY-MM-DD value
+func parseTimeValue(key, v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be RFC 3339 or YYYY-MM-DD", key)
+}
+
+func parseInt64Param(q url.Values, key string) (int64, error) {
+	v := q.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", key)
+	}
+	return n, nil
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopherpay/internal/pii"
+)
+
+// Target types
+const (
+	TargetAccount = "account"
+	TargetReview  = "review"
+	TargetAlert   = "sanctions_alert"
+	TargetRoute   = "route"
+	TargetKeyring = "pii_keyring"
+)
+
+// Actions
+const (
+	ActionAccountCreate   = "account.create"
+	ActionAccountUpdate   = "account.update"
+	ActionAccountDelete   = "account.delete"
+	ActionAccountFreeze   = "account.freeze"
+	ActionAccountUnfreeze = "account.unfreeze"
+	ActionAccountErase    = "account.erase"
+	ActionAccountExport   = "account.export"
+	ActionBalanceAdjust   = "balance.adjust"
+	ActionAccountTier     = "account.tier"
+	ActionLimitsSet       = "limits.set"
+	ActionLimitsClear     = "limits.clear"
+	ActionKYCTier         = "kyc.tier"
+	ActionKYCDocument     = "kyc.document"
+	ActionReviewAdjust    = "review.adjust"
+	ActionReviewApprove   = "review.approve"
+	ActionReviewReject    = "review.reject"
+	ActionAlertDismiss    = "sanctions.dismiss"
+	ActionAlertConfirm    = "sanctions.confirm"
+	ActionPIIRead         = "pii.read"
+	ActionPIIRewrap       = "pii.rewrap"
+	ActionAdminQuery      = "admin.query"
+)
+
+// Event is one administrative action. Events are append-only: the table
+// rejects updates and deletes.
+type Event struct {
+	ID         int64             `json:"id"`
+	Actor      string            `json:"actor"`
+	Action     string            `json:"action"`
+	TargetType string            `json:"target_type"`
+	TargetID   string            `json:"target_id"`
+	Diff       map[string]Change `json:"diff,omitempty"`
+	Details    map[string]any    `json:"details,omitempty"`
+	RequestID  string            `json:"request_id,omitempty"`
+	SourceIP   string            `json:"source_ip,omitempty"`
+	Source     string            `json:"source"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// Change is a field's value before and after an action
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// sensitiveKeys are fields whose values are personal data. Events
+// outlive account erasure, so they only record that these changed.
+var sensitiveKeys = map[string]bool{
+	"name":      true,
+	"email":     true,
+	"phone":     true,
+	"dob":       true,
+	"reference": true,
+}
+
+// Redact hides the value of a sensitive key, keeping whether it was set
+func Redact(key string, value any) any {
+	if !sensitiveKeys[strings.ToLower(key)] {
+		return value
+	}
+	if value == nil || value == "" {
+		return value
+	}
+	return pii.Redacted
+}
+
+// Diff returns the fields that differ between two snapshots, which may be
+// nil for creations and deletions. Snapshots are compared by their JSON
+// form, and sensitive values are redacted.
+func Diff(before, after any) map[string]Change {
+	b, a := flatten(before), flatten(after)
+
+	diff := make(map[string]Change)
+	for key, bv := range b {
+		if av, ok := a[key]; !ok || !reflect.DeepEqual(av, bv) {
+			diff[key] = Change{Before: Redact(key, bv), After: Redact(key, a[key])}
+		}
+	}
+	for key, av := range a {
+		if _, ok := b[key]; !ok {
+			diff[key] = Change{Before: nil, After: Redact(key, av)}
+		}
+	}
+
+	if len(diff) == 0 {
+		return nil
+	}
+	return diff
+}
+
+func flatten(v any) map[string]any {
+	m := map[string]any{}
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return m
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return m
+	}
+	json.Unmarshal(data, &m)
+	return m
+}
@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) Create(ctx context.Context, event *Event) error {
+	diff, err := nullJSON(event.Diff)
+	if err != nil {
+		return err
+	}
+	details, err := nullJSON(event.Details)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO audit_events
+	(actor, action, target_type, target_id, diff, details, request_id, source_ip, source)
+	VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9)
+	RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		event.Actor,
+		event.Action,
+		event.TargetType,
+		event.TargetID,
+		diff,
+		details,
+		event.RequestID,
+		event.SourceIP,
+		event.Source,
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+func (r *PostgresRepository) List(ctx context.Context, filter Filter) ([]Event, error) {
+	var (
+		where []string
+		args  []any
+	)
+
+	// arg binds a value and returns its placeholder
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.Actor != "" {
+		where = append(where, "actor = "+arg(filter.Actor))
+	}
+	if prefix, ok := strings.CutSuffix(filter.Action, "*"); ok {
+		where = append(where, "starts_with(action, "+arg(prefix)+")")
+	} else if filter.Action != "" {
+		where = append(where, "action = "+arg(filter.Action))
+	}
+	if filter.TargetType != "" {
+		where = append(where, "target_type = "+arg(filter.TargetType))
+	}
+	if filter.TargetID != "" {
+		where = append(where, "target_id = "+arg(filter.TargetID))
+	}
+	if filter.RequestID != "" {
+		where = append(where, "request_id = "+arg(filter.RequestID))
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "created_at < "+arg(filter.To))
+	}
+	if filter.BeforeID > 0 {
+		where = append(where, "id < "+arg(filter.BeforeID))
+	}
+
+	query := `
+	SELECT id, actor, action, target_type, target_id, diff, details,
+		COALESCE(request_id, ''), COALESCE(source_ip, ''), source, created_at
+	FROM audit_events
+	`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT " + arg(filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var (
+			e             Event
+			diff, details []byte
+		)
+		err := rows.Scan(
+			&e.ID,
+			&e.Actor,
+			&e.Action,
+			&e.TargetType,
+			&e.TargetID,
+			&diff,
+			&details,
+			&e.RequestID,
+			&e.SourceIP,
+			&e.Source,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(diff) > 0 {
+			if err := json.Unmarshal(diff, &e.Diff); err != nil {
+				return nil, err
+			}
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &e.Details); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// nullJSON encodes v for a JSONB column, NULL when empty
+func nullJSON[T any](v map[string]T) (any, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
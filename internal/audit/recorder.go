@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+const (
+	// DefaultLimit is used when a listing asks for no limit
+	DefaultLimit = 100
+
+	// MaxLimit caps a single listing
+	MaxLimit = 1000
+)
+
+// Recorder writes audit events for one source: "api" for the server and
+// "cli:<host>" for the admin CLI. A nil Recorder records nothing.
+type Recorder struct {
+	repo   Repository
+	source string
+}
+
+func NewRecorder(repo Repository, source string) *Recorder {
+	return &Recorder{repo: repo, source: source}
+}
+
+// Record appends event, stamped with the recorder's source. Events
+// without an actor are recorded as anonymous. Failures are
+// logged as well as returned, so callers that cannot undo the action they
+// audit may ignore the error.
+func (r *Recorder) Record(ctx context.Context, event *Event) error {
+	if r == nil {
+		return nil
+	}
+	if event.Actor == "" {
+		event.Actor = "anonymous"
+	}
+	event.Source = r.source
+
+	if err := r.repo.Create(ctx, event); err != nil {
+		slog.Error("audit event not recorded",
+			"error", err,
+			"action", event.Action,
+			"target_type", event.TargetType,
+			"target_id", event.TargetID,
+			"actor", event.Actor,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// List returns events matching filter, newest first
+func (r *Recorder) List(ctx context.Context, filter Filter) ([]Event, error) {
+	if r == nil {
+		return []Event{}, nil
+	}
+	if filter.Limit < 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultLimit
+	}
+	if filter.Limit > MaxLimit {
+		filter.Limit = MaxLimit
+	}
+
+	return r.repo.List(ctx, filter)
+}
@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Filter narrows an event listing. Zero values mean "no restriction". An
+// Action ending in * matches by prefix, e.g. account.*
+type Filter struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       time.Time // inclusive
+	To         time.Time // exclusive
+
+	// BeforeID pages backwards: only events older than this ID
+	BeforeID int64
+	Limit    int
+}
+
+type Repository interface {
+	// Append an event
+	Create(ctx context.Context, event *Event) error
+
+	// List events newest first
+	List(ctx context.Context, filter Filter) ([]Event, error)
+}
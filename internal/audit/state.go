@@ -0,0 +1,39 @@
+package audit
+
+import "gopherpay/internal/wallet"
+
+// AccountState is the part of an account that account updates change,
+// for Diff. Freezing and KYC are audited by their own actions.
+func AccountState(acc *wallet.Account) map[string]any {
+	if acc == nil {
+		return nil
+	}
+
+	dob := ""
+	if !acc.DOB.IsZero() {
+		dob = acc.DOB.Format("2006-01-02")
+	}
+
+	return map[string]any{
+		"name":    acc.Name,
+		"email":   acc.Email,
+		"phone":   acc.Phone,
+		"dob":     dob,
+		"balance": acc.Balance,
+		"tier":    acc.Tier,
+	}
+}
+
+// ReviewState is the part of a review that decisions and adjustments
+// change, for Diff
+func ReviewState(review *wallet.Review) map[string]any {
+	if review == nil {
+		return nil
+	}
+
+	return map[string]any{
+		"status": review.Status,
+		"amount": review.Amount,
+		"note":   review.Note,
+	}
+}
@@ -0,0 +1,317 @@
+package billing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ManifestFile is written next to the statements of a bulk run
+const ManifestFile = "manifest.json"
+
+// BulkOptions configures GenerateAll
+type BulkOptions struct {
+	Store   ReportStore
+	Dir     string // directory within Store, also holds the manifest
+	Filter  string // account number pattern, see ListAccountNumbers
+	Format  string
+	From    time.Time
+	To      time.Time // zero means the time the run first started
+	Workers int
+	Output  OutputOptions
+
+	// Progress, when set, is called after each account with the number of
+	// accounts finished so far (including ones skipped on resume)
+	Progress func(done, total int, accountNumber string, err error)
+}
+
+// Manifest records a bulk run: its parameters and one entry per finished
+// statement. It is rewritten after every statement, so an interrupted run
+// can be resumed by calling GenerateAll with the same options.
+type Manifest struct {
+	Format      string          `json:"format"`
+	Compression string          `json:"compression,omitempty"`
+	Encrypted   bool            `json:"encrypted,omitempty"`
+	Signed      bool            `json:"signed,omitempty"`
+	Filter      string          `json:"filter"`
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	StartedAt   time.Time       `json:"started_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	Accounts    int             `json:"accounts"`
+	Entries     []ManifestEntry `json:"entries"`
+}
+
+type ManifestEntry struct {
+	AccountNumber string    `json:"account_number"`
+	File          string    `json:"file"`
+	SHA256        string    `json:"sha256"`
+	Bytes         int64     `json:"bytes"`
+	Rows          int       `json:"rows"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
+
+// GenerateAll writes one statement per matching account into opts.Dir,
+// fanning out over opts.Workers goroutines, and records each file's
+// SHA-256 and row count in the manifest. Statements already listed in an
+// existing manifest whose files are intact are skipped. Failed accounts
+// are left out of the manifest so a rerun retries them.
+func (s *ReportService) GenerateAll(ctx context.Context, opts BulkOptions) (*Manifest, error) {
+	if !IsFormat(opts.Format) {
+		return nil, ErrUnsupportedFormat
+	}
+	if err := opts.Output.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+
+	manifest, err := loadManifest(ctx, opts.Store, opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest == nil {
+		now := time.Now().UTC()
+		manifest = &Manifest{
+			Format:      opts.Format,
+			Compression: opts.Output.Compression,
+			Encrypted:   len(opts.Output.Recipients) > 0,
+			Signed:      opts.Output.SigningKey != nil,
+			Filter:      opts.Filter,
+			From:        opts.From,
+			To:          opts.To,
+			StartedAt:   now,
+		}
+		// Pin an open-ended period so a resumed run covers the same range
+		if manifest.To.IsZero() {
+			manifest.To = now
+		}
+	} else if manifest.Format != opts.Format || manifest.Filter != opts.Filter ||
+		manifest.Compression != opts.Output.Compression ||
+		manifest.Encrypted != (len(opts.Output.Recipients) > 0) ||
+		manifest.Signed != (opts.Output.SigningKey != nil) ||
+		!manifest.From.Equal(opts.From) || (!opts.To.IsZero() && !manifest.To.Equal(opts.To)) {
+		return nil, fmt.Errorf("%s belongs to a different run (format %s, filter %q); choose another output directory",
+			opts.Store.Location(path.Join(opts.Dir, ManifestFile)), manifest.Format, manifest.Filter)
+	}
+
+	accounts, err := s.repo.ListAccountNumbers(ctx, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Accounts = len(accounts)
+	manifest.CompletedAt = nil
+
+	// Keep only entries whose file is still present and unmodified
+	done := make(map[string]bool)
+	intact := manifest.Entries[:0]
+	for _, e := range manifest.Entries {
+		name := path.Join(opts.Dir, e.File)
+		if sum, err := objectChecksum(ctx, opts.Store, name); err == nil && sum == e.SHA256 &&
+			(!manifest.Signed || objectExists(ctx, opts.Store, name+SignatureExt)) {
+			intact = append(intact, e)
+			done[e.AccountNumber] = true
+		}
+	}
+	manifest.Entries = intact
+
+	var (
+		mu       sync.Mutex
+		finished int
+		failed   int
+		saveErr  error
+	)
+
+	report := func(acct string, err error) {
+		finished++
+		if opts.Progress != nil {
+			opts.Progress(finished, len(accounts), acct, err)
+		}
+	}
+
+	var pending []string
+	for _, acct := range accounts {
+		if done[acct] {
+			report(acct, nil)
+			continue
+		}
+		pending = append(pending, acct)
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for acct := range jobs {
+				entry, err := s.generateBulkStatement(ctx, opts.Store, opts.Dir, acct, manifest, opts.Output)
+
+				mu.Lock()
+				if err == nil {
+					manifest.Entries = append(manifest.Entries, *entry)
+					if err := saveManifest(ctx, opts.Store, opts.Dir, manifest); err != nil && saveErr == nil {
+						saveErr = err
+					}
+				} else if ctx.Err() == nil {
+					failed++
+				}
+				report(acct, err)
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, acct := range pending {
+		select {
+		case jobs <- acct:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if saveErr != nil {
+		return manifest, saveErr
+	}
+	if err := ctx.Err(); err != nil {
+		return manifest, err
+	}
+	if failed > 0 {
+		return manifest, fmt.Errorf("%d of %d statements failed; rerun to retry them", failed, len(accounts))
+	}
+
+	completed := time.Now().UTC()
+	manifest.CompletedAt = &completed
+
+	return manifest, saveManifest(ctx, opts.Store, opts.Dir, manifest)
+}
+
+// generateBulkStatement writes one account's statement. The store only
+// publishes it once complete, so a crash never leaves a partial file that
+// looks complete. The checksum covers the object as stored, after
+// compression and encryption.
+func (s *ReportService) generateBulkStatement(
+	ctx context.Context,
+	store ReportStore,
+	dir string,
+	accountNumber string,
+	manifest *Manifest,
+	output OutputOptions,
+) (*ManifestEntry, error) {
+
+	file := path.Base(accountNumber) + "." + FileExtension(manifest.Format) + output.Suffix()
+	name := path.Join(dir, file)
+
+	obj, err := store.Create(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Abort()
+
+	hash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(obj, hash)}
+
+	w, err := output.wrap(counter)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := s.renderStatement(ctx, accountNumber, manifest.From, manifest.To, manifest.Format, false, w)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := obj.Commit(); err != nil {
+		return nil, err
+	}
+
+	if sig := w.Signature(); sig != nil {
+		if err := writeSignature(ctx, store, name, sig); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ManifestEntry{
+		AccountNumber: accountNumber,
+		File:          file,
+		SHA256:        hex.EncodeToString(hash.Sum(nil)),
+		Bytes:         counter.n,
+		Rows:          st.EntryCount,
+		GeneratedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// loadManifest returns the manifest in dir, or nil when there is none
+func loadManifest(ctx context.Context, store ReportStore, dir string) (*Manifest, error) {
+	r, err := store.Open(ctx, path.Join(dir, ManifestFile))
+	if errors.Is(err, ErrReportNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("corrupt manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+// saveManifest atomically replaces the manifest in dir
+func saveManifest(ctx context.Context, store ReportStore, dir string, m *Manifest) error {
+	slices.SortFunc(m.Entries, func(a, b ManifestEntry) int {
+		return strings.Compare(a.AccountNumber, b.AccountNumber)
+	})
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeObject(ctx, store, path.Join(dir, ManifestFile), data)
+}
+
+func objectExists(ctx context.Context, store ReportStore, name string) bool {
+	r, err := store.Open(ctx, name)
+	if err != nil {
+		return false
+	}
+	r.Close()
+	return true
+}
+
+func objectChecksum(ctx context.Context, store ReportStore, name string) (string, error) {
+	r, err := store.Open(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,313 @@
+package billing
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopherpay/internal/wallet"
+)
+
+// ISO 20022 BankToCustomerStatement, version 08. Field order follows the
+// schema sequence, which encoding/xml preserves.
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+type camtDocument struct {
+	XMLName xml.Name      `xml:"Document"`
+	Xmlns   string        `xml:"xmlns,attr"`
+	Stmt    camtBkToCstmr `xml:"BkToCstmrStmt"`
+}
+
+type camtBkToCstmr struct {
+	GrpHdr camtGrpHdr    `xml:"GrpHdr"`
+	Stmt   camtStatement `xml:"Stmt"`
+}
+
+type camtGrpHdr struct {
+	MsgId    string `xml:"MsgId"`
+	CreDtTm  string `xml:"CreDtTm"`
+	MsgPgntn struct {
+		PgNb      int  `xml:"PgNb"`
+		LastPgInd bool `xml:"LastPgInd"`
+	} `xml:"MsgPgntn"`
+}
+
+type camtStatement struct {
+	Id        string         `xml:"Id"`
+	CreDtTm   string         `xml:"CreDtTm"`
+	FrToDt    camtFrToDt     `xml:"FrToDt"`
+	Acct      camtAccount    `xml:"Acct"`
+	Bal       []camtBalance  `xml:"Bal"`
+	TxsSummry camtTxsSummary `xml:"TxsSummry"`
+	Ntry      []camtEntry    `xml:"Ntry"`
+}
+
+type camtFrToDt struct {
+	FrDtTm string `xml:"FrDtTm"`
+	ToDtTm string `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	Id   camtAccountId `xml:"Id"`
+	Ccy  string        `xml:"Ccy"`
+	Ownr *camtParty    `xml:"Ownr,omitempty"`
+}
+
+type camtAccountId struct {
+	Othr struct {
+		Id string `xml:"Id"`
+	} `xml:"Othr"`
+}
+
+type camtParty struct {
+	Nm string `xml:"Nm"`
+}
+
+type camtAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type camtBalance struct {
+	Tp struct {
+		CdOrPrtry struct {
+			Cd string `xml:"Cd"`
+		} `xml:"CdOrPrtry"`
+	} `xml:"Tp"`
+	Amt       camtAmount `xml:"Amt"`
+	CdtDbtInd string     `xml:"CdtDbtInd"`
+	Dt        struct {
+		Dt string `xml:"Dt"`
+	} `xml:"Dt"`
+}
+
+type camtTxsSummary struct {
+	TtlNtries    camtNumberAndSum `xml:"TtlNtries"`
+	TtlCdtNtries camtNumberAndSum `xml:"TtlCdtNtries"`
+	TtlDbtNtries camtNumberAndSum `xml:"TtlDbtNtries"`
+}
+
+type camtNumberAndSum struct {
+	NbOfNtries int    `xml:"NbOfNtries"`
+	Sum        string `xml:"Sum"`
+}
+
+type camtEntry struct {
+	NtryRef   string     `xml:"NtryRef"`
+	Amt       camtAmount `xml:"Amt"`
+	CdtDbtInd string     `xml:"CdtDbtInd"`
+	Sts       struct {
+		Cd string `xml:"Cd"`
+	} `xml:"Sts"`
+	BookgDt struct {
+		DtTm string `xml:"DtTm"`
+	} `xml:"BookgDt"`
+	ValDt struct {
+		Dt string `xml:"Dt"`
+	} `xml:"ValDt"`
+	AcctSvcrRef string        `xml:"AcctSvcrRef"`
+	BkTxCd      camtBkTxCd    `xml:"BkTxCd"`
+	NtryDtls    camtEntryDtls `xml:"NtryDtls"`
+}
+
+type camtBkTxCd struct {
+	Prtry struct {
+		Cd string `xml:"Cd"`
+	} `xml:"Prtry"`
+}
+
+type camtEntryDtls struct {
+	TxDtls camtTxDtls `xml:"TxDtls"`
+}
+
+type camtTxDtls struct {
+	Refs struct {
+		AcctSvcrRef string `xml:"AcctSvcrRef"`
+		EndToEndId  string `xml:"EndToEndId"`
+		UETR        string `xml:"UETR,omitempty"`
+	} `xml:"Refs"`
+	Amt       camtAmount       `xml:"Amt"`
+	CdtDbtInd string           `xml:"CdtDbtInd"`
+	RltdPties camtRelatedParty `xml:"RltdPties"`
+}
+
+type camtRelatedParty struct {
+	DbtrAcct *camtAccountId `xml:"DbtrAcct>Id,omitempty"`
+	CdtrAcct *camtAccountId `xml:"CdtrAcct>Id,omitempty"`
+}
+
+// WriteCamt053 renders st as an ISO 20022 camt.053.001.08 statement with
+// opening (OPBD) and closing (CLBD) booked balances. Each entry carries
+// the transaction ID as servicer reference and the request ID as
+// end-to-end ID.
+func WriteCamt053(w io.Writer, holder *wallet.Account, st *Statement, currency string) error {
+	created := st.GeneratedAt.UTC().Format(time.RFC3339)
+
+	doc := camtDocument{Xmlns: camt053Namespace}
+	doc.Stmt.GrpHdr.MsgId = maxLen(fmt.Sprintf("GP-%s-%d", st.AccountNumber, st.GeneratedAt.Unix()), 35)
+	doc.Stmt.GrpHdr.CreDtTm = created
+	doc.Stmt.GrpHdr.MsgPgntn.PgNb = 1
+	doc.Stmt.GrpHdr.MsgPgntn.LastPgInd = true
+
+	stmt := &doc.Stmt.Stmt
+	stmt.Id = maxLen(fmt.Sprintf("%s-%s-%s",
+		st.AccountNumber, periodStart(st).Format("20060102"), periodEnd(st).Format("20060102")), 35)
+	stmt.CreDtTm = created
+	stmt.FrToDt = camtFrToDt{
+		FrDtTm: periodStart(st).UTC().Format(time.RFC3339),
+		ToDtTm: st.To.UTC().Format(time.RFC3339),
+	}
+
+	stmt.Acct.Id.Othr.Id = st.AccountNumber
+	stmt.Acct.Ccy = currency
+	if holder != nil && holder.Name != "" {
+		stmt.Acct.Ownr = &camtParty{Nm: maxLen(holder.Name, 140)}
+	}
+
+	stmt.Bal = []camtBalance{
+		camtBal("OPBD", st.OpeningBalance, periodStart(st), currency),
+		camtBal("CLBD", st.ClosingBalance, periodEnd(st), currency),
+	}
+
+	var credits, debits int
+	for _, e := range st.Entries {
+		if e.Type == EntryDebit {
+			debits++
+		} else {
+			credits++
+		}
+	}
+	stmt.TxsSummry = camtTxsSummary{
+		TtlNtries:    camtNumberAndSum{st.EntryCount, decimalAmount(st.TotalCredits + st.TotalDebits)},
+		TtlCdtNtries: camtNumberAndSum{credits, decimalAmount(st.TotalCredits)},
+		TtlDbtNtries: camtNumberAndSum{debits, decimalAmount(st.TotalDebits)},
+	}
+
+	for _, e := range st.Entries {
+		ref := strconv.FormatInt(e.TransactionID, 10)
+		amount := camtAmount{Ccy: currency, Value: decimalAmount(abs(e.Amount))}
+		indicator := creditDebitIndicator(e.Amount)
+
+		var ntry camtEntry
+		ntry.NtryRef = ref
+		ntry.Amt = amount
+		ntry.CdtDbtInd = indicator
+		ntry.Sts.Cd = "BOOK"
+		ntry.BookgDt.DtTm = e.BookedAt.UTC().Format(time.RFC3339)
+		ntry.ValDt.Dt = e.BookedAt.Format("2006-01-02")
+		ntry.AcctSvcrRef = ref
+		ntry.BkTxCd.Prtry.Cd = "TRANSFER"
+		if e.Kind == KindFee {
+			ntry.BkTxCd.Prtry.Cd = "FEE"
+		}
+
+		tx := &ntry.NtryDtls.TxDtls
+		tx.Refs.AcctSvcrRef = ref
+		tx.Refs.EndToEndId = endToEndID(e.RequestID)
+		if uetrPattern.MatchString(e.RequestID) {
+			tx.Refs.UETR = e.RequestID
+		}
+		tx.Amt = amount
+		tx.CdtDbtInd = indicator
+
+		counterparty := &camtAccountId{}
+		counterparty.Othr.Id = e.Counterparty
+		if e.Type == EntryDebit {
+			tx.RltdPties.CdtrAcct = counterparty
+		} else {
+			tx.RltdPties.DbtrAcct = counterparty
+		}
+
+		stmt.Ntry = append(stmt.Ntry, ntry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func camtBal(code string, balance int64, at time.Time, currency string) camtBalance {
+	var b camtBalance
+	b.Tp.CdOrPrtry.Cd = code
+	b.Amt = camtAmount{Ccy: currency, Value: decimalAmount(abs(balance))}
+	b.CdtDbtInd = creditDebitIndicator(balance)
+	b.Dt.Dt = at.Format("2006-01-02")
+	return b
+}
+
+func creditDebitIndicator(amount int64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// uetrPattern is the UUIDv4Identifier pattern of the schema; generated
+// request IDs match it and are also reported as UETR
+var uetrPattern = regexp.MustCompile(`^[a-f0-9]{8}-[a-f0-9]{4}-4[a-f0-9]{3}-[89ab][a-f0-9]{3}-[a-f0-9]{12}$`)
+
+// endToEndID maps a request ID onto Max35Text. A UUID is 36 characters
+// with hyphens, so those are dropped rather than cutting off the tail.
+// A missing ID becomes NOTPROVIDED, the ISO 20022 convention.
+func endToEndID(requestID string) string {
+	if requestID == "" {
+		return "NOTPROVIDED"
+	}
+	if len(requestID) > 35 {
+		requestID = strings.ReplaceAll(requestID, "-", "")
+	}
+	return maxLen(requestID, 35)
+}
+
+// periodStart is the statement start, or the first entry when the
+// statement covers the whole history
+func periodStart(st *Statement) time.Time {
+	if !st.From.IsZero() {
+		return st.From
+	}
+	if len(st.Entries) > 0 {
+		return st.Entries[0].BookedAt
+	}
+	return periodEnd(st)
+}
+
+// periodEnd is the last instant of the statement. To is exclusive, so a
+// March statement ends on 31 March, which is the date banks expect on
+// the closing balance.
+func periodEnd(st *Statement) time.Time {
+	return st.To.Add(-time.Nanosecond)
+}
+
+// decimalAmount renders a non-negative minor-unit amount as 1234.56
+func decimalAmount(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// maxLen cuts s to at most n runes, for length-limited identifier fields
+func maxLen(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
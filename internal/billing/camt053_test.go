@@ -0,0 +1,218 @@
+package billing
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopherpay/internal/wallet"
+)
+
+const (
+	creditRequestID = "3f2b8c1e-9d4a-4b6e-8f10-2a7c5d9e1b34"
+	debitRequestID  = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+)
+
+// testStatement is a March statement with a credit, a debit and the fee
+// charged for that debit under the same request ID
+func testStatement() *Statement {
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+
+	return &Statement{
+		AccountNumber:  "ACC1001",
+		From:           day(1, 0),
+		To:             time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		GeneratedAt:    time.Date(2026, 4, 1, 6, 0, 0, 0, time.UTC),
+		OpeningBalance: 10000,
+		TotalCredits:   5000,
+		TotalDebits:    2030,
+		TotalFees:      30,
+		ClosingBalance: 12970,
+		EntryCount:     3,
+		Entries: []StatementEntry{
+			{TransactionID: 101, RequestID: creditRequestID, BookedAt: day(3, 9), Counterparty: "ACC2002",
+				Type: EntryCredit, Kind: KindTransfer, Amount: 5000, Balance: 15000},
+			{TransactionID: 102, RequestID: debitRequestID, BookedAt: day(10, 14), Counterparty: "ACC3003",
+				Type: EntryDebit, Kind: KindTransfer, Amount: -2000, Balance: 13000},
+			{TransactionID: 103, RequestID: debitRequestID, BookedAt: day(10, 14), Counterparty: "HOUSE-REVENUE",
+				Type: EntryDebit, Kind: KindFee, Amount: -30, Balance: 12970},
+		},
+	}
+}
+
+func writeCamt053(t *testing.T, st *Statement) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := WriteCamt053(&buf, &wallet.Account{Name: "Jane Doe"}, st, "EUR"); err != nil {
+		t.Fatalf("WriteCamt053: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestWriteCamt053Schema validates the output with xmllint against the
+// camt.053.001.08 schema: the subset in testdata, or the full published
+// schema when CAMT053_XSD names it
+func TestWriteCamt053Schema(t *testing.T) {
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint not installed")
+	}
+
+	xsd := os.Getenv("CAMT053_XSD")
+	if xsd == "" {
+		xsd = filepath.Join("testdata", "camt.053.001.08.xsd")
+	}
+
+	empty := testStatement()
+	empty.Entries, empty.EntryCount = nil, 0
+	empty.TotalCredits, empty.TotalDebits, empty.TotalFees = 0, 0, 0
+	empty.ClosingBalance = empty.OpeningBalance
+
+	overdrawn := testStatement()
+	overdrawn.OpeningBalance = -1000
+	overdrawn.ClosingBalance = 1970
+
+	for name, st := range map[string]*Statement{
+		"entries":   testStatement(),
+		"empty":     empty,
+		"overdrawn": overdrawn,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "statement.xml")
+			if err := os.WriteFile(path, writeCamt053(t, st), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := exec.Command(xmllint, "--noout", "--schema", xsd, path).CombinedOutput()
+			if err != nil {
+				t.Fatalf("schema validation failed: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+func TestWriteCamt053(t *testing.T) {
+	out := writeCamt053(t, testStatement())
+
+	if !bytes.HasPrefix(out, []byte(xml.Header)) {
+		t.Errorf("output does not start with the XML declaration")
+	}
+
+	var doc camtDocument
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("output does not parse: %v", err)
+	}
+	if doc.Xmlns != camt053Namespace {
+		t.Errorf("namespace = %q, want %q", doc.Xmlns, camt053Namespace)
+	}
+
+	stmt := doc.Stmt.Stmt
+	if stmt.Id != "ACC1001-20260301-20260331" {
+		t.Errorf("statement Id = %q, want ACC1001-20260301-20260331", stmt.Id)
+	}
+	if stmt.Acct.Id.Othr.Id != "ACC1001" || stmt.Acct.Ccy != "EUR" {
+		t.Errorf("account = %s %s, want ACC1001 EUR", stmt.Acct.Id.Othr.Id, stmt.Acct.Ccy)
+	}
+
+	wantBal := []struct{ code, amount, indicator, date string }{
+		{"OPBD", "100.00", "CRDT", "2026-03-01"},
+		// To is exclusive: March closes on the 31st
+		{"CLBD", "129.70", "CRDT", "2026-03-31"},
+	}
+	if len(stmt.Bal) != len(wantBal) {
+		t.Fatalf("got %d balances, want %d", len(stmt.Bal), len(wantBal))
+	}
+	for i, want := range wantBal {
+		b := stmt.Bal[i]
+		got := []string{b.Tp.CdOrPrtry.Cd, b.Amt.Value, b.CdtDbtInd, b.Dt.Dt}
+		if strings.Join(got, " ") != strings.Join([]string{want.code, want.amount, want.indicator, want.date}, " ") {
+			t.Errorf("balance %d = %v, want %+v", i, got, want)
+		}
+	}
+
+	sum := stmt.TxsSummry
+	if sum.TtlNtries != (camtNumberAndSum{3, "70.30"}) ||
+		sum.TtlCdtNtries != (camtNumberAndSum{1, "50.00"}) ||
+		sum.TtlDbtNtries != (camtNumberAndSum{2, "20.30"}) {
+		t.Errorf("summary = %+v", sum)
+	}
+
+	wantNtry := []struct {
+		ref, amount, indicator, code, endToEnd, uetr, cdtr, dbtr string
+	}{
+		{"101", "50.00", "CRDT", "TRANSFER", strings.ReplaceAll(creditRequestID, "-", ""), creditRequestID, "", "ACC2002"},
+		{"102", "20.00", "DBIT", "TRANSFER", strings.ReplaceAll(debitRequestID, "-", ""), debitRequestID, "ACC3003", ""},
+		{"103", "0.30", "DBIT", "FEE", strings.ReplaceAll(debitRequestID, "-", ""), debitRequestID, "HOUSE-REVENUE", ""},
+	}
+	if len(stmt.Ntry) != len(wantNtry) {
+		t.Fatalf("got %d entries, want %d", len(stmt.Ntry), len(wantNtry))
+	}
+	for i, want := range wantNtry {
+		n := stmt.Ntry[i]
+		tx := n.NtryDtls.TxDtls
+
+		if n.NtryRef != want.ref || n.AcctSvcrRef != want.ref || tx.Refs.AcctSvcrRef != want.ref {
+			t.Errorf("entry %d references = %s/%s/%s, want %s", i, n.NtryRef, n.AcctSvcrRef, tx.Refs.AcctSvcrRef, want.ref)
+		}
+		if n.Amt.Value != want.amount || n.Amt.Ccy != "EUR" || n.CdtDbtInd != want.indicator {
+			t.Errorf("entry %d = %s %s %s, want %s EUR %s", i, n.Amt.Value, n.Amt.Ccy, n.CdtDbtInd, want.amount, want.indicator)
+		}
+		if n.Sts.Cd != "BOOK" || n.BkTxCd.Prtry.Cd != want.code {
+			t.Errorf("entry %d status/code = %s/%s, want BOOK/%s", i, n.Sts.Cd, n.BkTxCd.Prtry.Cd, want.code)
+		}
+		if tx.Refs.EndToEndId != want.endToEnd || tx.Refs.UETR != want.uetr {
+			t.Errorf("entry %d EndToEndId/UETR = %s/%s, want %s/%s", i, tx.Refs.EndToEndId, tx.Refs.UETR, want.endToEnd, want.uetr)
+		}
+
+		var cdtr, dbtr string
+		if tx.RltdPties.CdtrAcct != nil {
+			cdtr = tx.RltdPties.CdtrAcct.Othr.Id
+		}
+		if tx.RltdPties.DbtrAcct != nil {
+			dbtr = tx.RltdPties.DbtrAcct.Othr.Id
+		}
+		if cdtr != want.cdtr || dbtr != want.dbtr {
+			t.Errorf("entry %d creditor/debtor = %q/%q, want %q/%q", i, cdtr, dbtr, want.cdtr, want.dbtr)
+		}
+	}
+}
+
+func TestEndToEndID(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", "NOTPROVIDED"},
+		{"batch-7", "batch-7"},
+		{creditRequestID, strings.ReplaceAll(creditRequestID, "-", "")},
+		{strings.Repeat("x", 40), strings.Repeat("x", 35)},
+	}
+
+	for _, tt := range tests {
+		if got := endToEndID(tt.in); got != tt.want {
+			t.Errorf("endToEndID(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPeriodEnd(t *testing.T) {
+	tests := []struct {
+		name string
+		to   time.Time
+		want string
+	}{
+		{"month end", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), "2026-03-31"},
+		{"year end", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), "2026-12-31"},
+		// A statement that runs up to now ends today
+		{"now", time.Date(2026, 4, 14, 9, 30, 0, 0, time.UTC), "2026-04-14"},
+	}
+
+	for _, tt := range tests {
+		if got := periodEnd(&Statement{To: tt.to}).Format("2006-01-02"); got != tt.want {
+			t.Errorf("%s: periodEnd = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package billing
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatPDF     = "pdf"
+	FormatCamt053 = "camt053"
+	FormatMT940   = "mt940"
+	FormatOFX     = "ofx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// Formats lists every statement format RenderStatement accepts
+var Formats = []string{FormatCSV, FormatNDJSON, FormatPDF, FormatCamt053, FormatMT940, FormatOFX}
+
+// IsFormat reports whether format is a supported statement format
+func IsFormat(format string) bool {
+	return slices.Contains(Formats, format)
+}
+
+// flushEvery is how many rows are buffered before being pushed to the writer
+const flushEvery = 500
+
+// Flusher is implemented by writers that can push buffered bytes to their
+// destination, e.g. an HTTP response wrapped in http.ResponseController.
+type Flusher interface {
+	Flush() error
+}
+
+// ContentType returns the MIME type for an export format
+func ContentType(format string) string {
+	switch format {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatCamt053:
+		return "application/xml"
+	case FormatMT940:
+		return "text/plain; charset=us-ascii"
+	case FormatOFX:
+		return "application/x-ofx"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// FileExtension returns the conventional file extension for a format
+func FileExtension(format string) string {
+	switch format {
+	case FormatCamt053:
+		return "xml"
+	case FormatMT940:
+		return "sta"
+	default:
+		return format
+	}
+}
+
+// WriteStatement streams the account statement for [from, to) to w in the
+// given format. Entries go straight from the database cursor to w, so
+// memory use does not grow with the number of rows. Cancelling ctx aborts
+// the query.
+func (s *ReportService) WriteStatement(
+	ctx context.Context,
+	accountNumber string,
+	from time.Time,
+	to time.Time,
+	format string,
+	w io.Writer,
+) error {
+
+	_, err := s.writeStatement(ctx, accountNumber, from, to, format, w)
+	return err
+}
+
+func (s *ReportService) writeStatement(
+	ctx context.Context,
+	accountNumber string,
+	from time.Time,
+	to time.Time,
+	format string,
+	w io.Writer,
+) (*Statement, error) {
+
+	enc, err := newStatementEncoder(format, w)
+	if err != nil {
+		return nil, err
+	}
+
+	flush := func() error {
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(Flusher); ok {
+			return f.Flush()
+		}
+		return nil
+	}
+
+	n := 0
+	st, err := s.streamStatement(ctx, accountNumber, from, to,
+		enc.Begin,
+		func(e StatementEntry) error {
+			if err := enc.Entry(e); err != nil {
+				return err
+			}
+			n++
+			if n%flushEvery == 0 {
+				return flush()
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enc.End(st); err != nil {
+		return nil, err
+	}
+
+	return st, flush()
+}
+
+// statementEncoder writes a statement in one output format
+type statementEncoder interface {
+	Begin(st *Statement) error
+	Entry(e StatementEntry) error
+	End(st *Statement) error
+	Flush() error
+}
+
+func newStatementEncoder(format string, w io.Writer) (statementEncoder, error) {
+	switch format {
+	case FormatCSV:
+		return &csvStatementEncoder{w: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonStatementEncoder{bw: bw, enc: json.NewEncoder(bw)}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// csvStatementEncoder writes one row per entry, framed by an opening
+// balance row and total/closing balance rows
+type csvStatementEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvStatementEncoder) Begin(st *Statement) error {
+	if err := e.w.Write([]string{
+		"booked_at",
+		"transaction_id",
+		"request_id",
+		"counterparty",
+		"type",
+		"amount",
+		"balance",
+		"kind",
+	}); err != nil {
+		return err
+	}
+
+	return e.summary(st.From, "opening_balance", "", formatInt(st.OpeningBalance))
+}
+
+func (e *csvStatementEncoder) Entry(entry StatementEntry) error {
+	return e.w.Write([]string{
+		entry.BookedAt.Format(time.RFC3339Nano),
+		formatInt(entry.TransactionID),
+		entry.RequestID,
+		entry.Counterparty,
+		entry.Type,
+		formatInt(entry.Amount),
+		formatInt(entry.Balance),
+		entry.Kind,
+	})
+}
+
+func (e *csvStatementEncoder) End(st *Statement) error {
+	if err := e.summary(st.To, "total_debits", formatInt(-st.TotalDebits), ""); err != nil {
+		return err
+	}
+	if err := e.summary(st.To, "total_credits", formatInt(st.TotalCredits), ""); err != nil {
+		return err
+	}
+	if err := e.summary(st.To, "total_fees", formatInt(-st.TotalFees), ""); err != nil {
+		return err
+	}
+	return e.summary(st.To, "closing_balance", "", formatInt(st.ClosingBalance))
+}
+
+func (e *csvStatementEncoder) summary(at time.Time, kind, amount, balance string) error {
+	booked := ""
+	if !at.IsZero() {
+		booked = at.Format(time.RFC3339Nano)
+	}
+	return e.w.Write([]string{booked, "", "", "", kind, amount, balance, ""})
+}
+
+func (e *csvStatementEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ndjsonStatementEncoder writes an "opening" line, one "entry" line per
+// transaction and a "closing" line with the totals
+type ndjsonStatementEncoder struct {
+	bw  *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonStatementEncoder) Begin(st *Statement) error {
+	return e.enc.Encode(map[string]any{
+		"record":          "opening",
+		"account_number":  st.AccountNumber,
+		"from":            st.From,
+		"to":              st.To,
+		"opening_balance": st.OpeningBalance,
+	})
+}
+
+func (e *ndjsonStatementEncoder) Entry(entry StatementEntry) error {
+	return e.enc.Encode(struct {
+		Record string `json:"record"`
+		StatementEntry
+	}{"entry", entry})
+}
+
+func (e *ndjsonStatementEncoder) End(st *Statement) error {
+	return e.enc.Encode(map[string]any{
+		"record":          "closing",
+		"total_debits":    st.TotalDebits,
+		"total_credits":   st.TotalCredits,
+		"total_fees":      st.TotalFees,
+		"entry_count":     st.EntryCount,
+		"closing_balance": st.ClosingBalance,
+	})
+}
+
+func (e *ndjsonStatementEncoder) Flush() error {
+	return e.bw.Flush()
+}
+
+// scanTransactionView reads one row in the column order shared by the
+// report repository queries. The raw created_at is returned alongside
+// for callers that need it as a time.
+func scanTransactionView(rows *sql.Rows) (TransactionView, time.Time, error) {
+	var (
+		tv        TransactionView
+		requestID sql.NullString
+		createdAt time.Time
+	)
+
+	if err := rows.Scan(
+		&tv.ID,
+		&tv.From,
+		&tv.To,
+		&tv.Amount,
+		&tv.Status,
+		&requestID,
+		&createdAt,
+		&tv.Kind,
+	); err != nil {
+		return tv, createdAt, err
+	}
+
+	tv.RequestID = requestID.String
+	tv.CreatedAt = createdAt.Format(time.RFC3339Nano)
+
+	return tv, createdAt, nil
+}
+
+func formatInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,101 @@
+package billing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteMT940 renders st as a SWIFT MT940 customer statement (text block
+// only, CRLF line endings). The :61: bank reference is the transaction ID
+// and the request ID is carried as /EREF/ in :86:.
+func WriteMT940(w io.Writer, st *Statement, currency string) error {
+	bw := bufio.NewWriter(w)
+
+	field := func(tag, value string) {
+		bw.WriteString(":" + tag + ":" + value + "\r\n")
+	}
+
+	// :20: transaction reference, 16x
+	field("20", swiftText(fmt.Sprintf("GP%d", st.GeneratedAt.Unix()), 16))
+	// :25: account identification, 35x
+	field("25", swiftText(st.AccountNumber, 35))
+	// :28C: statement number / sequence number
+	field("28C", "00001/001")
+	// :60F: first opening balance
+	field("60F", mt940Balance(st.OpeningBalance, periodStart(st), currency))
+
+	for _, e := range st.Entries {
+		mark := "C"
+		if e.Type == EntryDebit {
+			mark = "D"
+		}
+
+		// NCHG marks charges, NTRF transfers
+		code := "NTRF"
+		if e.Kind == KindFee {
+			code = "NCHG"
+		}
+
+		// value date, entry date (MMDD), mark, amount, type code,
+		// customer reference, // bank reference
+		field("61", e.BookedAt.Format("060102")+e.BookedAt.Format("0102")+
+			mark+mt940Amount(abs(e.Amount))+code+"NONREF"+
+			"//"+swiftText(strconv.FormatInt(e.TransactionID, 10), 16))
+
+		info := "/EREF/" + e.RequestID + "/CPTY/" + e.Counterparty
+		if e.RequestID == "" {
+			info = "/EREF/NOTPROVIDED/CPTY/" + e.Counterparty
+		}
+		bw.WriteString(":86:" + strings.Join(wrapSwift(swiftText(info, 6*65), 65), "\r\n") + "\r\n")
+	}
+
+	// :62F: final closing balance
+	field("62F", mt940Balance(st.ClosingBalance, periodEnd(st), currency))
+	bw.WriteString("-\r\n")
+
+	return bw.Flush()
+}
+
+// mt940Balance formats a balance field: D/C mark, YYMMDD, currency, amount
+func mt940Balance(balance int64, at time.Time, currency string) string {
+	mark := "C"
+	if balance < 0 {
+		mark = "D"
+	}
+	return mark + at.Format("060102") + currency + mt940Amount(abs(balance))
+}
+
+// mt940Amount renders a non-negative minor-unit amount with a decimal
+// comma, as 1234,56
+func mt940Amount(cents int64) string {
+	return fmt.Sprintf("%d,%02d", cents/100, cents%100)
+}
+
+// swiftText restricts s to the SWIFT x character set and n characters
+func swiftText(s string, n int) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			strings.ContainsRune("/-?:().,'+ ", r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('.')
+		}
+	}
+	return maxLen(b.String(), n)
+}
+
+// wrapSwift splits s into lines of at most width characters
+func wrapSwift(s string, width int) []string {
+	var lines []string
+	for len(s) > width {
+		lines = append(lines, s[:width])
+		s = s[width:]
+	}
+	return append(lines, s)
+}
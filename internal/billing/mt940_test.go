@@ -0,0 +1,174 @@
+package billing
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// mt940Field is one tagged field; continuation lines are kept separate
+type mt940Field struct {
+	tag   string
+	lines []string
+}
+
+// parseMT940 splits a statement into its fields, checking the CRLF line
+// endings and the closing "-" on the way
+func parseMT940(t *testing.T, out []byte) []mt940Field {
+	t.Helper()
+
+	text := string(out)
+	if !strings.HasSuffix(text, "\r\n-\r\n") {
+		t.Fatalf("statement does not end with a \"-\" line")
+	}
+	if strings.Count(text, "\n") != strings.Count(text, "\r\n") {
+		t.Fatalf("statement has bare LF line endings")
+	}
+
+	tagged := regexp.MustCompile(`^:([0-9]{2}[A-Z]?):(.*)$`)
+
+	var fields []mt940Field
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\r\n-\r\n"), "\r\n") {
+		if m := tagged.FindStringSubmatch(line); m != nil {
+			fields = append(fields, mt940Field{tag: m[1], lines: []string{m[2]}})
+			continue
+		}
+		if len(fields) == 0 {
+			t.Fatalf("untagged first line %q", line)
+		}
+		last := &fields[len(fields)-1]
+		last.lines = append(last.lines, line)
+	}
+	return fields
+}
+
+func TestWriteMT940(t *testing.T) {
+	st := testStatement()
+
+	var buf bytes.Buffer
+	if err := WriteMT940(&buf, st, "EUR"); err != nil {
+		t.Fatalf("WriteMT940: %v", err)
+	}
+	fields := parseMT940(t, buf.Bytes())
+
+	var tags []string
+	for _, f := range fields {
+		tags = append(tags, f.tag)
+	}
+	wantTags := "20 25 28C 60F 61 86 61 86 61 86 62F"
+	if got := strings.Join(tags, " "); got != wantTags {
+		t.Fatalf("tags = %s, want %s", got, wantTags)
+	}
+
+	value := func(i int) string { return fields[i].lines[0] }
+
+	if ref := value(0); ref == "" || len(ref) > 16 {
+		t.Errorf(":20: = %q, want 1-16 characters", ref)
+	}
+	if got := value(1); got != "ACC1001" {
+		t.Errorf(":25: = %q, want ACC1001", got)
+	}
+	if got := value(2); got != "00001/001" {
+		t.Errorf(":28C: = %q, want 00001/001", got)
+	}
+	if got := value(3); got != "C260301EUR100,00" {
+		t.Errorf(":60F: = %q, want C260301EUR100,00", got)
+	}
+	// To is exclusive: March closes on the 31st
+	if got := value(len(fields) - 1); got != "C260331EUR129,70" {
+		t.Errorf(":62F: = %q, want C260331EUR129,70", got)
+	}
+
+	// value date, entry date, mark, amount, type code, references
+	line61 := regexp.MustCompile(`^([0-9]{6})([0-9]{4})([CD])([0-9]{1,12},[0-9]{2})N([A-Z]{3})NONREF//([0-9]{1,16})$`)
+
+	wantEntries := []struct {
+		date, mark, amount, code, ref, requestID, counterparty string
+	}{
+		{"2603030303", "C", "50,00", "TRF", "101", creditRequestID, "ACC2002"},
+		{"2603100310", "D", "20,00", "TRF", "102", debitRequestID, "ACC3003"},
+		{"2603100310", "D", "0,30", "CHG", "103", debitRequestID, "HOUSE-REVENUE"},
+	}
+
+	balance := st.OpeningBalance
+	for i, want := range wantEntries {
+		f61, f86 := fields[4+2*i], fields[5+2*i]
+
+		m := line61.FindStringSubmatch(f61.lines[0])
+		if m == nil {
+			t.Errorf(":61: %q does not match the field format", f61.lines[0])
+			continue
+		}
+		got := []string{m[1] + m[2], m[3], m[4], m[5], m[6]}
+		if strings.Join(got, " ") != strings.Join([]string{want.date, want.mark, want.amount, want.code, want.ref}, " ") {
+			t.Errorf(":61: %d = %v, want %+v", i, got, want)
+		}
+
+		info := strings.Join(f86.lines, "")
+		if wantInfo := "/EREF/" + want.requestID + "/CPTY/" + want.counterparty; info != wantInfo {
+			t.Errorf(":86: %d = %q, want %q", i, info, wantInfo)
+		}
+
+		cents, _ := strconv.ParseInt(strings.Replace(m[4], ",", "", 1), 10, 64)
+		if m[3] == "D" {
+			cents = -cents
+		}
+		balance += cents
+	}
+
+	if balance != st.ClosingBalance {
+		t.Errorf("opening balance plus :61: entries = %d, want closing balance %d", balance, st.ClosingBalance)
+	}
+}
+
+func TestWriteMT940Wrapping(t *testing.T) {
+	st := testStatement()
+	st.Entries = st.Entries[:1]
+	st.Entries[0].RequestID = ""
+	st.Entries[0].Counterparty = strings.Repeat("ACC_", 40)
+
+	var buf bytes.Buffer
+	if err := WriteMT940(&buf, st, "EUR"); err != nil {
+		t.Fatalf("WriteMT940: %v", err)
+	}
+
+	for _, f := range parseMT940(t, buf.Bytes()) {
+		if f.tag != "86" {
+			continue
+		}
+		if len(f.lines) < 2 || len(f.lines) > 6 {
+			t.Errorf(":86: has %d lines, want 2-6", len(f.lines))
+		}
+		for _, line := range f.lines {
+			if len(line) > 65 {
+				t.Errorf(":86: line of %d characters: %q", len(line), line)
+			}
+		}
+		info := strings.Join(f.lines, "")
+		if !strings.HasPrefix(info, "/EREF/NOTPROVIDED/CPTY/ACC.ACC.") {
+			t.Errorf(":86: = %q, want NOTPROVIDED reference and '_' replaced", info)
+		}
+	}
+}
+
+func TestMT940Balance(t *testing.T) {
+	at := testStatement().To
+
+	tests := []struct {
+		balance int64
+		want    string
+	}{
+		{0, "C260401EUR0,00"},
+		{5, "C260401EUR0,05"},
+		{123456, "C260401EUR1234,56"},
+		{-2500, "D260401EUR25,00"},
+	}
+
+	for _, tt := range tests {
+		if got := mt940Balance(tt.balance, at, "EUR"); got != tt.want {
+			t.Errorf("mt940Balance(%d) = %q, want %q", tt.balance, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package billing
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// OFX 2.2 bank statement response. Element order follows the OFX
+// specification, which encoding/xml preserves.
+const ofxHeader = `<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+
+// ofxBankID identifies GopherPay as the financial institution (A-9)
+const ofxBankID = "GOPHERPAY"
+
+type ofxDocument struct {
+	XMLName xml.Name     `xml:"OFX"`
+	Signon  ofxSignon    `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank    ofxStmtTrnRs `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignon struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DTServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+	FI       struct {
+		Org string `xml:"ORG"`
+		FID string `xml:"FID"`
+	} `xml:"FI"`
+}
+
+type ofxStmtTrnRs struct {
+	TrnUID string    `xml:"TRNUID"`
+	Status ofxStatus `xml:"STATUS"`
+	StmtRs ofxStmtRs `xml:"STMTRS"`
+}
+
+type ofxStmtRs struct {
+	CurDef   string `xml:"CURDEF"`
+	AcctFrom struct {
+		BankID   string `xml:"BANKID"`
+		AcctID   string `xml:"ACCTID"`
+		AcctType string `xml:"ACCTTYPE"`
+	} `xml:"BANKACCTFROM"`
+	TranList struct {
+		DTStart string       `xml:"DTSTART"`
+		DTEnd   string       `xml:"DTEND"`
+		Trns    []ofxStmtTrn `xml:"STMTTRN"`
+	} `xml:"BANKTRANLIST"`
+	LedgerBal ofxBalance `xml:"LEDGERBAL"`
+}
+
+type ofxStmtTrn struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DTPosted string `xml:"DTPOSTED"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FITID    string `xml:"FITID"`
+	Name     string `xml:"NAME,omitempty"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	BalAmt string `xml:"BALAMT"`
+	DTAsOf string `xml:"DTASOF"`
+}
+
+// WriteOFX renders st as an OFX 2.2 bank statement for personal finance
+// tools. FITID is the transaction ID, which is unique and stable, so
+// re-importing an overlapping period does not duplicate entries. Amounts
+// are signed from the account's perspective; LEDGERBAL is the closing
+// balance.
+func WriteOFX(w io.Writer, st *Statement, currency string) error {
+	var doc ofxDocument
+
+	doc.Signon.Status = ofxStatus{Code: 0, Severity: "INFO"}
+	doc.Signon.DTServer = ofxTime(st.GeneratedAt)
+	doc.Signon.Language = "ENG"
+	doc.Signon.FI.Org = "GopherPay"
+	doc.Signon.FI.FID = ofxBankID
+
+	doc.Bank.TrnUID = fmt.Sprintf("%s-%d", st.AccountNumber, st.GeneratedAt.Unix())
+	doc.Bank.Status = ofxStatus{Code: 0, Severity: "INFO"}
+
+	rs := &doc.Bank.StmtRs
+	rs.CurDef = currency
+	rs.AcctFrom.BankID = ofxBankID
+	rs.AcctFrom.AcctID = maxLen(st.AccountNumber, 22)
+	rs.AcctFrom.AcctType = "CHECKING"
+
+	rs.TranList.DTStart = ofxTime(periodStart(st))
+	rs.TranList.DTEnd = ofxTime(st.To)
+
+	for _, e := range st.Entries {
+		trnType := "CREDIT"
+		memo := "Transfer from " + e.Counterparty
+		if e.Type == EntryDebit {
+			trnType = "DEBIT"
+			memo = "Transfer to " + e.Counterparty
+		}
+		if e.Kind == KindFee {
+			memo = "Fee from " + e.Counterparty
+			if e.Type == EntryDebit {
+				trnType = "FEE"
+				memo = "Transfer fee"
+			}
+		}
+		if e.RequestID != "" {
+			memo += " ref " + e.RequestID
+		}
+
+		rs.TranList.Trns = append(rs.TranList.Trns, ofxStmtTrn{
+			TrnType:  trnType,
+			DTPosted: ofxTime(e.BookedAt),
+			TrnAmt:   signedDecimal(e.Amount),
+			FITID:    strconv.FormatInt(e.TransactionID, 10),
+			Name:     maxLen(e.Counterparty, 32),
+			Memo:     maxLen(memo, 255),
+		})
+	}
+
+	rs.LedgerBal = ofxBalance{
+		BalAmt: signedDecimal(st.ClosingBalance),
+		DTAsOf: ofxTime(st.To),
+	}
+
+	if _, err := io.WriteString(w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>`+"\n"+ofxHeader); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ofxTime formats a timestamp as OFX datetime with an explicit UTC offset
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:UTC]"
+}
+
+// signedDecimal renders a minor-unit amount as -1234.56
+func signedDecimal(cents int64) string {
+	if cents < 0 {
+		return "-" + decimalAmount(-cents)
+	}
+	return decimalAmount(cents)
+}
@@ -0,0 +1,318 @@
+package billing
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression algorithms for generated reports
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// SignatureExt is appended to a report's filename for its detached signature
+const SignatureExt = ".sig"
+
+var (
+	ErrUnsupportedCompression = errors.New("unsupported compression")
+	ErrInvalidSignature       = errors.New("signature does not match")
+)
+
+// OutputOptions post-processes a rendered report on its way to the store: the
+// statement is compressed, then encrypted to the age recipients, and the
+// bytes as written are signed. The zero value writes plain output.
+type OutputOptions struct {
+	Compression string
+	Recipients  []age.Recipient
+	SigningKey  ed25519.PrivateKey
+}
+
+// Suffix is appended to a report's filename, e.g. ".csv.gz.age"
+func (o OutputOptions) Suffix() string {
+	var suffix string
+	switch o.Compression {
+	case CompressionGzip:
+		suffix = ".gz"
+	case CompressionZstd:
+		suffix = ".zst"
+	}
+	if len(o.Recipients) > 0 {
+		suffix += ".age"
+	}
+	return suffix
+}
+
+// Validate reports an unknown compression algorithm
+func (o OutputOptions) Validate() error {
+	switch o.Compression {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedCompression, o.Compression)
+	}
+}
+
+// outputWriter is the plaintext end of an output pipeline
+type outputWriter struct {
+	io.Writer
+	closers []io.Closer // innermost first
+	digest  hash.Hash   // SHA-512 of the bytes as stored, when signing
+	key     ed25519.PrivateKey
+}
+
+// wrap builds the pipeline in front of w. Compression runs before
+// encryption, since ciphertext does not compress.
+func (o OutputOptions) wrap(w io.Writer) (*outputWriter, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	out := &outputWriter{key: o.SigningKey}
+
+	if o.SigningKey != nil {
+		out.digest = sha512.New()
+		w = io.MultiWriter(w, out.digest)
+	}
+
+	if len(o.Recipients) > 0 {
+		enc, err := age.Encrypt(w, o.Recipients...)
+		if err != nil {
+			return nil, err
+		}
+		out.closers = append(out.closers, enc)
+		w = enc
+	}
+
+	switch o.Compression {
+	case CompressionGzip:
+		gz := gzip.NewWriter(w)
+		out.closers = append([]io.Closer{gz}, out.closers...)
+		w = gz
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		out.closers = append([]io.Closer{zw}, out.closers...)
+		w = zw
+	}
+
+	out.Writer = w
+	return out, nil
+}
+
+// Close flushes the compressor and writes the final encryption chunk
+func (o *outputWriter) Close() error {
+	for _, c := range o.closers {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Signature returns the detached signature of everything written, or nil
+// when not signing. Call it after Close.
+func (o *outputWriter) Signature() []byte {
+	if o.key == nil {
+		return nil
+	}
+	return encodeSignature(o.key, o.digest.Sum(nil))
+}
+
+// A signature file holds an Ed25519ph (RFC 8032, SHA-512 prehash)
+// signature over the report file exactly as written:
+//
+//	algorithm: ed25519ph
+//	public-key: <base64 signer public key>
+//	signature: <base64 signature>
+//
+// The public key only identifies the signer; verification must use a
+// key the recipient already trusts.
+func encodeSignature(key ed25519.PrivateKey, digest []byte) []byte {
+	sig, err := key.Sign(nil, digest, &ed25519.Options{Hash: crypto.SHA512})
+	if err != nil {
+		// Only fails for a malformed digest length, which sha512 rules out
+		panic(err)
+	}
+
+	pub := key.Public().(ed25519.PublicKey)
+
+	return []byte("algorithm: ed25519ph\n" +
+		"public-key: " + base64.StdEncoding.EncodeToString(pub) + "\n" +
+		"signature: " + base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+// SignDetached returns the detached signature file for the contents of r,
+// for files written outside the report pipeline
+func SignDetached(r io.Reader, key ed25519.PrivateKey) ([]byte, error) {
+	digest := sha512.New()
+	if _, err := io.Copy(digest, r); err != nil {
+		return nil, err
+	}
+	return encodeSignature(key, digest.Sum(nil)), nil
+}
+
+// VerifySignature checks a detached signature file against the report in r
+func VerifySignature(r io.Reader, signature []byte, pub ed25519.PublicKey) error {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(signature)), "\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("malformed signature line %q", line)
+		}
+		fields[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	if fields["algorithm"] != "ed25519ph" {
+		return fmt.Errorf("unsupported signature algorithm %q", fields["algorithm"])
+	}
+	sig, err := base64.StdEncoding.DecodeString(fields["signature"])
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+
+	digest := sha512.New()
+	if _, err := io.Copy(digest, bufio.NewReader(r)); err != nil {
+		return err
+	}
+
+	if err := ed25519.VerifyWithOptions(pub, digest.Sum(nil), sig, &ed25519.Options{Hash: crypto.SHA512}); err != nil {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// GenerateSigningKey writes a new Ed25519 key pair as PEM: the PKCS #8
+// private key to privPath (mode 0600) and the PKIX public key to pubPath
+func GenerateSigningKey(privPath, pubPath string) error {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return err
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return err
+	}
+
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+	if err := os.WriteFile(privPath, privPEM, 0o600); err != nil {
+		return err
+	}
+
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	return os.WriteFile(pubPath, pubPEM, 0o644)
+}
+
+// LoadSigningKey reads a PEM PKCS #8 Ed25519 private key
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 key", path)
+	}
+
+	return priv, nil
+}
+
+// LoadVerifyKey reads a PEM PKIX Ed25519 public key
+func LoadVerifyKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 key", path)
+	}
+
+	return pub, nil
+}
+
+func readPEM(path, blockType string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s: no %s PEM block", path, blockType)
+	}
+
+	return block, nil
+}
+
+// ParseRecipients parses age X25519 recipients ("age1...") given directly
+// or, prefixed with @, as a recipients file with one per line
+func ParseRecipients(values []string) ([]age.Recipient, error) {
+	var recipients []age.Recipient
+
+	for _, v := range values {
+		if path, ok := strings.CutPrefix(v, "@"); ok {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			rs, err := age.ParseRecipients(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			recipients = append(recipients, rs...)
+			continue
+		}
+
+		r, err := age.ParseX25519Recipient(v)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, r)
+	}
+
+	return recipients, nil
+}
+
+// writeSignature stores the detached signature for name
+func writeSignature(ctx context.Context, store ReportStore, name string, sig []byte) error {
+	return writeObject(ctx, store, name+SignatureExt, sig)
+}
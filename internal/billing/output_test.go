@@ -0,0 +1,237 @@
+package billing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/klauspost/compress/zstd"
+)
+
+// testReport is long and repetitive enough to compress
+var testReport = []byte(strings.Repeat("2026-03-02,ACC1001,ACC1002,12.50,completed\n", 200))
+
+// writeOutput runs plaintext through the pipeline and returns the bytes as
+// stored and the detached signature
+func writeOutput(t *testing.T, opts OutputOptions, plaintext []byte) ([]byte, []byte) {
+	t.Helper()
+
+	var stored bytes.Buffer
+	out, err := opts.wrap(&stored)
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+	if _, err := out.Write(plaintext); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	return stored.Bytes(), out.Signature()
+}
+
+// readOutput undoes the pipeline the way a recipient would: decrypt, then
+// decompress
+func readOutput(stored []byte, compression string, identity age.Identity) ([]byte, error) {
+	var r io.Reader = bytes.NewReader(stored)
+
+	if identity != nil {
+		dec, err := age.Decrypt(r, identity)
+		if err != nil {
+			return nil, err
+		}
+		r = dec
+	}
+
+	switch compression {
+	case CompressionGzip:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		r = gz
+	case CompressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	return io.ReadAll(r)
+}
+
+func TestOutputRoundTrip(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		compression string
+		encrypt     bool
+		wantSuffix  string
+	}{
+		{CompressionNone, false, ""},
+		{CompressionGzip, false, ".gz"},
+		{CompressionZstd, false, ".zst"},
+		{CompressionNone, true, ".age"},
+		{CompressionGzip, true, ".gz.age"},
+		{CompressionZstd, true, ".zst.age"},
+	}
+
+	for _, tt := range tests {
+		t.Run("compression="+tt.compression+",suffix="+tt.wantSuffix, func(t *testing.T) {
+			opts := OutputOptions{Compression: tt.compression, SigningKey: priv}
+			var id age.Identity
+			if tt.encrypt {
+				opts.Recipients = []age.Recipient{identity.Recipient()}
+				id = identity
+			}
+
+			if got := opts.Suffix(); got != tt.wantSuffix {
+				t.Errorf("Suffix = %q, want %q", got, tt.wantSuffix)
+			}
+
+			stored, sig := writeOutput(t, opts, testReport)
+
+			if tt.compression != CompressionNone && !tt.encrypt && len(stored) >= len(testReport) {
+				t.Errorf("compressed to %d bytes from %d", len(stored), len(testReport))
+			}
+			if tt.encrypt && bytes.Contains(stored, []byte("ACC1001")) {
+				t.Error("encrypted output holds plaintext")
+			}
+
+			got, err := readOutput(stored, tt.compression, id)
+			if err != nil {
+				t.Fatalf("read back: %v", err)
+			}
+			if !bytes.Equal(got, testReport) {
+				t.Fatal("round trip changed the report")
+			}
+
+			// The signature covers the file as stored, so it can be checked
+			// without the decryption key
+			if err := VerifySignature(bytes.NewReader(stored), sig, pub); err != nil {
+				t.Fatalf("VerifySignature: %v", err)
+			}
+
+			detached, err := SignDetached(bytes.NewReader(stored), priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(detached, sig) {
+				t.Error("SignDetached differs from the pipeline signature")
+			}
+
+			tampered := bytes.Clone(stored)
+			tampered[len(tampered)/2] ^= 0x01
+			if err := VerifySignature(bytes.NewReader(tampered), sig, pub); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("tampered file: err = %v, want ErrInvalidSignature", err)
+			}
+			if _, err := readOutput(tampered, tt.compression, id); err == nil && tt.compression != CompressionNone {
+				t.Error("tampered file read back without error")
+			}
+		})
+	}
+}
+
+func TestOutputWrongKeys(t *testing.T) {
+	identity, _ := age.GenerateX25519Identity()
+	other, _ := age.GenerateX25519Identity()
+	_, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+
+	opts := OutputOptions{
+		Compression: CompressionGzip,
+		Recipients:  []age.Recipient{identity.Recipient()},
+		SigningKey:  priv,
+	}
+	stored, sig := writeOutput(t, opts, testReport)
+
+	if _, err := readOutput(stored, opts.Compression, other); err == nil {
+		t.Error("decrypted with an identity that is not a recipient")
+	}
+
+	if err := VerifySignature(bytes.NewReader(stored), sig, otherPub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("other key: err = %v, want ErrInvalidSignature", err)
+	}
+
+	// The public key in the file is informational; a signature made by
+	// someone else does not verify against the trusted key
+	_, forger, _ := ed25519.GenerateKey(nil)
+	forged, _ := SignDetached(bytes.NewReader(stored), forger)
+	pub := priv.Public().(ed25519.PublicKey)
+	if err := VerifySignature(bytes.NewReader(stored), forged, pub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("forged signature: err = %v, want ErrInvalidSignature", err)
+	}
+
+	for name, bad := range map[string][]byte{
+		"algorithm": bytes.Replace(sig, []byte("ed25519ph"), []byte("ed25519"), 1),
+		"encoding":  append(bytes.Clone(sig), "signature: !!!\n"...),
+		"line":      []byte("not a signature"),
+	} {
+		if err := VerifySignature(bytes.NewReader(stored), bad, pub); err == nil {
+			t.Errorf("malformed %s accepted", name)
+		}
+	}
+}
+
+func TestOutputUnsigned(t *testing.T) {
+	stored, sig := writeOutput(t, OutputOptions{}, testReport)
+	if !bytes.Equal(stored, testReport) {
+		t.Error("zero options changed the report")
+	}
+	if sig != nil {
+		t.Errorf("unsigned output has signature %q", sig)
+	}
+
+	if _, err := (OutputOptions{Compression: "brotli"}).wrap(io.Discard); !errors.Is(err, ErrUnsupportedCompression) {
+		t.Errorf("brotli: err = %v, want ErrUnsupportedCompression", err)
+	}
+}
+
+func TestSigningKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	privPath, pubPath := filepath.Join(dir, "signing.pem"), filepath.Join(dir, "signing.pub.pem")
+
+	if err := GenerateSigningKey(privPath, pubPath); err != nil {
+		t.Fatalf("GenerateSigningKey: %v", err)
+	}
+	priv, err := LoadSigningKey(privPath)
+	if err != nil {
+		t.Fatalf("LoadSigningKey: %v", err)
+	}
+	pub, err := LoadVerifyKey(pubPath)
+	if err != nil {
+		t.Fatalf("LoadVerifyKey: %v", err)
+	}
+
+	sig, err := SignDetached(bytes.NewReader(testReport), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignature(bytes.NewReader(testReport), sig, pub); err != nil {
+		t.Errorf("VerifySignature with loaded keys: %v", err)
+	}
+
+	// Swapped paths are rejected rather than misread
+	if _, err := LoadSigningKey(pubPath); err == nil {
+		t.Error("LoadSigningKey accepted a public key")
+	}
+	if _, err := LoadVerifyKey(privPath); err == nil {
+		t.Error("LoadVerifyKey accepted a private key")
+	}
+}
@@ -0,0 +1,214 @@
+package billing
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Minimal PDF 1.4 writer: A4 pages, the four standard Type1 fonts below
+// (no embedding needed), text, rules and filled boxes. Enough for tabular
+// statements without pulling in a dependency or an external binary.
+
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+)
+
+const (
+	fontRegular   = "F1" // Helvetica
+	fontBold      = "F2" // Helvetica-Bold
+	fontMono      = "F3" // Courier
+	fontMonoBold  = "F4" // Courier-Bold
+	monoCharWidth = 0.6  // Courier advance width, as a fraction of the font size
+)
+
+var pdfFonts = []struct{ key, base string }{
+	{fontRegular, "Helvetica"},
+	{fontBold, "Helvetica-Bold"},
+	{fontMono, "Courier"},
+	{fontMonoBold, "Courier-Bold"},
+}
+
+type pdfDocument struct {
+	title   string
+	created time.Time
+	pages   []*pdfPage
+}
+
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+func newPDFDocument(title string, created time.Time) *pdfDocument {
+	return &pdfDocument{title: title, created: created}
+}
+
+func (d *pdfDocument) newPage() *pdfPage {
+	p := &pdfPage{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// text draws s with its baseline starting at (x, y), origin bottom-left
+func (p *pdfPage) text(font string, size, x, y float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		font, num(size), num(x), num(y), pdfString(s))
+}
+
+// textRight draws monospaced s so that it ends at x
+func (p *pdfPage) textRight(font string, size, x, y float64, s string) {
+	width := float64(len([]rune(s))) * size * monoCharWidth
+	p.text(font, size, x-width, y, s)
+}
+
+// line strokes a line of the given width from (x1, y1) to (x2, y2)
+func (p *pdfPage) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n",
+		num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// fillRect fills a rectangle in the given gray level (0 black, 1 white)
+func (p *pdfPage) fillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(&p.content, "%s g %s %s %s %s re f 0 g\n",
+		num(gray), num(x), num(y), num(w), num(h))
+}
+
+// WriteTo serialises the document with a cross-reference table
+func (d *pdfDocument) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	// Object numbers: 1 catalog, 2 page tree, 3 info, fonts, then a
+	// page object and a content stream per page
+	const (
+		catalogObj = 1
+		pagesObj   = 2
+		infoObj    = 3
+		firstFont  = 4
+	)
+	firstPage := firstFont + len(pdfFonts)
+	objCount := firstPage + 2*len(d.pages) - 1
+
+	offsets := make([]int64, objCount+1)
+	begin := func(n int) {
+		offsets[n] = cw.n
+		fmt.Fprintf(cw, "%d 0 obj\n", n)
+	}
+	end := func() {
+		fmt.Fprint(cw, "endobj\n")
+	}
+
+	fmt.Fprint(cw, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	begin(catalogObj)
+	fmt.Fprintf(cw, "<< /Type /Catalog /Pages %d 0 R >>\n", pagesObj)
+	end()
+
+	begin(pagesObj)
+	fmt.Fprint(cw, "<< /Type /Pages /Kids [")
+	for i := range d.pages {
+		fmt.Fprintf(cw, " %d 0 R", firstPage+2*i)
+	}
+	fmt.Fprintf(cw, " ] /Count %d /MediaBox [0 0 %s %s] >>\n",
+		len(d.pages), num(pdfPageWidth), num(pdfPageHeight))
+	end()
+
+	begin(infoObj)
+	fmt.Fprintf(cw, "<< /Title (%s) /Producer (GopherPay) /CreationDate (D:%s) >>\n",
+		pdfString(d.title), d.created.UTC().Format("20060102150405Z"))
+	end()
+
+	var resources bytes.Buffer
+	resources.WriteString("<< /Font <<")
+	for i, f := range pdfFonts {
+		begin(firstFont + i)
+		fmt.Fprintf(cw, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\n", f.base)
+		end()
+		fmt.Fprintf(&resources, " /%s %d 0 R", f.key, firstFont+i)
+	}
+	resources.WriteString(" >> >>")
+
+	for i, p := range d.pages {
+		pageObj := firstPage + 2*i
+		contentObj := pageObj + 1
+
+		begin(pageObj)
+		fmt.Fprintf(cw, "<< /Type /Page /Parent %d 0 R /Resources %s /Contents %d 0 R >>\n",
+			pagesObj, resources.String(), contentObj)
+		end()
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write(p.content.Bytes()); err != nil {
+			return cw.n, err
+		}
+		if err := zw.Close(); err != nil {
+			return cw.n, err
+		}
+
+		begin(contentObj)
+		fmt.Fprintf(cw, "<< /Length %d /Filter /FlateDecode >>\nstream\n", stream.Len())
+		cw.Write(stream.Bytes())
+		fmt.Fprint(cw, "\nendstream\n")
+		end()
+	}
+
+	xref := cw.n
+	fmt.Fprintf(cw, "xref\n0 %d\n0000000000 65535 f \n", objCount+1)
+	for n := 1; n <= objCount; n++ {
+		fmt.Fprintf(cw, "%010d 00000 n \n", offsets[n])
+	}
+	fmt.Fprintf(cw, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		objCount+1, catalogObj, infoObj, xref)
+
+	return cw.n, cw.err
+}
+
+// pdfString escapes s for a literal string in WinAnsiEncoding. Runes
+// outside Latin-1 have no glyph in the standard fonts and become '?'.
+func pdfString(s string) string {
+	var b bytes.Buffer
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// num formats a coordinate with at most two decimals
+func num(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 2, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// countingWriter tracks the byte offset needed for the xref table and
+// keeps the first write error
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
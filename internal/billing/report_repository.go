@@ -3,11 +3,24 @@ package billing
 import (
 	"context"
 	"database/sql"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ReportRepository interface {
 	GetTransactionsByAccount(ctx context.Context, accountNumber string) (*sql.Rows, error)
-	GetAllTransactions(ctx context.Context) (*sql.Rows, error)
+	ListTransactions(ctx context.Context, filter TransactionFilter) (*sql.Rows, error)
+	ListAccountNumbers(ctx context.Context, pattern string) ([]string, error)
+
+	// Statement queries run inside one read-only snapshot so the opening
+	// balance and the rows that follow it agree
+	BeginSnapshot(ctx context.Context) (*sql.Tx, error)
+	GetOpeningBalance(ctx context.Context, tx *sql.Tx, accountNumber string, from time.Time) (int64, error)
+	GetCompletedTransactionsByAccount(ctx context.Context, tx *sql.Tx, accountNumber string, from, to time.Time) (*sql.Rows, error)
+
+	// GetSummary aggregates transfers for the finance summary report
+	GetSummary(ctx context.Context, filter SummaryFilter) (*Summary, error)
 }
 
 type PostgresReportRepository struct {
@@ -46,7 +59,8 @@ func (r *PostgresReportRepository) GetTransactionsByAccount(
 			t.amount,
 			t.status,
 			t.request_id,
-			t.created_at
+			t.created_at,
+			t.kind
 		FROM transactions t
 		JOIN accounts f ON t.from_account_id = f.id
 		JOIN accounts ta ON t.to_account_id = ta.id
@@ -58,7 +72,109 @@ func (r *PostgresReportRepository) GetTransactionsByAccount(
 	return r.db.QueryContext(ctx, query, accountNumber)
 }
 
-func (r *PostgresReportRepository) GetAllTransactions(ctx context.Context) (*sql.Rows, error) {
+// ListAccountNumbers returns every account number matching pattern, where
+// * matches any run of characters and ? a single one. An empty pattern
+// matches all accounts.
+func (r *PostgresReportRepository) ListAccountNumbers(
+	ctx context.Context,
+	pattern string,
+) ([]string, error) {
+
+	query := `
+		SELECT account_number
+		FROM accounts
+		WHERE $1 = '' OR account_number LIKE $1 ESCAPE '\'
+		ORDER BY account_number ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, globToLike(pattern))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []string
+	for rows.Next() {
+		var acct string
+		if err := rows.Scan(&acct); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, acct)
+	}
+
+	return accounts, rows.Err()
+}
+
+// globToLike converts a shell-style pattern to a LIKE pattern, escaping
+// LIKE's own wildcards
+func globToLike(pattern string) string {
+	var b strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteByte('%')
+		case '?':
+			b.WriteByte('_')
+		case '%', '_', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// BeginSnapshot starts a read-only REPEATABLE READ transaction
+func (r *PostgresReportRepository) BeginSnapshot(ctx context.Context) (*sql.Tx, error) {
+	return r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+}
+
+// GetOpeningBalance derives the account balance at from by backing the
+// completed transactions since then out of the live balance. A zero from
+// means "before the first transaction".
+// Returns sql.ErrNoRows when the account does not exist.
+func (r *PostgresReportRepository) GetOpeningBalance(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountNumber string,
+	from time.Time,
+) (int64, error) {
+
+	query := `
+		SELECT
+			a.balance - COALESCE(SUM(
+				CASE WHEN t.to_account_id = a.id THEN t.amount ELSE -t.amount END
+			) FILTER (WHERE $2::timestamp IS NULL OR t.created_at >= $2), 0) AS opening
+		FROM accounts a
+		LEFT JOIN transactions t
+			ON (t.from_account_id = a.id OR t.to_account_id = a.id)
+		   AND t.status = 'completed'
+		   AND t.from_account_id <> t.to_account_id
+		WHERE a.account_number = $1
+		GROUP BY a.id, a.balance
+	`
+
+	var opening int64
+	err := tx.QueryRowContext(ctx, query, accountNumber, nullTime(from)).Scan(&opening)
+
+	return opening, err
+}
+
+// GetCompletedTransactionsByAccount returns the account's completed
+// transactions with from <= created_at < to; a zero bound is open-ended.
+// Rows are read from the connection as the caller iterates, so large
+// ranges are not buffered.
+func (r *PostgresReportRepository) GetCompletedTransactionsByAccount(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountNumber string,
+	from time.Time,
+	to time.Time,
+) (*sql.Rows, error) {
 
 	query := `
 		SELECT 
@@ -68,12 +184,280 @@ func (r *PostgresReportRepository) GetAllTransactions(ctx context.Context) (*sql
 			t.amount,
 			t.status,
 			t.request_id,
-			t.created_at
+			t.created_at,
+			t.kind
 		FROM transactions t
 		JOIN accounts f ON t.from_account_id = f.id
 		JOIN accounts ta ON t.to_account_id = ta.id
-		ORDER BY t.created_at ASC
+		WHERE (f.account_number = $1 OR ta.account_number = $1)
+		  AND t.status = 'completed'
+		  AND t.from_account_id <> t.to_account_id
+		  AND ($2::timestamp IS NULL OR t.created_at >= $2)
+		  AND ($3::timestamp IS NULL OR t.created_at < $3)
+		ORDER BY t.created_at ASC, t.id ASC
 	`
 
-	return r.db.QueryContext(ctx, query)
+	return tx.QueryContext(ctx, query, accountNumber, nullTime(from), nullTime(to))
+}
+
+// nullTime maps the zero time to SQL NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// ListTransactions returns at most filter.Limit+1 rows after filter.After,
+// ordered by (created_at, id) so pages are stable under concurrent inserts.
+// The extra row tells the caller whether another page exists.
+func (r *PostgresReportRepository) ListTransactions(
+	ctx context.Context,
+	filter TransactionFilter,
+) (*sql.Rows, error) {
+
+	var (
+		where []string
+		args  []any
+	)
+
+	// arg binds a value and returns its placeholder
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.AccountNumber != "" {
+		acct := arg(filter.AccountNumber)
+
+		switch filter.Direction {
+		case DirectionSent:
+			where = append(where, "f.account_number = "+acct)
+		case DirectionReceived:
+			where = append(where, "ta.account_number = "+acct)
+		default:
+			where = append(where, "(f.account_number = "+acct+" OR ta.account_number = "+acct+")")
+		}
+
+		if filter.Counterparty != "" {
+			where = append(where,
+				"CASE WHEN f.account_number = "+acct+
+					" THEN ta.account_number ELSE f.account_number END = "+arg(filter.Counterparty))
+		}
+	} else if filter.Counterparty != "" {
+		cp := arg(filter.Counterparty)
+		where = append(where, "(f.account_number = "+cp+" OR ta.account_number = "+cp+")")
+	}
+
+	if filter.Status != "" {
+		where = append(where, "t.status = "+arg(filter.Status))
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "t.created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "t.created_at < "+arg(filter.To))
+	}
+	if filter.MinAmount > 0 {
+		where = append(where, "t.amount >= "+arg(filter.MinAmount))
+	}
+	if filter.MaxAmount > 0 {
+		where = append(where, "t.amount <= "+arg(filter.MaxAmount))
+	}
+	if filter.After != nil {
+		where = append(where,
+			"(t.created_at, t.id) > ("+arg(filter.After.CreatedAt)+", "+arg(filter.After.ID)+")")
+	}
+
+	query := `
+		SELECT 
+			t.id,
+			f.account_number AS from_account,
+			ta.account_number AS to_account,
+			t.amount,
+			t.status,
+			t.request_id,
+			t.created_at,
+			t.kind
+		FROM transactions t
+		JOIN accounts f ON t.from_account_id = f.id
+		JOIN accounts ta ON t.to_account_id = ta.id
+	`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY t.created_at ASC, t.id ASC LIMIT " + arg(filter.Limit+1)
+
+	return r.db.QueryContext(ctx, query, args...)
+}
+
+// GetSummary computes the summary figures with SQL aggregation. The
+// queries share one snapshot so the sections add up.
+func (r *PostgresReportRepository) GetSummary(
+	ctx context.Context,
+	filter SummaryFilter,
+) (*Summary, error) {
+
+	tx, err := r.BeginSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	from, to := nullTime(filter.From), nullTime(filter.To)
+	summary := &Summary{
+		Daily:             []VolumeStats{},
+		Monthly:           []VolumeStats{},
+		FailureReasons:    []FailureStats{},
+		TopCounterparties: []CounterpartyStat{},
+	}
+
+	// Daily, monthly and overall figures in one pass; GROUPING tells the
+	// sets apart (1 = daily, 2 = monthly, 3 = total). Fee rows only add to
+	// fee revenue, never to transfer counts or volume.
+	volumeQuery := `
+		SELECT
+			GROUPING(t.day, t.month) AS grouping_set,
+			COALESCE(to_char(t.day, 'YYYY-MM-DD'), to_char(t.month, 'YYYY-MM'), '') AS period,
+			COUNT(*) FILTER (WHERE t.kind = 'transfer') AS transfers,
+			COUNT(*) FILTER (WHERE t.kind = 'transfer' AND t.status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE t.kind = 'transfer' AND t.status = 'failed') AS failed,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'transfer' AND t.status = 'completed'), 0) AS volume,
+			COALESCE(ROUND(AVG(t.amount) FILTER (WHERE t.kind = 'transfer' AND t.status = 'completed')), 0)::BIGINT AS average_ticket,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.kind = 'fee' AND t.status = 'completed'), 0) AS fees
+		FROM (
+			SELECT
+				date_trunc('day', created_at) AS day,
+				date_trunc('month', created_at) AS month,
+				kind,
+				status,
+				amount
+			FROM transactions
+			WHERE ($1::timestamp IS NULL OR created_at >= $1)
+			  AND ($2::timestamp IS NULL OR created_at < $2)
+		) t
+		GROUP BY GROUPING SETS ((t.day), (t.month), ())
+		ORDER BY grouping_set, period
+	`
+
+	rows, err := tx.QueryContext(ctx, volumeQuery, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			grouping int
+			v        VolumeStats
+		)
+		if err := rows.Scan(&grouping, &v.Period, &v.Transfers, &v.Completed, &v.Failed, &v.Volume, &v.AverageTicket, &v.Fees); err != nil {
+			return nil, err
+		}
+		if v.Transfers > 0 {
+			v.FailureRate = float64(v.Failed) / float64(v.Transfers)
+		}
+
+		switch grouping {
+		case 1:
+			summary.Daily = append(summary.Daily, v)
+		case 2:
+			summary.Monthly = append(summary.Monthly, v)
+		default:
+			summary.Totals = v
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	failureQuery := `
+		SELECT COALESCE(failure_reason, 'unknown') AS reason, COUNT(*) AS failed
+		FROM transactions
+		WHERE status = 'failed'
+		  AND kind = 'transfer'
+		  AND ($1::timestamp IS NULL OR created_at >= $1)
+		  AND ($2::timestamp IS NULL OR created_at < $2)
+		GROUP BY reason
+		ORDER BY failed DESC, reason ASC
+	`
+
+	rows, err = tx.QueryContext(ctx, failureQuery, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f FailureStats
+		if err := rows.Scan(&f.Reason, &f.Failed); err != nil {
+			return nil, err
+		}
+		if summary.Totals.Transfers > 0 {
+			f.Rate = float64(f.Failed) / float64(summary.Totals.Transfers)
+		}
+		summary.FailureReasons = append(summary.FailureReasons, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// Each completed transfer counts for both of its accounts; fees do not
+	counterpartyQuery := `
+		WITH legs AS (
+			SELECT t.from_account_id AS account_id, t.to_account_id AS counterparty_id, t.amount
+			FROM transactions t
+			WHERE t.status = 'completed'
+			  AND t.kind = 'transfer'
+			  AND t.from_account_id <> t.to_account_id
+			  AND ($1::timestamp IS NULL OR t.created_at >= $1)
+			  AND ($2::timestamp IS NULL OR t.created_at < $2)
+			UNION ALL
+			SELECT t.to_account_id, t.from_account_id, t.amount
+			FROM transactions t
+			WHERE t.status = 'completed'
+			  AND t.kind = 'transfer'
+			  AND t.from_account_id <> t.to_account_id
+			  AND ($1::timestamp IS NULL OR t.created_at >= $1)
+			  AND ($2::timestamp IS NULL OR t.created_at < $2)
+		),
+		ranked AS (
+			SELECT
+				a.account_number,
+				c.account_number AS counterparty,
+				COUNT(*) AS transfers,
+				SUM(l.amount) AS volume,
+				ROW_NUMBER() OVER (
+					PARTITION BY a.account_number
+					ORDER BY SUM(l.amount) DESC, COUNT(*) DESC, c.account_number ASC
+				) AS rank
+			FROM legs l
+			JOIN accounts a ON a.id = l.account_id
+			JOIN accounts c ON c.id = l.counterparty_id
+			WHERE $3 = '' OR a.account_number LIKE $3 ESCAPE '\'
+			GROUP BY a.account_number, c.account_number
+		)
+		SELECT account_number, rank, counterparty, transfers, volume
+		FROM ranked
+		WHERE rank <= $4
+		ORDER BY account_number ASC, rank ASC
+	`
+
+	rows, err = tx.QueryContext(ctx, counterpartyQuery, from, to, globToLike(filter.Accounts), filter.Top)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c CounterpartyStat
+		if err := rows.Scan(&c.AccountNumber, &c.Rank, &c.Counterparty, &c.Transfers, &c.Volume); err != nil {
+			return nil, err
+		}
+		summary.TopCounterparties = append(summary.TopCounterparties, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
 }
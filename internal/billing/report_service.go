@@ -1,90 +1,157 @@
 package billing
 
 import (
-	"bufio"
 	"context"
 	"database/sql"
-	"encoding/csv"
-	"os"
-	"strconv"
+	"errors"
+	"io"
+	"time"
+
+	"gopherpay/internal/pii"
+	"gopherpay/internal/wallet"
 )
 
+// AccountLookup resolves the account holder details printed on statements.
+// wallet.PostgresRepository and wallet.WalletService both satisfy it.
+type AccountLookup interface {
+	GetAccountByNumber(ctx context.Context, accountNumber string) (*wallet.Account, error)
+}
+
 type ReportService struct {
-	repo ReportRepository
+	repo     ReportRepository
+	accounts AccountLookup
+	currency string // ISO 4217 code for bank-standard formats
 }
 
-func NewReportService(repo ReportRepository) *ReportService {
-	return &ReportService{repo: repo}
+func NewReportService(repo ReportRepository, accounts AccountLookup, currency string) *ReportService {
+	return &ReportService{repo: repo, accounts: accounts, currency: currency}
 }
 
+// GenerateReport writes the account statement for [from, to) to name in
+// store, in the given format, compressed, encrypted and signed as set in
+// output. The signature goes to name+SignatureExt. Zero bounds cover the
+// whole history up to now.
 func (s *ReportService) GenerateReport(
 	ctx context.Context,
 	accountNumber string,
-	filename string,
+	from time.Time,
+	to time.Time,
+	format string,
+	output OutputOptions,
+	store ReportStore,
+	name string,
 ) error {
 
-	rows, err := s.repo.GetTransactionsByAccount(ctx, accountNumber)
-	if err != nil {
+	if err := output.Validate(); err != nil {
 		return err
 	}
-	defer rows.Close()
 
-	file, err := os.Create(filename)
+	obj, err := store.Create(ctx, name)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	defer obj.Abort()
 
-	writer := csv.NewWriter(bufio.NewWriter(file))
+	w, err := output.wrap(obj)
+	if err != nil {
+		return err
+	}
 
-	// Write header
-	writer.Write([]string{
-		"id",
-		"from_account",
-		"to_account",
-		"amount",
-		"status",
-		"request_id",
-		"created_at",
-	})
+	if _, err := s.renderStatement(ctx, accountNumber, from, to, format, false, w); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := obj.Commit(); err != nil {
+		return err
+	}
 
-	for rows.Next() {
-		var (
-			id        int64
-			fromAcc   string
-			toAcc     string
-			amount    int64
-			status    string
-			requestID string
-			createdAt string
-		)
-
-		if err := rows.Scan(
-			&id,
-			&fromAcc,
-			&toAcc,
-			&amount,
-			&status,
-			&requestID,
-			&createdAt,
-		); err != nil {
+	if sig := w.Signature(); sig != nil {
+		if err := writeSignature(ctx, store, name, sig); err != nil {
+			// An unsigned report must not pass for a signed one
+			store.Delete(ctx, name)
 			return err
 		}
-
-		writer.Write([]string{
-			strconv.FormatInt(id, 10),
-			fromAcc,
-			toAcc,
-			strconv.FormatInt(amount, 10),
-			status,
-			requestID,
-			createdAt,
-		})
 	}
 
-	writer.Flush()
+	return nil
+}
+
+// RenderStatement writes the account statement for [from, to) to w in any
+// supported format. Line-oriented formats are streamed; document formats
+// are built in memory first. The holder's email and phone are masked
+// unless unmaskPII is set; stored reports are always masked.
+func (s *ReportService) RenderStatement(
+	ctx context.Context,
+	accountNumber string,
+	from time.Time,
+	to time.Time,
+	format string,
+	unmaskPII bool,
+	w io.Writer,
+) error {
 
-	return writer.Error()
+	_, err := s.renderStatement(ctx, accountNumber, from, to, format, unmaskPII, w)
+	return err
+}
+
+// renderStatement is RenderStatement, also returning the statement totals
+func (s *ReportService) renderStatement(
+	ctx context.Context,
+	accountNumber string,
+	from time.Time,
+	to time.Time,
+	format string,
+	unmaskPII bool,
+	w io.Writer,
+) (*Statement, error) {
+
+	switch format {
+	case FormatCSV, FormatNDJSON:
+		return s.writeStatement(ctx, accountNumber, from, to, format, w)
+
+	case FormatPDF, FormatCamt053, FormatMT940, FormatOFX:
+		holder, err := s.accounts.GetAccountByNumber(ctx, accountNumber)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAccountNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !unmaskPII {
+			holder = maskHolder(holder)
+		}
+
+		st, err := s.BuildStatement(ctx, accountNumber, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		switch format {
+		case FormatCamt053:
+			err = WriteCamt053(w, holder, st, s.currency)
+		case FormatMT940:
+			err = WriteMT940(w, st, s.currency)
+		case FormatOFX:
+			err = WriteOFX(w, st, s.currency)
+		default:
+			err = RenderStatementPDF(w, holder, st)
+		}
+		return st, err
+
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// maskHolder returns a copy of holder with the contact details masked as
+// in API responses
+func maskHolder(holder *wallet.Account) *wallet.Account {
+	masked := *holder
+	masked.Email = pii.MaskEmail(holder.Email)
+	masked.Phone = pii.MaskPhone(holder.Phone)
+	return &masked
 }
 
 type TransactionView struct {
@@ -95,40 +162,50 @@ type TransactionView struct {
 	Status    string `json:"status"`
 	RequestID string `json:"request_id"`
 	CreatedAt string `json:"created_at"`
+	Kind      string `json:"kind"`
 }
 
-// FetchTransactions returns transactions for a specific account or all when accountNumber is empty
-func (s *ReportService) FetchTransactions(ctx context.Context, accountNumber string) ([]TransactionView, error) {
-	var rows *sql.Rows
-	var err error
+// TransactionPage is one page of a transaction listing. NextCursor is
+// empty on the last page.
+type TransactionPage struct {
+	Transactions []TransactionView `json:"transactions"`
+	NextCursor   string            `json:"next_cursor,omitempty"`
+}
 
-	if accountNumber == "" {
-		rows, err = s.repo.GetAllTransactions(ctx)
-	} else {
-		rows, err = s.repo.GetTransactionsByAccount(ctx, accountNumber)
+// ListTransactions returns one page of transactions matching the filter
+func (s *ReportService) ListTransactions(ctx context.Context, filter TransactionFilter) (*TransactionPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
 	}
+
+	rows, err := s.repo.ListTransactions(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var result []TransactionView
+	page := &TransactionPage{Transactions: []TransactionView{}}
+	var last Cursor
 
 	for rows.Next() {
-		var tv TransactionView
-		if err := rows.Scan(
-			&tv.ID,
-			&tv.From,
-			&tv.To,
-			&tv.Amount,
-			&tv.Status,
-			&tv.RequestID,
-			&tv.CreatedAt,
-		); err != nil {
+		// The repository returns one row beyond the limit when more exist
+		if len(page.Transactions) == filter.Limit {
+			page.NextCursor = last.Encode()
+			break
+		}
+
+		tv, createdAt, err := scanTransactionView(rows)
+		if err != nil {
 			return nil, err
 		}
-		result = append(result, tv)
+		last = Cursor{CreatedAt: createdAt, ID: tv.ID}
+
+		page.Transactions = append(page.Transactions, tv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return page, nil
 }
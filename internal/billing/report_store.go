@@ -0,0 +1,244 @@
+package billing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrReportNotFound = errors.New("report not found")
+
+// ReportStore is where generated reports are written. Names are
+// slash-separated keys relative to the store's root.
+type ReportStore interface {
+	// Create starts writing name. Nothing is visible under name until
+	// Commit, so a failed or interrupted report never looks complete.
+	Create(ctx context.Context, name string) (ReportWriter, error)
+
+	// Open returns the contents of name, or ErrReportNotFound
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	Delete(ctx context.Context, name string) error
+
+	// Location describes name for humans, e.g. a path or s3:// URL
+	Location(name string) string
+}
+
+// ReportWriter is an object being written to a ReportStore
+type ReportWriter interface {
+	io.Writer
+
+	// Commit publishes the object
+	Commit() error
+
+	// Abort discards the object; it is a no-op after Commit
+	Abort()
+}
+
+// OpenReportStore returns the store at location: an s3://bucket/prefix
+// URL, a file:// URL or a plain directory path
+func OpenReportStore(location string, s3 S3Config) (ReportStore, error) {
+	if !strings.Contains(location, "://") {
+		return NewFileStore(location), nil
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("report location %q: %w", location, err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return NewFileStore(u.Path), nil
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("report location %q: missing bucket", location)
+		}
+		return NewS3Store(s3, u.Host, strings.Trim(u.Path, "/")), nil
+	default:
+		return nil, fmt.Errorf("report location %q: unsupported scheme %q", location, u.Scheme)
+	}
+}
+
+// writeObject stores data under name in one step
+func writeObject(ctx context.Context, store ReportStore, name string, data []byte) error {
+	w, err := store.Create(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer w.Abort()
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	return w.Commit()
+}
+
+// ========================================
+// LOCAL FILESYSTEM
+// ========================================
+
+// FileStore keeps reports in a local directory
+type FileStore struct {
+	root string
+}
+
+func NewFileStore(root string) *FileStore {
+	return &FileStore{root: root}
+}
+
+// path maps name into the root; ".." cannot escape it
+func (s *FileStore) path(name string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+// Create writes to a temporary file next to the target and renames it
+// into place on Commit
+func (s *FileStore) Create(ctx context.Context, name string) (ReportWriter, error) {
+	target := s.path(name)
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileWriter{File: tmp, target: target}, nil
+}
+
+func (s *FileStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrReportNotFound
+	}
+	return f, err
+}
+
+func (s *FileStore) Delete(ctx context.Context, name string) error {
+	err := os.Remove(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrReportNotFound
+	}
+	return err
+}
+
+func (s *FileStore) Location(name string) string {
+	return s.path(name)
+}
+
+type fileWriter struct {
+	*os.File
+	target string
+	done   bool
+}
+
+func (w *fileWriter) Commit() error {
+	if err := w.File.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(w.File.Name(), w.target); err != nil {
+		return err
+	}
+	w.done = true
+	return nil
+}
+
+func (w *fileWriter) Abort() {
+	if w.done {
+		return
+	}
+	w.File.Close()
+	os.Remove(w.File.Name())
+	w.done = true
+}
+
+// ========================================
+// IN-MEMORY
+// ========================================
+
+// MemoryStore keeps reports in memory, for tests
+type MemoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{objects: make(map[string][]byte)}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, name string) (ReportWriter, error) {
+	return &memoryWriter{store: s, name: path.Clean(name)}, nil
+}
+
+func (s *MemoryStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.objects[path.Clean(name)]
+	if !ok {
+		return nil, ErrReportNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.objects[path.Clean(name)]; !ok {
+		return ErrReportNotFound
+	}
+	delete(s.objects, path.Clean(name))
+
+	return nil
+}
+
+func (s *MemoryStore) Location(name string) string {
+	return "mem://" + path.Clean(name)
+}
+
+// Names lists the stored objects in order
+func (s *MemoryStore) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.objects))
+	for name := range s.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+type memoryWriter struct {
+	bytes.Buffer
+	store *MemoryStore
+	name  string
+}
+
+func (w *memoryWriter) Commit() error {
+	w.store.mu.Lock()
+	defer w.store.mu.Unlock()
+
+	w.store.objects[w.name] = bytes.Clone(w.Bytes())
+
+	return nil
+}
+
+// Abort drops the buffer; nothing was published
+func (w *memoryWriter) Abort() {}
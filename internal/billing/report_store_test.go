@@ -0,0 +1,155 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestReportStores runs the same checks against every ReportStore
+func TestReportStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) ReportStore{
+		"file":   func(t *testing.T) ReportStore { return NewFileStore(t.TempDir()) },
+		"memory": func(t *testing.T) ReportStore { return NewMemoryStore() },
+		"s3": func(t *testing.T) ReportStore {
+			_, srv := newFakeS3(t)
+			return newTestS3Store(srv)
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			t.Run("commit publishes", func(t *testing.T) {
+				store := newStore(t)
+
+				w, err := store.Create(context.Background(), "statements/ACC1001.csv")
+				if err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				defer w.Abort()
+
+				io.WriteString(w, "first,")
+				io.WriteString(w, "second\n")
+
+				if _, err := store.Open(context.Background(), "statements/ACC1001.csv"); !errors.Is(err, ErrReportNotFound) {
+					t.Errorf("Open before Commit = %v, want ErrReportNotFound", err)
+				}
+
+				if err := w.Commit(); err != nil {
+					t.Fatalf("Commit: %v", err)
+				}
+				// Abort after Commit must not undo it
+				w.Abort()
+
+				if got := readReport(t, store, "statements/ACC1001.csv"); got != "first,second\n" {
+					t.Errorf("contents = %q", got)
+				}
+			})
+
+			t.Run("abort discards", func(t *testing.T) {
+				store := newStore(t)
+
+				w, err := store.Create(context.Background(), "aborted.csv")
+				if err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				io.WriteString(w, "partial")
+				w.Abort()
+
+				if _, err := store.Open(context.Background(), "aborted.csv"); !errors.Is(err, ErrReportNotFound) {
+					t.Errorf("Open after Abort = %v, want ErrReportNotFound", err)
+				}
+			})
+
+			t.Run("overwrite", func(t *testing.T) {
+				store := newStore(t)
+
+				for _, data := range []string{"old", "new"} {
+					if err := writeObject(context.Background(), store, "report.csv", []byte(data)); err != nil {
+						t.Fatalf("writeObject: %v", err)
+					}
+				}
+				if got := readReport(t, store, "report.csv"); got != "new" {
+					t.Errorf("contents = %q, want new", got)
+				}
+			})
+
+			t.Run("delete", func(t *testing.T) {
+				store := newStore(t)
+
+				if err := writeObject(context.Background(), store, "report.csv", []byte("x")); err != nil {
+					t.Fatalf("writeObject: %v", err)
+				}
+				if err := store.Delete(context.Background(), "report.csv"); err != nil {
+					t.Fatalf("Delete: %v", err)
+				}
+				if _, err := store.Open(context.Background(), "report.csv"); !errors.Is(err, ErrReportNotFound) {
+					t.Errorf("Open after Delete = %v, want ErrReportNotFound", err)
+				}
+			})
+
+			t.Run("missing", func(t *testing.T) {
+				store := newStore(t)
+
+				if _, err := store.Open(context.Background(), "missing.csv"); !errors.Is(err, ErrReportNotFound) {
+					t.Errorf("Open = %v, want ErrReportNotFound", err)
+				}
+			})
+		})
+	}
+}
+
+func TestFileStoreStaysInRoot(t *testing.T) {
+	root := t.TempDir()
+	store := NewFileStore(filepath.Join(root, "reports"))
+
+	if err := writeObject(context.Background(), store, "../../escape.csv", []byte("x")); err != nil {
+		t.Fatalf("writeObject: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "reports", "escape.csv")); err != nil {
+		t.Errorf("object not written inside the root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.csv")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("object escaped the root")
+	}
+}
+
+func TestFileStoreAbortLeavesNoFiles(t *testing.T) {
+	root := t.TempDir()
+	store := NewFileStore(root)
+
+	w, err := store.Create(context.Background(), "report.csv")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	io.WriteString(w, "partial")
+	w.Abort()
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		t.Errorf("left behind: %s", e.Name())
+	}
+}
+
+func readReport(t *testing.T, store ReportStore, name string) string {
+	t.Helper()
+
+	rc, err := store.Open(context.Background(), name)
+	if err != nil {
+		t.Fatalf("Open(%q): %v", name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read %q: %v", name, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,315 @@
+package billing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config holds the connection settings for S3-compatible object stores
+type S3Config struct {
+	// Endpoint defaults to AWS S3 in Region; set it for MinIO and other
+	// S3-compatible services, e.g. http://localhost:9000
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// PathStyle addresses buckets as endpoint/bucket/key instead of
+	// bucket.endpoint/key, as MinIO expects
+	PathStyle bool
+}
+
+// S3Store keeps reports in an S3 bucket under a key prefix. Requests are
+// signed with AWS Signature Version 4.
+type S3Store struct {
+	cfg    S3Config
+	bucket string
+	prefix string
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewS3Store(cfg S3Config, bucket, prefix string) *S3Store {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+
+	return &S3Store{
+		cfg:    cfg,
+		bucket: bucket,
+		prefix: prefix,
+		client: &http.Client{Timeout: 5 * time.Minute},
+		now:    time.Now,
+	}
+}
+
+func (s *S3Store) key(name string) string {
+	return strings.TrimPrefix(path.Join(s.prefix, path.Clean("/"+name)), "/")
+}
+
+func (s *S3Store) Location(name string) string {
+	return "s3://" + s.bucket + "/" + s.key(name)
+}
+
+// Create buffers the object in a temporary file, hashing it for the
+// signature, and uploads it with a single PUT on Commit
+func (s *S3Store) Create(ctx context.Context, name string) (ReportWriter, error) {
+	tmp, err := os.CreateTemp("", "gopherpay-s3-*")
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+
+	return &s3Writer{
+		Writer: io.MultiWriter(tmp, hash),
+		ctx:    ctx,
+		store:  s,
+		key:    s.key(name),
+		tmp:    tmp,
+		hash:   hash,
+	}, nil
+}
+
+func (s *S3Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, s.key(name), nil, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, s3Error(resp)
+	}
+
+	return resp.Body, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, name string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, s.key(name), nil, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+
+	return nil
+}
+
+type s3Writer struct {
+	io.Writer
+	ctx   context.Context
+	store *S3Store
+	key   string
+	tmp   *os.File
+	hash  hash.Hash
+	done  bool
+}
+
+func (w *s3Writer) Commit() error {
+	defer w.Abort()
+
+	size, err := w.tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := w.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	req, err := w.store.newRequest(w.ctx, http.MethodPut, w.key, io.NopCloser(w.tmp), hex.EncodeToString(w.hash.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+
+	resp, err := w.store.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+
+	return nil
+}
+
+func (w *s3Writer) Abort() {
+	if w.done {
+		return
+	}
+	w.tmp.Close()
+	os.Remove(w.tmp.Name())
+	w.done = true
+}
+
+// emptyPayloadHash is the SHA-256 of an empty body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// newRequest builds a signed request for key
+func (s *S3Store) newRequest(
+	ctx context.Context,
+	method string,
+	key string,
+	body io.ReadCloser,
+	payloadHash string,
+) (*http.Request, error) {
+
+	endpoint, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("s3 endpoint: %w", err)
+	}
+
+	host := endpoint.Host
+	uriPath := "/" + awsEscapePath(key)
+	if s.cfg.PathStyle {
+		uriPath = "/" + awsEscape(s.bucket) + uriPath
+	} else {
+		host = s.bucket + "." + host
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.Scheme+"://"+host+uriPath, body)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sign(req, uriPath, payloadHash)
+
+	return req, nil
+}
+
+// sign adds a SigV4 Authorization header covering the host and every
+// header already set on req. uriPath is the escaped request path.
+func (s *S3Store) sign(req *http.Request, uriPath, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriPath,
+		"", // no query parameters
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.cfg.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signature := hex.EncodeToString(hmacSHA256(sigV4Key(s.cfg.SecretAccessKey, day, s.cfg.Region, "s3"), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// sigV4Key derives the signing key for one day, region and service
+func sigV4Key(secret, day, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsEscapePath URI-encodes each segment of an object key as SigV4
+// requires: everything except A-Z a-z 0-9 - _ . ~ is percent-encoded
+func awsEscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = awsEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// s3Error turns an S3 error response into an error, mapping missing keys
+// to ErrReportNotFound
+func s3Error(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrReportNotFound
+	}
+
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("s3: %s: %s (HTTP %d)", body.Code, body.Message, resp.StatusCode)
+	}
+
+	return fmt.Errorf("s3: unexpected HTTP %d", resp.StatusCode)
+}
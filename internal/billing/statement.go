@@ -0,0 +1,175 @@
+package billing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	EntryDebit  = "debit"
+	EntryCredit = "credit"
+)
+
+// Entry kinds; a transfer fee is its own entry, booked alongside the
+// transfer it was charged for
+const (
+	KindTransfer = "transfer"
+	KindFee      = "fee"
+)
+
+var ErrAccountNotFound = errors.New("account not found")
+
+// StatementEntry is one completed transaction seen from the statement
+// account's side. Amount is negative for debits; Balance is the running
+// balance after the entry. Kind tells transfers and fees apart.
+type StatementEntry struct {
+	TransactionID int64     `json:"transaction_id"`
+	RequestID     string    `json:"request_id"`
+	BookedAt      time.Time `json:"booked_at"`
+	Counterparty  string    `json:"counterparty"`
+	Type          string    `json:"type"`
+	Kind          string    `json:"kind"`
+	Amount        int64     `json:"amount"`
+	Balance       int64     `json:"balance"`
+}
+
+// Statement summarises an account over [From, To). Entries is only
+// populated by BuildStatement; streaming writers receive entries one by one.
+// TotalFees is the part of TotalDebits paid in fees.
+type Statement struct {
+	AccountNumber  string           `json:"account_number"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	GeneratedAt    time.Time        `json:"generated_at"`
+	OpeningBalance int64            `json:"opening_balance"`
+	TotalDebits    int64            `json:"total_debits"`
+	TotalCredits   int64            `json:"total_credits"`
+	TotalFees      int64            `json:"total_fees"`
+	ClosingBalance int64            `json:"closing_balance"`
+	EntryCount     int              `json:"entry_count"`
+	Entries        []StatementEntry `json:"entries,omitempty"`
+}
+
+// BuildStatement loads the complete statement into memory, for renderers
+// that need every entry before they can write output
+func (s *ReportService) BuildStatement(
+	ctx context.Context,
+	accountNumber string,
+	from time.Time,
+	to time.Time,
+) (*Statement, error) {
+
+	var entries []StatementEntry
+
+	st, err := s.streamStatement(ctx, accountNumber, from, to,
+		func(*Statement) error { return nil },
+		func(e StatementEntry) error {
+			entries = append(entries, e)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	st.Entries = entries
+	return st, nil
+}
+
+// streamStatement reads the opening balance and the completed transactions
+// of the period from one snapshot, calling begin once with the opening
+// balance and then each for every entry with its running balance. The
+// returned statement carries the totals.
+func (s *ReportService) streamStatement(
+	ctx context.Context,
+	accountNumber string,
+	from time.Time,
+	to time.Time,
+	begin func(*Statement) error,
+	each func(StatementEntry) error,
+) (*Statement, error) {
+
+	tx, err := s.repo.BeginSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	opening, err := s.repo.GetOpeningBalance(ctx, tx, accountNumber, from)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	st := &Statement{
+		AccountNumber:  accountNumber,
+		From:           from,
+		To:             to,
+		GeneratedAt:    time.Now().UTC(),
+		OpeningBalance: opening,
+	}
+	if st.To.IsZero() {
+		st.To = st.GeneratedAt
+	}
+
+	if err := begin(st); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.GetCompletedTransactionsByAccount(ctx, tx, accountNumber, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balance := opening
+
+	for rows.Next() {
+		tv, bookedAt, err := scanTransactionView(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := StatementEntry{
+			TransactionID: tv.ID,
+			RequestID:     tv.RequestID,
+			BookedAt:      bookedAt,
+			Kind:          tv.Kind,
+		}
+
+		if tv.From == accountNumber {
+			entry.Type = EntryDebit
+			entry.Amount = -tv.Amount
+			entry.Counterparty = tv.To
+			st.TotalDebits += tv.Amount
+			if tv.Kind == KindFee {
+				st.TotalFees += tv.Amount
+			}
+		} else {
+			entry.Type = EntryCredit
+			entry.Amount = tv.Amount
+			entry.Counterparty = tv.From
+			st.TotalCredits += tv.Amount
+		}
+
+		balance += entry.Amount
+		entry.Balance = balance
+		st.EntryCount++
+
+		if err := each(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	st.ClosingBalance = balance
+
+	return st, nil
+}
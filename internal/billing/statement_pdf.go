@@ -0,0 +1,228 @@
+package billing
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopherpay/internal/wallet"
+)
+
+// Layout of the PDF statement, in points from the bottom-left corner
+const (
+	pdfMargin    = 40.0
+	pdfRight     = pdfPageWidth - pdfMargin
+	pdfRowHeight = 14.0
+	pdfTableMin  = 60.0 // lowest baseline a table row may use
+	pdfFooterY   = 30.0
+)
+
+// Table columns: left edges for text, right edges for amounts
+const (
+	colDate         = pdfMargin
+	colCounterparty = 125.0
+	colReference    = 220.0
+	colType         = 378.0
+	colAmountRight  = 480.0
+	colBalanceRight = pdfRight
+)
+
+// RenderStatementPDF renders st as a paginated A4 PDF: holder details and
+// a period summary on the first page, the entries as a table repeated
+// under a compact header on following pages, and a totals footer.
+func RenderStatementPDF(w io.Writer, holder *wallet.Account, st *Statement) error {
+	doc := newPDFDocument("Account Statement "+st.AccountNumber, st.GeneratedAt)
+	period := fmt.Sprintf("Period: %s to %s",
+		formatPeriodBound(st, true), formatPeriodBound(st, false))
+
+	page := doc.newPage()
+	y := drawStatementHeader(page, holder, st, period)
+	y = drawTableHeader(page, y)
+
+	// newPage continues the table on a fresh page
+	newPage := func() {
+		page = doc.newPage()
+		page.text(fontBold, 11, pdfMargin, 800, "Account Statement - "+st.AccountNumber)
+		page.text(fontRegular, 9, pdfMargin, 786, period)
+		y = drawTableHeader(page, 770)
+	}
+
+	if len(st.Entries) == 0 {
+		page.text(fontRegular, 9, colDate, y, "No completed transactions in this period.")
+		y -= pdfRowHeight
+	}
+
+	for i, e := range st.Entries {
+		if y < pdfTableMin {
+			newPage()
+		}
+
+		if i%2 == 1 {
+			page.fillRect(pdfMargin, y-4, pdfRight-pdfMargin, pdfRowHeight, 0.96)
+		}
+
+		kind := "Credit"
+		if e.Type == EntryDebit {
+			kind = "Debit"
+		}
+		if e.Kind == KindFee {
+			kind = "Fee"
+		}
+
+		page.text(fontMono, 8, colDate, y, e.BookedAt.Format("2006-01-02 15:04"))
+		page.text(fontRegular, 8, colCounterparty, y, truncate(e.Counterparty, 20))
+		page.text(fontMono, 7, colReference, y, truncate(e.RequestID, 36))
+		page.text(fontRegular, 8, colType, y, kind)
+		page.textRight(fontMono, 8, colAmountRight, y, formatCents(e.Amount))
+		page.textRight(fontMono, 8, colBalanceRight, y, formatCents(e.Balance))
+
+		y -= pdfRowHeight
+	}
+
+	// Keep the totals footer together with at least its own rule
+	const footerHeight = 5 * pdfRowHeight
+	if y-footerHeight < pdfTableMin {
+		newPage()
+	}
+
+	page.line(pdfMargin, y+pdfRowHeight-4, pdfRight, y+pdfRowHeight-4, 0.75)
+	y -= 4
+
+	totals := []struct {
+		label string
+		value int64
+	}{
+		{"Total credits", st.TotalCredits},
+		{"Total debits", -st.TotalDebits},
+		{"Closing balance", st.ClosingBalance},
+	}
+	if st.TotalFees != 0 {
+		// Fees are part of the debits; list them just below
+		totals = slices.Insert(totals, 2, struct {
+			label string
+			value int64
+		}{"  of which fees", -st.TotalFees})
+	}
+	for _, t := range totals {
+		page.text(fontBold, 9, colType, y, t.label)
+		page.textRight(fontMonoBold, 9, colBalanceRight, y, formatCents(t.value))
+		y -= pdfRowHeight
+	}
+	page.text(fontRegular, 8, pdfMargin, y+pdfRowHeight,
+		fmt.Sprintf("%d transaction(s)", st.EntryCount))
+
+	for i, p := range doc.pages {
+		p.line(pdfMargin, pdfFooterY+12, pdfRight, pdfFooterY+12, 0.5)
+		p.text(fontRegular, 8, pdfMargin, pdfFooterY,
+			"GopherPay - "+st.AccountNumber+" - generated "+st.GeneratedAt.Format("2006-01-02 15:04 MST"))
+		p.textRight(fontMono, 8, pdfRight, pdfFooterY, fmt.Sprintf("Page %d of %d", i+1, len(doc.pages)))
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// drawStatementHeader draws the title, holder details and period summary
+// and returns the baseline where the table starts
+func drawStatementHeader(page *pdfPage, holder *wallet.Account, st *Statement, period string) float64 {
+	page.text(fontBold, 18, pdfMargin, 795, "Account Statement")
+	page.textRight(fontMono, 9, pdfRight, 800, "GopherPay")
+
+	y := 765.0
+	page.text(fontBold, 11, pdfMargin, y, holder.Name)
+	y -= 14
+
+	details := []string{"Account number: " + holder.AccountNumber}
+	if holder.Email != "" {
+		details = append(details, "Email: "+holder.Email)
+	}
+	if holder.Phone != "" {
+		details = append(details, "Phone: "+holder.Phone)
+	}
+	details = append(details, period)
+
+	for _, d := range details {
+		page.text(fontRegular, 9, pdfMargin, y, d)
+		y -= 12
+	}
+
+	// Period summary box
+	y -= 8
+	boxTop := y
+	page.fillRect(pdfMargin, boxTop-44, pdfRight-pdfMargin, 44, 0.93)
+
+	summary := []struct {
+		label string
+		value int64
+	}{
+		{"Opening balance", st.OpeningBalance},
+		{"Total credits", st.TotalCredits},
+		{"Total debits", -st.TotalDebits},
+		{"Closing balance", st.ClosingBalance},
+	}
+	colWidth := (pdfRight - pdfMargin) / float64(len(summary))
+	for i, s := range summary {
+		x := pdfMargin + 8 + float64(i)*colWidth
+		page.text(fontRegular, 8, x, boxTop-15, s.label)
+		page.text(fontMonoBold, 11, x, boxTop-33, formatCents(s.value))
+	}
+
+	return boxTop - 70
+}
+
+// drawTableHeader draws the column headings with their baseline at y and
+// returns the baseline of the first row
+func drawTableHeader(page *pdfPage, y float64) float64 {
+	page.fillRect(pdfMargin, y-5, pdfRight-pdfMargin, pdfRowHeight+2, 0.85)
+
+	page.text(fontBold, 8, colDate, y, "Date (UTC)")
+	page.text(fontBold, 8, colCounterparty, y, "Counterparty")
+	page.text(fontBold, 8, colReference, y, "Reference")
+	page.text(fontBold, 8, colType, y, "Type")
+	page.text(fontBold, 8, colAmountRight-30, y, "Amount")
+	page.text(fontBold, 8, colBalanceRight-34, y, "Balance")
+
+	return y - pdfRowHeight - 4
+}
+
+// formatPeriodBound renders the statement start (or end) as a date, or
+// "account opening" for an open start
+func formatPeriodBound(st *Statement, start bool) string {
+	if start {
+		if st.From.IsZero() {
+			return "account opening"
+		}
+		return st.From.Format("2006-01-02")
+	}
+	return st.To.Format("2006-01-02 15:04")
+}
+
+// formatCents renders a minor-unit amount as 1,234.56
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	whole := strconv.FormatInt(cents/100, 10)
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+
+	return fmt.Sprintf("%s%s.%02d", sign, b.String(), cents%100)
+}
+
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "~"
+}
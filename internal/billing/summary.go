@@ -0,0 +1,196 @@
+package billing
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Summary output formats
+const (
+	SummaryCSV  = "csv"
+	SummaryJSON = "json"
+)
+
+const (
+	DefaultTopCounterparties = 5
+	MaxTopCounterparties     = 100
+)
+
+// SummaryFilter selects the transfers a Summary covers. Zero values mean
+// "no restriction".
+type SummaryFilter struct {
+	From time.Time // inclusive
+	To   time.Time // exclusive
+
+	// Accounts limits the top counterparty lists to accounts matching this
+	// pattern (see ListAccountNumbers); the other figures cover everyone
+	Accounts string
+
+	// Top is how many counterparties to list per account
+	Top int
+}
+
+// Validate checks the filter and applies defaults
+func (f *SummaryFilter) Validate() error {
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return errors.New("from must be before to")
+	}
+	if f.Top < 0 {
+		return errors.New("top must be positive")
+	}
+
+	if f.Top == 0 {
+		f.Top = DefaultTopCounterparties
+	}
+	if f.Top > MaxTopCounterparties {
+		f.Top = MaxTopCounterparties
+	}
+
+	return nil
+}
+
+// Summary aggregates transfers for finance reporting. Volumes and average
+// ticket sizes count completed transfers only; failure rates are failed
+// transfers over all transfers. Fees are reported separately and are not
+// counted as transfers.
+type Summary struct {
+	From        *time.Time `json:"from,omitempty"`
+	To          *time.Time `json:"to,omitempty"`
+	GeneratedAt time.Time  `json:"generated_at"`
+
+	Totals            VolumeStats        `json:"totals"`
+	Daily             []VolumeStats      `json:"daily"`
+	Monthly           []VolumeStats      `json:"monthly"`
+	FailureReasons    []FailureStats     `json:"failure_reasons"`
+	TopCounterparties []CounterpartyStat `json:"top_counterparties"`
+}
+
+// VolumeStats covers one period: a day (2026-01-31), a month (2026-01) or
+// the whole summary (empty period)
+type VolumeStats struct {
+	Period        string  `json:"period,omitempty"`
+	Transfers     int64   `json:"transfers"`
+	Completed     int64   `json:"completed"`
+	Failed        int64   `json:"failed"`
+	Volume        int64   `json:"volume"`
+	AverageTicket int64   `json:"average_ticket"`
+	FailureRate   float64 `json:"failure_rate"`
+	Fees          int64   `json:"fees"`
+}
+
+// FailureStats counts failed transfers with one reason. Rate is the share
+// of all transfers; failures recorded before reasons were tracked are
+// reported as "unknown".
+type FailureStats struct {
+	Reason string  `json:"reason"`
+	Failed int64   `json:"failed"`
+	Rate   float64 `json:"rate"`
+}
+
+// CounterpartyStat is one of an account's top counterparties by completed
+// volume, counting transfers in both directions
+type CounterpartyStat struct {
+	AccountNumber string `json:"account_number"`
+	Rank          int    `json:"rank"`
+	Counterparty  string `json:"counterparty"`
+	Transfers     int64  `json:"transfers"`
+	Volume        int64  `json:"volume"`
+}
+
+// Summary computes the finance summary for filter
+func (s *ReportService) Summary(ctx context.Context, filter SummaryFilter) (*Summary, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	summary, err := s.repo.GetSummary(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if !filter.From.IsZero() {
+		summary.From = &filter.From
+	}
+	if !filter.To.IsZero() {
+		summary.To = &filter.To
+	}
+	summary.GeneratedAt = time.Now().UTC()
+
+	return summary, nil
+}
+
+// IsSummaryFormat reports whether format is a supported summary format
+func IsSummaryFormat(format string) bool {
+	return format == SummaryCSV || format == SummaryJSON
+}
+
+// WriteSummary writes summary as JSON or as CSV. The CSV has one row per
+// figure with a section column (total, daily, monthly, failure_reason,
+// top_counterparty); columns that do not apply to a section are empty.
+func WriteSummary(w io.Writer, summary *Summary, format string) error {
+	switch format {
+	case SummaryJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+
+	case SummaryCSV:
+		return writeSummaryCSV(w, summary)
+
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+func writeSummaryCSV(w io.Writer, summary *Summary) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{
+		"section", "key", "rank", "counterparty",
+		"transfers", "completed", "failed", "volume", "average_ticket", "failure_rate", "fees",
+	})
+
+	volume := func(section string, v VolumeStats) {
+		cw.Write([]string{
+			section, v.Period, "", "",
+			formatInt(v.Transfers), formatInt(v.Completed), formatInt(v.Failed),
+			formatInt(v.Volume), formatInt(v.AverageTicket), formatRate(v.FailureRate),
+			formatInt(v.Fees),
+		})
+	}
+
+	volume("total", summary.Totals)
+	for _, v := range summary.Daily {
+		volume("daily", v)
+	}
+	for _, v := range summary.Monthly {
+		volume("monthly", v)
+	}
+
+	for _, f := range summary.FailureReasons {
+		cw.Write([]string{
+			"failure_reason", f.Reason, "", "",
+			"", "", formatInt(f.Failed), "", "", formatRate(f.Rate), "",
+		})
+	}
+
+	for _, c := range summary.TopCounterparties {
+		cw.Write([]string{
+			"top_counterparty", c.AccountNumber, strconv.Itoa(c.Rank), c.Counterparty,
+			formatInt(c.Transfers), "", "", formatInt(c.Volume), "", "", "",
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatRate renders a ratio with four decimals, e.g. 0.0125
+func formatRate(r float64) string {
+	return strconv.FormatFloat(r, 'f', 4, 64)
+}
@@ -0,0 +1,113 @@
+package billing
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPageSize is used when the caller does not ask for a limit
+	DefaultPageSize = 50
+
+	// MaxPageSize caps a single page of a transaction listing
+	MaxPageSize = 500
+)
+
+const (
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the keyset position of the last row of a page
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// Encode returns an opaque, URL-safe representation of the cursor
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return Cursor{CreatedAt: createdAt, ID: n}, nil
+}
+
+// TransactionFilter narrows a transaction listing. Zero values mean
+// "no restriction".
+type TransactionFilter struct {
+	// AccountNumber scopes the listing to one account's history
+	AccountNumber string
+
+	Status    string
+	From      time.Time // inclusive
+	To        time.Time // exclusive
+	MinAmount int64
+	MaxAmount int64
+
+	// Direction and Counterparty are relative to AccountNumber when set;
+	// without it Counterparty matches either side of the transfer.
+	Direction    string
+	Counterparty string
+
+	Limit int
+	After *Cursor
+}
+
+// Validate checks the filter and applies page size defaults
+func (f *TransactionFilter) Validate() error {
+	if f.Direction != "" && f.Direction != DirectionSent && f.Direction != DirectionReceived {
+		return errors.New("direction must be sent or received")
+	}
+	if f.Direction != "" && f.AccountNumber == "" {
+		return errors.New("direction requires an account")
+	}
+	if f.MinAmount < 0 || f.MaxAmount < 0 {
+		return errors.New("amount bounds must not be negative")
+	}
+	if f.MaxAmount > 0 && f.MinAmount > f.MaxAmount {
+		return errors.New("min_amount must not exceed max_amount")
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return errors.New("from must be before to")
+	}
+	if f.Limit < 0 {
+		return errors.New("limit must be positive")
+	}
+
+	if f.Limit == 0 {
+		f.Limit = DefaultPageSize
+	}
+	if f.Limit > MaxPageSize {
+		f.Limit = MaxPageSize
+	}
+
+	return nil
+}
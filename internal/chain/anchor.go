@@ -0,0 +1,96 @@
+package chain
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Anchor is a checkpoint of the chain head. Exported anchors kept outside
+// the database let verification catch a chain rewritten from some row
+// onward, which the hashes alone cannot.
+type Anchor struct {
+	Seq       int64     `json:"seq"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WriteAnchors writes anchors as JSON lines
+func WriteAnchors(w io.Writer, anchors []Anchor) error {
+	enc := json.NewEncoder(w)
+	for _, a := range anchors {
+		if err := enc.Encode(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadAnchorsFile reads anchors written by WriteAnchors
+func ReadAnchorsFile(path string) ([]Anchor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var anchors []Anchor
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var a Anchor
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		anchors = append(anchors, a)
+	}
+
+	return anchors, scanner.Err()
+}
+
+// Anchorer records an anchor of the chain head every interval. Running
+// one per server instance is safe; an unchanged head is anchored once.
+type Anchorer struct {
+	repo     Repository
+	interval time.Duration
+}
+
+func NewAnchorer(repo Repository, interval time.Duration) *Anchorer {
+	return &Anchorer{repo: repo, interval: interval}
+}
+
+// Start anchors the head every interval until ctx is cancelled
+func (a *Anchorer) Start(ctx context.Context) {
+
+	go func() {
+
+		slog.Info("chain anchoring started", "interval", a.interval)
+
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			anchor, err := a.repo.CreateAnchor(ctx)
+			if err != nil {
+				slog.Error("chain anchor failed", "error", err)
+				continue
+			}
+			if anchor != nil {
+				slog.Info("chain anchored", "seq", anchor.Seq, "hash", anchor.Hash)
+			}
+		}
+	}()
+}
@@ -0,0 +1,222 @@
+package chain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// Seal hashes the row as stored and links it after the current head. The
+// head row stays locked until tx ends, so rows are sealed one at a time
+// in commit order.
+func (r *PostgresRepository) Seal(
+	ctx context.Context,
+	tx *sql.Tx,
+	transactionID int64,
+) error {
+
+	var link Link
+
+	err := tx.QueryRowContext(
+		ctx,
+		`SELECT seq, head_hash FROM transaction_chain WHERE id = 1 FOR UPDATE`,
+	).Scan(&link.Seq, &link.PrevHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("transaction chain is not initialised; run the migrations")
+	}
+	if err != nil {
+		return err
+	}
+	link.Seq++
+
+	query := `
+	SELECT id, from_account_id, to_account_id, amount, kind, status,
+		COALESCE(failure_reason, ''), COALESCE(request_id, ''), created_at
+	FROM transactions
+	WHERE id = $1
+	`
+
+	err = tx.QueryRowContext(ctx, query, transactionID).Scan(
+		&link.TransactionID,
+		&link.FromAccountID,
+		&link.ToAccountID,
+		&link.Amount,
+		&link.Kind,
+		&link.Status,
+		&link.FailureReason,
+		&link.RequestID,
+		&link.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("sealing transaction %d: %w", transactionID, err)
+	}
+
+	link.Hash = link.ComputeHash()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE transactions SET chain_seq = $1, prev_hash = $2, row_hash = $3 WHERE id = $4`,
+		link.Seq,
+		link.PrevHash,
+		link.Hash,
+		link.TransactionID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE transaction_chain SET seq = $1, head_hash = $2, updated_at = now() WHERE id = 1`,
+		link.Seq,
+		link.Hash,
+	)
+
+	return err
+}
+
+func (r *PostgresRepository) Head(ctx context.Context) (*Head, error) {
+	var head Head
+
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT seq, head_hash, first_id FROM transaction_chain WHERE id = 1`,
+	).Scan(&head.Seq, &head.Hash, &head.FirstID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &head, nil
+}
+
+func (r *PostgresRepository) Links(
+	ctx context.Context,
+	fn func(*Link) error,
+) error {
+
+	query := `
+	SELECT chain_seq, id, from_account_id, to_account_id, amount, kind, status,
+		COALESCE(failure_reason, ''), COALESCE(request_id, ''), created_at,
+		COALESCE(prev_hash, ''), COALESCE(row_hash, '')
+	FROM transactions
+	WHERE chain_seq IS NOT NULL
+	ORDER BY chain_seq
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link Link
+		err := rows.Scan(
+			&link.Seq,
+			&link.TransactionID,
+			&link.FromAccountID,
+			&link.ToAccountID,
+			&link.Amount,
+			&link.Kind,
+			&link.Status,
+			&link.FailureReason,
+			&link.RequestID,
+			&link.CreatedAt,
+			&link.PrevHash,
+			&link.Hash,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(&link); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *PostgresRepository) Unsealed(
+	ctx context.Context,
+	firstID int64,
+) (*Unsealed, error) {
+
+	query := `
+	SELECT
+		COUNT(*) FILTER (WHERE id <= $1 AND status <> 'review'),
+		COUNT(*) FILTER (WHERE status = 'review'),
+		COALESCE(MIN(id) FILTER (WHERE id > $1 AND status <> 'review'), 0)
+	FROM transactions
+	WHERE chain_seq IS NULL
+	`
+
+	var u Unsealed
+	err := r.db.QueryRowContext(ctx, query, firstID).Scan(&u.Legacy, &u.Held, &u.StrayID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+func (r *PostgresRepository) CreateAnchor(ctx context.Context) (*Anchor, error) {
+	query := `
+	INSERT INTO transaction_chain_anchors (seq, hash)
+	SELECT seq, head_hash
+	FROM transaction_chain
+	WHERE id = 1 AND seq > 0
+	ON CONFLICT (seq) DO NOTHING
+	RETURNING seq, hash, created_at
+	`
+
+	var a Anchor
+	err := r.db.QueryRowContext(ctx, query).Scan(&a.Seq, &a.Hash, &a.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func (r *PostgresRepository) ListAnchors(
+	ctx context.Context,
+	since time.Time,
+) ([]Anchor, error) {
+
+	query := `
+	SELECT seq, hash, created_at
+	FROM transaction_chain_anchors
+	WHERE created_at >= $1
+	ORDER BY seq
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	anchors := []Anchor{}
+	for rows.Next() {
+		var a Anchor
+		if err := rows.Scan(&a.Seq, &a.Hash, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		anchors = append(anchors, a)
+	}
+
+	return anchors, rows.Err()
+}
@@ -0,0 +1,48 @@
+package chain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Head is the end of the chain. FirstID is the last transaction that
+// existed when the chain was started; older rows are not part of it.
+type Head struct {
+	Seq     int64  `json:"seq"`
+	Hash    string `json:"hash"`
+	FirstID int64  `json:"first_id"`
+}
+
+// Unsealed counts the transaction rows that are not in the chain
+type Unsealed struct {
+	// Legacy rows predate the chain
+	Legacy int64 `json:"legacy"`
+
+	// Held rows are transfers awaiting review; they are sealed when settled
+	Held int64 `json:"held"`
+
+	// StrayID is the first newer row that should have been sealed but was
+	// not, i.e. one inserted around the application; 0 if none
+	StrayID int64 `json:"stray_id,omitempty"`
+}
+
+type Repository interface {
+	// Seal a transaction row at the head of the chain (inside transaction)
+	Seal(ctx context.Context, tx *sql.Tx, transactionID int64) error
+
+	// The current end of the chain
+	Head(ctx context.Context) (*Head, error)
+
+	// Call fn with every sealed row in chain order
+	Links(ctx context.Context, fn func(*Link) error) error
+
+	// Count the rows outside the chain
+	Unsealed(ctx context.Context, firstID int64) (*Unsealed, error)
+
+	// Record the current head as an anchor; nil if it already is one
+	CreateAnchor(ctx context.Context) (*Anchor, error)
+
+	// Anchors created at or after since, oldest first
+	ListAnchors(ctx context.Context, since time.Time) ([]Anchor, error)
+}
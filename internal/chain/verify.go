@@ -0,0 +1,123 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Break is the first point where the chain does not hold
+type Break struct {
+	Seq           int64  `json:"seq,omitempty"`
+	TransactionID int64  `json:"transaction_id,omitempty"`
+	Problem       string `json:"problem"`
+}
+
+// Report is the outcome of Verify. The chain is intact when Break is nil.
+type Report struct {
+	Head     Head     `json:"head"`
+	Checked  int64    `json:"checked"`
+	Anchors  int      `json:"anchors_matched"`
+	Unsealed Unsealed `json:"unsealed"`
+	Break    *Break   `json:"break,omitempty"`
+}
+
+var errStop = errors.New("stop")
+
+// Verify recomputes every link from the rows as stored and reports the
+// first one that does not match, checking the head and the given anchors
+// along the way. It also flags rows newer than the chain that were never
+// sealed.
+func Verify(ctx context.Context, repo Repository, anchors []Anchor) (*Report, error) {
+	head, err := repo.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Head: *head}
+
+	anchored := make(map[int64]Anchor, len(anchors))
+	for _, a := range anchors {
+		anchored[a.Seq] = a
+	}
+
+	prev := Genesis
+	var last int64
+
+	err = repo.Links(ctx, func(link *Link) error {
+		brk := func(problem string, args ...any) error {
+			report.Break = &Break{
+				Seq:           link.Seq,
+				TransactionID: link.TransactionID,
+				Problem:       fmt.Sprintf(problem, args...),
+			}
+			return errStop
+		}
+
+		if link.Seq != last+1 {
+			report.Break = &Break{
+				Seq:     last + 1,
+				Problem: fmt.Sprintf("link %d is missing; the next sealed row is link %d", last+1, link.Seq),
+			}
+			return errStop
+		}
+		if link.PrevHash != prev {
+			return brk("previous hash does not match link %d", link.Seq-1)
+		}
+
+		computed := link.ComputeHash()
+		if computed != link.Hash {
+			return brk("row contents changed after it was sealed")
+		}
+		if a, ok := anchored[link.Seq]; ok {
+			if a.Hash != computed {
+				return brk("hash does not match the anchor taken %s", a.CreatedAt.UTC().Format(timeLayout))
+			}
+			report.Anchors++
+		}
+
+		prev = computed
+		last = link.Seq
+		report.Checked++
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStop) {
+		return nil, err
+	}
+	if report.Break != nil {
+		return report, nil
+	}
+
+	if last != head.Seq || prev != head.Hash {
+		report.Break = &Break{
+			Seq:     last + 1,
+			Problem: fmt.Sprintf("chain ends at link %d but the head is link %d", last, head.Seq),
+		}
+		return report, nil
+	}
+
+	for _, a := range anchors {
+		if a.Seq > last {
+			report.Break = &Break{
+				Seq:     last + 1,
+				Problem: fmt.Sprintf("anchor at link %d is beyond the end of the chain", a.Seq),
+			}
+			return report, nil
+		}
+	}
+
+	unsealed, err := repo.Unsealed(ctx, head.FirstID)
+	if err != nil {
+		return nil, err
+	}
+	report.Unsealed = *unsealed
+
+	if unsealed.StrayID != 0 {
+		report.Break = &Break{
+			TransactionID: unsealed.StrayID,
+			Problem:       "row was never sealed into the chain",
+		}
+	}
+
+	return report, nil
+}
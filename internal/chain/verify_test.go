@@ -0,0 +1,241 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRepository serves a chain held in memory
+type fakeRepository struct {
+	Repository
+	head     Head
+	links    []*Link
+	unsealed Unsealed
+}
+
+func (r *fakeRepository) Head(ctx context.Context) (*Head, error) {
+	h := r.head
+	return &h, nil
+}
+
+func (r *fakeRepository) Links(ctx context.Context, fn func(*Link) error) error {
+	for _, l := range r.links {
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) Unsealed(ctx context.Context, firstID int64) (*Unsealed, error) {
+	u := r.unsealed
+	return &u, nil
+}
+
+// testChain seals n transfers the way the repository does
+func testChain(n int) *fakeRepository {
+	repo := &fakeRepository{head: Head{Hash: Genesis, FirstID: 100}}
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	for i := 1; i <= n; i++ {
+		repo.links = append(repo.links, &Link{
+			Seq:           int64(i),
+			TransactionID: 100 + int64(i),
+			FromAccountID: 1,
+			ToAccountID:   2,
+			Amount:        int64(i) * 1000,
+			Kind:          "transfer",
+			Status:        "completed",
+			RequestID:     fmt.Sprintf("req-%d", i),
+			CreatedAt:     created.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	repo.reseal(0)
+	return repo
+}
+
+// reseal recomputes the hashes from links[from] on and moves the head,
+// as someone rewriting the chain with database access would
+func (r *fakeRepository) reseal(from int) {
+	prev := Genesis
+	if from > 0 {
+		prev = r.links[from-1].Hash
+	}
+	for _, l := range r.links[from:] {
+		l.PrevHash = prev
+		l.Hash = l.ComputeHash()
+		prev = l.Hash
+	}
+
+	r.head.Seq = int64(len(r.links))
+	r.head.Hash = prev
+}
+
+func (r *fakeRepository) anchor(seq int64) Anchor {
+	return Anchor{
+		Seq:       seq,
+		Hash:      r.links[seq-1].Hash,
+		CreatedAt: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name string
+
+		// tamper changes the chain; the anchors are taken before it
+		tamper func(r *fakeRepository)
+
+		wantSeq     int64
+		wantTxID    int64
+		wantProblem string
+	}{
+		{
+			name:   "intact",
+			tamper: func(r *fakeRepository) {},
+		},
+		{
+			name:        "edited row",
+			tamper:      func(r *fakeRepository) { r.links[1].Amount = 1 },
+			wantSeq:     2,
+			wantTxID:    102,
+			wantProblem: "row contents changed",
+		},
+		{
+			name:        "edited timestamp",
+			tamper:      func(r *fakeRepository) { r.links[1].CreatedAt = r.links[1].CreatedAt.Add(time.Second) },
+			wantSeq:     2,
+			wantTxID:    102,
+			wantProblem: "row contents changed",
+		},
+		{
+			name: "missing sequence number",
+			tamper: func(r *fakeRepository) {
+				r.links = append(r.links[:1], r.links[2:]...)
+			},
+			wantSeq:     2,
+			wantProblem: "link 2 is missing; the next sealed row is link 3",
+		},
+		{
+			name: "wrong prev_hash",
+			tamper: func(r *fakeRepository) {
+				// A consistent row whose back-link points elsewhere
+				r.links[2].PrevHash = Genesis
+				r.links[2].Hash = r.links[2].ComputeHash()
+			},
+			wantSeq:     3,
+			wantTxID:    103,
+			wantProblem: "previous hash does not match link 2",
+		},
+		{
+			name: "anchor mismatch",
+			tamper: func(r *fakeRepository) {
+				// Rewritten from link 2 on: every hash is consistent and
+				// only the anchor remembers the original
+				r.links[1].Amount = 1
+				r.reseal(1)
+			},
+			wantSeq:     2,
+			wantTxID:    102,
+			wantProblem: "hash does not match the anchor taken 2026-03-03T00:00:00.000000Z",
+		},
+		{
+			name: "last row deleted",
+			tamper: func(r *fakeRepository) {
+				r.links = r.links[:len(r.links)-1]
+			},
+			wantSeq:     4,
+			wantProblem: "chain ends at link 3 but the head is link 4",
+		},
+		{
+			name:        "stray unsealed row",
+			tamper:      func(r *fakeRepository) { r.unsealed.StrayID = 250 },
+			wantTxID:    250,
+			wantProblem: "row was never sealed into the chain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := testChain(4)
+			anchors := []Anchor{repo.anchor(2), repo.anchor(4)}
+			tt.tamper(repo)
+
+			report, err := Verify(context.Background(), repo, anchors)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+
+			if tt.wantProblem == "" {
+				if report.Break != nil {
+					t.Fatalf("unexpected break: %+v", report.Break)
+				}
+				if report.Checked != 4 || report.Anchors != 2 {
+					t.Errorf("checked %d links and %d anchors, want 4 and 2", report.Checked, report.Anchors)
+				}
+				return
+			}
+
+			if report.Break == nil {
+				t.Fatal("tampering not detected")
+			}
+			b := report.Break
+			if b.Seq != tt.wantSeq || b.TransactionID != tt.wantTxID {
+				t.Errorf("break at link %d (transaction %d), want link %d (transaction %d)",
+					b.Seq, b.TransactionID, tt.wantSeq, tt.wantTxID)
+			}
+			if !strings.Contains(b.Problem, tt.wantProblem) {
+				t.Errorf("problem = %q, want %q", b.Problem, tt.wantProblem)
+			}
+		})
+	}
+}
+
+func TestComputeHash(t *testing.T) {
+	base := testChain(1).links[0]
+
+	if got := base.ComputeHash(); got != base.Hash || len(got) != 64 {
+		t.Fatalf("ComputeHash = %q, want the sealed hash %q", got, base.Hash)
+	}
+
+	// Each field, and the previous hash, is covered
+	changes := map[string]func(l *Link){
+		"seq":            func(l *Link) { l.Seq++ },
+		"transaction id": func(l *Link) { l.TransactionID++ },
+		"from account":   func(l *Link) { l.FromAccountID++ },
+		"to account":     func(l *Link) { l.ToAccountID++ },
+		"amount":         func(l *Link) { l.Amount++ },
+		"kind":           func(l *Link) { l.Kind = "fee" },
+		"status":         func(l *Link) { l.Status = "failed" },
+		"failure reason": func(l *Link) { l.FailureReason = "insufficient_funds" },
+		"request id":     func(l *Link) { l.RequestID = "other" },
+		"created at":     func(l *Link) { l.CreatedAt = l.CreatedAt.Add(time.Microsecond) },
+		"prev hash":      func(l *Link) { l.PrevHash = strings.Repeat("f", 64) },
+		// Field boundaries are part of the encoding
+		"shifted text": func(l *Link) { l.Kind, l.Status = l.Kind+l.Status[:1], l.Status[1:] },
+	}
+	for name, change := range changes {
+		l := *base
+		change(&l)
+		if l.ComputeHash() == base.Hash {
+			t.Errorf("changing the %s keeps the hash", name)
+		}
+	}
+
+	// Postgres keeps microseconds and the time zone is not stored, so
+	// neither may change the hash of a row read back
+	same := map[string]func(l *Link){
+		"time zone":   func(l *Link) { l.CreatedAt = l.CreatedAt.In(time.FixedZone("CET", 3600)) },
+		"nanoseconds": func(l *Link) { l.CreatedAt = l.CreatedAt.Add(999 * time.Nanosecond) },
+	}
+	for name, change := range same {
+		l := *base
+		change(&l)
+		if l.ComputeHash() != base.Hash {
+			t.Errorf("changing the %s changes the hash", name)
+		}
+	}
+}
@@ -23,6 +23,60 @@ type Config struct {
 	// Worker
 	WorkerPoolSize int
 	WorkerCount    int
+
+	// Fees, limits and risk screening
+	FeeScheduleFile string
+	LimitsFile      string
+	KYCPolicyFile   string
+	RiskRulesFile   string
+
+	// Sanctions screening; off unless SanctionsListFile is set.
+	// SanctionsMinScore is the match threshold in percent.
+	SanctionsListFile string
+	SanctionsMinScore int
+	SanctionsFreeze   bool
+
+	// ChainAnchorInterval is how often the server anchors the head of the
+	// transaction hash chain; 0 disables it
+	ChainAnchorInterval time.Duration
+
+	// PIIKeyringFile holds the keys account PII is encrypted with;
+	// PII is stored in plaintext when empty
+	PIIKeyringFile string
+
+	// ReviewThreshold holds transfers of more than this many cents for
+	// manual review; 0 disables it
+	ReviewThreshold int64
+
+	// AdjustmentAccount is the counterparty of manual balance
+	// adjustments; they are disabled when empty
+	AdjustmentAccount string
+
+	// Billing
+	Currency string
+
+	// ReportStore is where reports are written: a directory, file:// or
+	// s3://bucket/prefix URL
+	ReportStore string
+
+	// S3-compatible object store used by s3:// report locations
+	S3Endpoint        string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool
+
+	// ReportSigningKey is the PEM Ed25519 private key used by report --sign
+	ReportSigningKey string
+
+	// Report jobs (async exports)
+	ReportJobWorkers int
+	ReportJobTimeout time.Duration
+
+	// Scheduler
+	SchedulerEnabled  bool
+	SchedulerInterval time.Duration
+	SchedulerWorkers  int
 }
 
 func Load() (*Config, error) {
@@ -43,6 +97,44 @@ func Load() (*Config, error) {
 		// Worker
 		WorkerPoolSize: getEnvInt("WORKER_POOL_SIZE", 100),
 		WorkerCount:    getEnvInt("WORKER_COUNT", 10),
+
+		// Fees, limits and risk screening
+		FeeScheduleFile: getEnv("FEE_SCHEDULE_FILE", ""),
+		LimitsFile:      getEnv("LIMITS_FILE", ""),
+		KYCPolicyFile:   getEnv("KYC_POLICY_FILE", ""),
+		RiskRulesFile:   getEnv("RISK_RULES_FILE", ""),
+		ReviewThreshold: int64(getEnvInt("REVIEW_THRESHOLD", 0)),
+
+		AdjustmentAccount: getEnv("ADJUSTMENT_ACCOUNT", ""),
+
+		SanctionsListFile: getEnv("SANCTIONS_LIST_FILE", ""),
+		SanctionsMinScore: getEnvInt("SANCTIONS_MIN_SCORE", 90),
+		SanctionsFreeze:   getEnvBool("SANCTIONS_FREEZE", false),
+
+		PIIKeyringFile: getEnv("PII_KEYRING_FILE", ""),
+
+		ChainAnchorInterval: time.Duration(getEnvInt("CHAIN_ANCHOR_INTERVAL_MINUTES", 60)) * time.Minute,
+
+		// Billing
+		Currency:    getEnv("CURRENCY", "INR"),
+		ReportStore: getEnv("REPORT_STORE", getEnv("REPORTS_DIR", "Reports")),
+
+		S3Endpoint:        getEnv("S3_ENDPOINT", ""),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+		S3PathStyle:       getEnvBool("S3_PATH_STYLE", false),
+
+		ReportSigningKey: getEnv("REPORT_SIGNING_KEY", "report-signing.pem"),
+
+		// Report jobs
+		ReportJobWorkers: getEnvInt("REPORT_JOB_WORKERS", 2),
+		ReportJobTimeout: time.Duration(getEnvInt("REPORT_JOB_TIMEOUT_SECONDS", 1800)) * time.Second,
+
+		// Scheduler
+		SchedulerEnabled:  getEnvBool("SCHEDULER_ENABLED", true),
+		SchedulerInterval: time.Duration(getEnvInt("SCHEDULER_INTERVAL_SECONDS", 30)) * time.Second,
+		SchedulerWorkers:  getEnvInt("SCHEDULER_WORKERS", 4),
 	}
 
 	return cfg, nil
@@ -81,3 +173,11 @@ func getEnvInt(key string, defaultVal int) int {
 	}
 	return defaultVal
 }
+
+func getEnvBool(key string, defaultVal bool) bool {
+	valStr := getEnv(key, "")
+	if val, err := strconv.ParseBool(valStr); err == nil {
+		return val
+	}
+	return defaultVal
+}
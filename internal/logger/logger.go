@@ -18,17 +18,19 @@ type multi struct {
 
 func New() *slog.Logger {
 	infoHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level:       slog.LevelInfo,
+		ReplaceAttr: redactPII,
 	})
 
 	errorHandler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-		Level: slog.LevelError,
+		Level:       slog.LevelError,
+		ReplaceAttr: redactPII,
 	})
 
 	// Try to open stderr.log for appending; if it fails, continue without file handler
 	var errorFileHandler slog.Handler
 	if f, err := os.OpenFile("stderr.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); err == nil {
-		errorFileHandler = slog.NewTextHandler(f, &slog.HandlerOptions{Level: slog.LevelError})
+		errorFileHandler = slog.NewTextHandler(f, &slog.HandlerOptions{Level: slog.LevelError, ReplaceAttr: redactPII})
 	}
 
 	return slog.New(&multi{
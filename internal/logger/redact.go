@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+
+	"gopherpay/internal/pii"
+)
+
+// piiKeys are attribute keys whose values are personal data. Emails and
+// phone numbers are masked so log lines can still be correlated; the
+// rest are replaced outright.
+var piiKeys = map[string]func(string) string{
+	"email":         pii.MaskEmail,
+	"phone":         pii.MaskPhone,
+	"dob":           redact,
+	"date_of_birth": redact,
+	"holder_name":   redact,
+	"reference":     func(v string) string { return pii.MaskTail(v, 4) },
+}
+
+func redact(string) string {
+	return pii.Redacted
+}
+
+// redactPII is the ReplaceAttr policy of every handler. It matches keys
+// case-insensitively at any group depth.
+func redactPII(_ []string, a slog.Attr) slog.Attr {
+	mask, ok := piiKeys[strings.ToLower(a.Key)]
+	if !ok || a.Value.Kind() == slog.KindGroup {
+		return a
+	}
+
+	return slog.String(a.Key, mask(a.Value.String()))
+}
@@ -0,0 +1,277 @@
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// prefix marks an encrypted value: enc:v1:<key id>:<wrapped data key>:<ciphertext>
+const prefix = "enc:v1:"
+
+var (
+	ErrUnknownKey = errors.New("encryption key not in keyring")
+	ErrMalformed  = errors.New("malformed encrypted value")
+)
+
+// Keyring encrypts values with envelope encryption. Every value gets a
+// fresh AES-256-GCM data key, which is wrapped by the active key
+// encryption key and stored next to the ciphertext with that key's ID.
+// Retired keys stay in the keyring to decrypt older values until they
+// are re-wrapped. A separate index key derives blind indexes.
+//
+// The keyring is safe for concurrent use and can be reloaded while in use.
+type Keyring struct {
+	path  string
+	state atomic.Pointer[keyState]
+}
+
+type keyState struct {
+	activeID string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// keyringFile is the JSON layout of the keyring file. Keys are base64
+// encoded 32 byte secrets.
+type keyringFile struct {
+	ActiveKey string            `json:"active_key"`
+	Keys      map[string]string `json:"keys"`
+	IndexKey  string            `json:"index_key"`
+}
+
+// LoadKeyring reads the keyring file at path. An empty path means no
+// keyring, and PII is stored in plaintext.
+func LoadKeyring(path string) (*Keyring, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	k := &Keyring{path: path}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Reload rereads the keyring file. On error the keys in use are kept.
+func (k *Keyring) Reload() error {
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return err
+	}
+
+	var f keyringFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("%s: %w", k.path, err)
+	}
+
+	state, err := f.parse()
+	if err != nil {
+		return fmt.Errorf("%s: %w", k.path, err)
+	}
+
+	// Blind indexes already stored would stop matching
+	if current := k.state.Load(); current != nil && !hmac.Equal(current.indexKey, state.indexKey) {
+		return fmt.Errorf("%s: index_key cannot change while running", k.path)
+	}
+
+	k.state.Store(state)
+	return nil
+}
+
+func (f keyringFile) parse() (*keyState, error) {
+	state := &keyState{
+		activeID: f.ActiveKey,
+		keys:     make(map[string]cipher.AEAD, len(f.Keys)),
+	}
+
+	for id, encoded := range f.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		secret, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		aead, err := newAEAD(secret)
+		if err != nil {
+			return nil, err
+		}
+		state.keys[id] = aead
+	}
+
+	if _, ok := state.keys[f.ActiveKey]; !ok {
+		return nil, fmt.Errorf("active_key %q is not in keys", f.ActiveKey)
+	}
+
+	indexKey, err := decodeKey(f.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index_key: %w", err)
+	}
+	state.indexKey = indexKey
+
+	return state, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	secret, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(secret))
+	}
+	return secret, nil
+}
+
+// NewKey returns a random base64 encoded key for the keyring file
+func NewKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(secret), nil
+}
+
+// ActiveKeyID is the ID of the key new values are encrypted with
+func (k *Keyring) ActiveKeyID() string {
+	return k.state.Load().activeID
+}
+
+// Encrypt seals plaintext; aad binds the value to where it is stored, so
+// it cannot be moved to another row or column
+func (k *Keyring) Encrypt(plaintext string, aad string) (string, error) {
+	state := k.state.Load()
+	kek := state.keys[state.activeID]
+
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+
+	wrapped, err := seal(kek, dek, []byte(state.activeID))
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(aead, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + state.activeID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt with the same aad
+func (k *Keyring) Decrypt(value string, aad string) (string, error) {
+	id, wrappedB64, sealedB64, ok := split(value)
+	if !ok {
+		return "", ErrMalformed
+	}
+
+	kek, ok := k.state.Load().keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+
+	wrapped, err := base64.RawStdEncoding.DecodeString(wrappedB64)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(sealedB64)
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	dek, err := open(kek, wrapped, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("unwrapping data key: %w", err)
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, sealed, []byte(aad))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether value was produced by Encrypt, as opposed
+// to plaintext written before encryption was enabled
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// KeyID returns the ID of the key an encrypted value was sealed with
+func KeyID(value string) string {
+	id, _, _, _ := split(value)
+	return id
+}
+
+func split(value string) (id, wrapped, sealed string, ok bool) {
+	if !IsEncrypted(value) {
+		return "", "", "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// BlindIndex returns a keyed hash of an already normalised value for
+// exact-match lookups. kind separates, say, emails from phone numbers
+// with the same text. The index key cannot be rotated without
+// recomputing every index.
+func (k *Keyring) BlindIndex(kind, value string) string {
+	mac := hmac.New(sha256.New, k.state.Load().indexKey)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns nonce || ciphertext
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
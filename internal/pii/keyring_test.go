@@ -0,0 +1,235 @@
+package pii
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newKey(t *testing.T) string {
+	t.Helper()
+	k, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func writeKeyring(t *testing.T, path string, f keyringFile) {
+	t.Helper()
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testKeyring loads a keyring with one key, k1, and returns it with its
+// file and contents for rewriting
+func testKeyring(t *testing.T) (*Keyring, string, keyringFile) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	f := keyringFile{
+		ActiveKey: "k1",
+		Keys:      map[string]string{"k1": newKey(t)},
+		IndexKey:  newKey(t),
+	}
+	writeKeyring(t, path, f)
+
+	k, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	return k, path, f
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k, _, _ := testKeyring(t)
+	const aad = "accounts:7:email"
+
+	value, err := k.Encrypt("ada@example.com", aad)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !IsEncrypted(value) || KeyID(value) != "k1" || strings.Contains(value, "ada") {
+		t.Fatalf("unexpected value %q", value)
+	}
+
+	got, err := k.Decrypt(value, aad)
+	if err != nil || got != "ada@example.com" {
+		t.Fatalf("Decrypt = %q, %v", got, err)
+	}
+
+	again, _ := k.Encrypt("ada@example.com", aad)
+	if again == value {
+		t.Error("encrypting twice gave the same value")
+	}
+
+	// A value copied to another row or column does not open there
+	for _, other := range []string{"accounts:8:email", "accounts:7:phone", ""} {
+		if _, err := k.Decrypt(value, other); err == nil {
+			t.Errorf("decrypted with aad %q", other)
+		}
+	}
+
+	id, wrapped, sealed, _ := split(value)
+	flip := func(s string) string {
+		b := []byte(s)
+		if b[len(b)/2] == 'A' {
+			b[len(b)/2] = 'B'
+		} else {
+			b[len(b)/2] = 'A'
+		}
+		return string(b)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  error
+	}{
+		{"plaintext", "ada@example.com", ErrMalformed},
+		{"missing part", prefix + id + ":" + wrapped, ErrMalformed},
+		{"bad base64", prefix + id + ":" + wrapped + ":!!", ErrMalformed},
+		{"unknown key", prefix + "k9:" + wrapped + ":" + sealed, ErrUnknownKey},
+		{"tampered ciphertext", prefix + id + ":" + wrapped + ":" + flip(sealed), nil},
+		{"tampered data key", prefix + id + ":" + flip(wrapped) + ":" + sealed, nil},
+	}
+	for _, tt := range tests {
+		_, err := k.Decrypt(tt.value, aad)
+		if err == nil {
+			t.Errorf("%s: decrypted", tt.name)
+			continue
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRotation(t *testing.T) {
+	k, path, f := testKeyring(t)
+	const aad = "accounts:7:phone"
+
+	old, err := k.Encrypt("+441234567890", aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate: k2 becomes active, k1 is retired but kept
+	f.Keys["k2"] = newKey(t)
+	f.ActiveKey = "k2"
+	writeKeyring(t, path, f)
+	if err := k.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if k.ActiveKeyID() != "k2" {
+		t.Fatalf("active key = %q, want k2", k.ActiveKeyID())
+	}
+
+	if got, err := k.Decrypt(old, aad); err != nil || got != "+441234567890" {
+		t.Errorf("Decrypt under retired key = %q, %v", got, err)
+	}
+
+	current, _ := k.Encrypt("+441234567890", aad)
+	if KeyID(current) != "k2" {
+		t.Errorf("new value sealed with %q, want k2", KeyID(current))
+	}
+
+	// The data key is bound to its key ID, so relabelling a value with
+	// another key's ID does not open it
+	relabelled := strings.Replace(old, prefix+"k1:", prefix+"k2:", 1)
+	if _, err := k.Decrypt(relabelled, aad); err == nil {
+		t.Error("decrypted a value relabelled to another key")
+	}
+
+	// Once k1 is dropped its values no longer open
+	delete(f.Keys, "k1")
+	writeKeyring(t, path, f)
+	if err := k.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if _, err := k.Decrypt(old, aad); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("after removing k1: err = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestReloadRejectsIndexKeyChange(t *testing.T) {
+	k, path, f := testKeyring(t)
+	index := k.BlindIndex("email", "ada@example.com")
+
+	// Rotating the encryption key and replacing the index key together
+	// must leave the running keyring untouched
+	f.Keys["k2"] = newKey(t)
+	f.ActiveKey = "k2"
+	f.IndexKey = newKey(t)
+	writeKeyring(t, path, f)
+
+	err := k.Reload()
+	if err == nil || !strings.Contains(err.Error(), "index_key cannot change") {
+		t.Fatalf("Reload = %v, want index_key error", err)
+	}
+	if k.ActiveKeyID() != "k1" {
+		t.Errorf("active key = %q after failed reload, want k1", k.ActiveKeyID())
+	}
+	if got := k.BlindIndex("email", "ada@example.com"); got != index {
+		t.Error("blind index changed after failed reload")
+	}
+
+	// A fresh process may start with a new index key
+	if _, err := LoadKeyring(path); err != nil {
+		t.Errorf("LoadKeyring with new index key: %v", err)
+	}
+}
+
+func TestReloadKeepsKeysOnError(t *testing.T) {
+	k, path, f := testKeyring(t)
+
+	bad := map[string]func(f *keyringFile){
+		"active key missing": func(f *keyringFile) { f.ActiveKey = "k9" },
+		"short key":          func(f *keyringFile) { f.Keys["k1"] = "c2hvcnQ=" },
+		"colon in id":        func(f *keyringFile) { f.Keys["a:b"] = f.Keys["k1"] },
+	}
+	for name, change := range bad {
+		g := keyringFile{ActiveKey: f.ActiveKey, Keys: map[string]string{}, IndexKey: f.IndexKey}
+		for id, key := range f.Keys {
+			g.Keys[id] = key
+		}
+		change(&g)
+		writeKeyring(t, path, g)
+
+		if err := k.Reload(); err == nil {
+			t.Errorf("%s: Reload accepted the file", name)
+		}
+		if _, err := k.Encrypt("x", "aad"); err != nil {
+			t.Errorf("%s: keyring unusable after failed reload: %v", name, err)
+		}
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	k, _, _ := testKeyring(t)
+
+	email := k.BlindIndex("email", "ada@example.com")
+	if email != k.BlindIndex("email", "ada@example.com") {
+		t.Error("blind index is not deterministic")
+	}
+	if email == k.BlindIndex("phone", "ada@example.com") {
+		t.Error("kinds share an index")
+	}
+	// The separator keeps kind and value apart
+	if k.BlindIndex("ab", "c") == k.BlindIndex("a", "bc") {
+		t.Error("kind and value run together")
+	}
+
+	other, _, _ := testKeyring(t)
+	if email == other.BlindIndex("email", "ada@example.com") {
+		t.Error("index does not depend on the key")
+	}
+}
@@ -0,0 +1,42 @@
+package pii
+
+import "strings"
+
+// Redacted replaces a value that is hidden entirely
+const Redacted = "[REDACTED]"
+
+// MaskEmail keeps the first letter and the domain: j***@mail.com
+func MaskEmail(email string) string {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return ""
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "***"
+	}
+
+	local := []rune(email[:at])
+	return string(local[0]) + "***" + email[at:]
+}
+
+// MaskPhone keeps the last four digits: ******1234
+func MaskPhone(phone string) string {
+	return MaskTail(NormalizePhone(phone), 4)
+}
+
+// MaskTail stars out all but the last keep characters of value. Values
+// too short to keep anything are starred out completely.
+func MaskTail(value string, keep int) string {
+	runes := []rune(value)
+	if len(runes) == 0 {
+		return ""
+	}
+	if len(runes) <= keep {
+		return strings.Repeat("*", len(runes))
+	}
+
+	hidden := len(runes) - keep
+	return strings.Repeat("*", hidden) + string(runes[hidden:])
+}
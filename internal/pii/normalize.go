@@ -0,0 +1,25 @@
+package pii
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeEmail is the form emails are indexed and looked up in
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone keeps the digits and a leading +, so "+91 98765-43210"
+// and "+919876543210" are the same number
+func NormalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+
+	var b strings.Builder
+	for i, r := range phone {
+		if unicode.IsDigit(r) || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
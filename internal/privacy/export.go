@@ -0,0 +1,237 @@
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"gopherpay/internal/audit"
+	"gopherpay/internal/billing"
+	"gopherpay/internal/wallet"
+)
+
+// BundleFormat identifies the layout of an export bundle
+const BundleFormat = "gopherpay.subject-export.v1"
+
+// Bundle is everything held about an account holder, in machine-readable
+// form. Screening results (sanctions alerts, risk decisions and review
+// notes) are compliance records and are not part of it.
+type Bundle struct {
+	Format       string                    `json:"format"`
+	GeneratedAt  time.Time                 `json:"generated_at"`
+	GeneratedBy  string                    `json:"generated_by"`
+	Profile      Profile                   `json:"profile"`
+	KYC          *wallet.AccountKYC        `json:"kyc"`
+	Limits       *wallet.AccountLimits     `json:"limits"`
+	Transactions []billing.TransactionView `json:"transactions"`
+	AuditEvents  []AuditEvent              `json:"audit_events"`
+}
+
+// Profile is the account as stored, unmasked
+type Profile struct {
+	AccountNumber string     `json:"account_number"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Phone         string     `json:"phone"`
+	DOB           string     `json:"dob,omitempty"`
+	Balance       int64      `json:"balance"`
+	Tier          string     `json:"tier"`
+	KYCTier       string     `json:"kyc_tier"`
+	Frozen        bool       `json:"frozen"`
+	ErasedAt      *time.Time `json:"erased_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// AuditEvent is a change made to the account by staff or the system
+type AuditEvent struct {
+	At     time.Time      `json:"at"`
+	Actor  string         `json:"actor"`
+	Action string         `json:"action"`
+	Detail map[string]any `json:"detail,omitempty"`
+}
+
+// Exporter assembles export bundles from the wallet and report services
+// and the audit log
+type Exporter struct {
+	wallet  *wallet.WalletService
+	reports *billing.ReportService
+	audit   *audit.Recorder
+}
+
+func NewExporter(
+	walletService *wallet.WalletService,
+	reports *billing.ReportService,
+	recorder *audit.Recorder,
+) *Exporter {
+
+	return &Exporter{wallet: walletService, reports: reports, audit: recorder}
+}
+
+// Export collects the bundle of an account on principal's behalf
+func (e *Exporter) Export(
+	ctx context.Context,
+	accountNumber string,
+	principal string,
+) (*Bundle, error) {
+
+	if principal == "" {
+		return nil, wallet.ErrPrincipalRequired
+	}
+
+	acc, err := e.wallet.GetAccountByNumber(ctx, accountNumber)
+	if err != nil {
+		return nil, wallet.ErrAccountNotFound
+	}
+
+	kyc, err := e.wallet.GetKYC(ctx, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("kyc: %w", err)
+	}
+
+	limits, err := e.wallet.GetLimits(ctx, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("limits: %w", err)
+	}
+
+	transactions, err := e.transactions(ctx, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("transactions: %w", err)
+	}
+
+	erasures, err := e.wallet.ListErasures(ctx, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("erasures: %w", err)
+	}
+
+	logged, err := e.audit.List(ctx, audit.Filter{
+		TargetType: audit.TargetAccount,
+		TargetID:   accountNumber,
+		Limit:      audit.MaxLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("audit events: %w", err)
+	}
+
+	return &Bundle{
+		Format:       BundleFormat,
+		GeneratedAt:  time.Now().UTC(),
+		GeneratedBy:  principal,
+		Profile:      newProfile(acc),
+		KYC:          kyc,
+		Limits:       limits,
+		Transactions: transactions,
+		AuditEvents:  auditEvents(kyc, erasures, logged),
+	}, nil
+}
+
+// transactions pages through the account's whole history
+func (e *Exporter) transactions(ctx context.Context, accountNumber string) ([]billing.TransactionView, error) {
+	filter := billing.TransactionFilter{
+		AccountNumber: accountNumber,
+		Limit:         billing.MaxPageSize,
+	}
+
+	all := []billing.TransactionView{}
+	for {
+		page, err := e.reports.ListTransactions(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Transactions...)
+
+		if page.NextCursor == "" {
+			return all, nil
+		}
+
+		cursor, err := billing.DecodeCursor(page.NextCursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = &cursor
+	}
+}
+
+func newProfile(acc *wallet.Account) Profile {
+	p := Profile{
+		AccountNumber: acc.AccountNumber,
+		Name:          acc.Name,
+		Email:         acc.Email,
+		Phone:         acc.Phone,
+		Balance:       acc.Balance,
+		Tier:          acc.Tier,
+		KYCTier:       acc.KYCTier,
+		Frozen:        acc.Frozen,
+		ErasedAt:      acc.ErasedAt,
+		CreatedAt:     acc.CreatedAt,
+		UpdatedAt:     acc.UpdatedAt,
+	}
+	if !acc.DOB.IsZero() {
+		p.DOB = acc.DOB.Format("2006-01-02")
+	}
+	return p
+}
+
+// derivedActions are audit log actions whose records are already listed
+// from the KYC and erasure tables, which go back further than the log
+var derivedActions = []string{
+	audit.ActionKYCDocument,
+	audit.ActionKYCTier,
+	audit.ActionAccountErase,
+}
+
+// auditEvents lists the recorded changes to the account, oldest first
+func auditEvents(kyc *wallet.AccountKYC, erasures []wallet.Erasure, logged []audit.Event) []AuditEvent {
+	events := []AuditEvent{}
+
+	for _, doc := range kyc.Documents {
+		events = append(events, AuditEvent{
+			At:     doc.CreatedAt,
+			Actor:  doc.RecordedBy,
+			Action: "kyc.document_recorded",
+			Detail: map[string]any{"document_id": doc.ID, "type": doc.Type},
+		})
+	}
+	for _, change := range kyc.History {
+		events = append(events, AuditEvent{
+			At:     change.CreatedAt,
+			Actor:  change.ChangedBy,
+			Action: "kyc.tier_changed",
+			Detail: map[string]any{"from": change.FromTier, "to": change.ToTier, "reason": change.Reason},
+		})
+	}
+	for _, erasure := range erasures {
+		events = append(events, AuditEvent{
+			At:     erasure.CreatedAt,
+			Actor:  erasure.ErasedBy,
+			Action: "account.erased",
+			Detail: map[string]any{"reason": erasure.Reason},
+		})
+	}
+	for _, event := range logged {
+		if slices.Contains(derivedActions, event.Action) {
+			continue
+		}
+
+		detail := map[string]any{}
+		for key, value := range event.Details {
+			detail[key] = value
+		}
+		if len(event.Diff) > 0 {
+			detail["changes"] = event.Diff
+		}
+		events = append(events, AuditEvent{
+			At:     event.CreatedAt,
+			Actor:  event.Actor,
+			Action: event.Action,
+			Detail: detail,
+		})
+	}
+
+	slices.SortStableFunc(events, func(a, b AuditEvent) int {
+		return a.At.Compare(b.At)
+	})
+
+	return events
+}
@@ -0,0 +1,38 @@
+package reportjob
+
+import (
+	"errors"
+	"time"
+)
+
+// Job states
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+var (
+	ErrJobNotFound = errors.New("report job not found")
+
+	// ErrJobNotReady is returned when downloading a job that has not
+	// succeeded (yet)
+	ErrJobNotReady = errors.New("report job has not succeeded")
+)
+
+// Job is one statement export. The finished file is kept in the report
+// store under ObjectName.
+type Job struct {
+	ID            string
+	AccountNumber string
+	Format        string
+	From          time.Time // zero means open-ended
+	To            time.Time
+	Status        string
+	ObjectName    string
+	Error         string
+	CreatedAt     time.Time
+	StartedAt     *time.Time
+	FinishedAt    *time.Time
+}
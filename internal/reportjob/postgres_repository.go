@@ -0,0 +1,147 @@
+package reportjob
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) CreateJob(ctx context.Context, job *Job) error {
+
+	query := `
+	INSERT INTO report_jobs
+	(id, account_number, format, period_from, period_to, status, object_name, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+	RETURNING created_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		job.ID,
+		job.AccountNumber,
+		job.Format,
+		nullTime(job.From),
+		nullTime(job.To),
+		job.Status,
+		job.ObjectName,
+	).Scan(&job.CreatedAt)
+}
+
+func (r *PostgresRepository) GetJob(ctx context.Context, id string) (*Job, error) {
+
+	query := `
+	SELECT id, account_number, format, period_from, period_to, status,
+	       object_name, error, created_at, started_at, finished_at
+	FROM report_jobs
+	WHERE id = $1
+	`
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+
+	return job, err
+}
+
+// ClaimJob uses FOR UPDATE SKIP LOCKED so concurrent workers, in this or
+// another instance, never claim the same job
+func (r *PostgresRepository) ClaimJob(ctx context.Context, staleAfter time.Duration) (*Job, error) {
+
+	query := `
+	UPDATE report_jobs
+	SET status = 'running',
+	    started_at = now(),
+	    error = ''
+	WHERE id = (
+		SELECT id
+		FROM report_jobs
+		WHERE status = 'queued'
+		   OR (status = 'running' AND started_at < now() - make_interval(secs => $1))
+		ORDER BY created_at ASC
+		FOR UPDATE SKIP LOCKED
+		LIMIT 1
+	)
+	RETURNING id, account_number, format, period_from, period_to, status,
+	          object_name, error, created_at, started_at, finished_at
+	`
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, staleAfter.Seconds()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return job, err
+}
+
+func (r *PostgresRepository) FinishJob(ctx context.Context, job *Job) error {
+
+	query := `
+	UPDATE report_jobs
+	SET status = $1,
+	    error = $2,
+	    finished_at = now()
+	WHERE id = $3
+	RETURNING finished_at
+	`
+
+	var finished time.Time
+	if err := r.db.QueryRowContext(ctx, query, job.Status, job.Error, job.ID).Scan(&finished); err != nil {
+		return err
+	}
+	job.FinishedAt = &finished
+
+	return nil
+}
+
+func scanJob(row *sql.Row) (*Job, error) {
+	var (
+		job                 Job
+		from, to            sql.NullTime
+		started, finishedAt sql.NullTime
+	)
+
+	if err := row.Scan(
+		&job.ID,
+		&job.AccountNumber,
+		&job.Format,
+		&from,
+		&to,
+		&job.Status,
+		&job.ObjectName,
+		&job.Error,
+		&job.CreatedAt,
+		&started,
+		&finishedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	job.From = from.Time
+	job.To = to.Time
+	if started.Valid {
+		t := started.Time
+		job.StartedAt = &t
+	}
+	if finishedAt.Valid {
+		t := finishedAt.Time
+		job.FinishedAt = &t
+	}
+
+	return &job, nil
+}
+
+// nullTime maps the zero time to SQL NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
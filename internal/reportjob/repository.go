@@ -0,0 +1,23 @@
+package reportjob
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+
+	// Insert a queued job; sets CreatedAt
+	CreateJob(ctx context.Context, job *Job) error
+
+	// Get a job by ID, or ErrJobNotFound
+	GetJob(ctx context.Context, id string) (*Job, error)
+
+	// Claim the oldest queued job, or a running one that has not finished
+	// within staleAfter (its worker died), and mark it running. Returns
+	// nil when there is nothing to do. Safe across server instances.
+	ClaimJob(ctx context.Context, staleAfter time.Duration) (*Job, error)
+
+	// Record the outcome of a job
+	FinishJob(ctx context.Context, job *Job) error
+}
@@ -0,0 +1,191 @@
+package reportjob
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gopherpay/internal/billing"
+)
+
+// pollInterval bounds how long a queued job waits when it was submitted
+// to another instance, or before a restart
+const pollInterval = 5 * time.Second
+
+// Runner executes report jobs on its own goroutines, separate from the
+// transfer worker pool, so large exports never hold up payments. Jobs are
+// persisted before they run: a job submitted to one instance may be run
+// by any instance, and queued jobs survive a restart.
+type Runner struct {
+	repo    Repository
+	reports *billing.ReportService
+	store   billing.ReportStore
+	workers int
+	timeout time.Duration
+	wake    chan struct{}
+}
+
+func NewRunner(
+	repo Repository,
+	reports *billing.ReportService,
+	store billing.ReportStore,
+	workers int,
+	timeout time.Duration,
+) *Runner {
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Runner{
+		repo:    repo,
+		reports: reports,
+		store:   store,
+		workers: workers,
+		timeout: timeout,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Start launches the workers; they stop when ctx is cancelled
+func (r *Runner) Start(ctx context.Context) {
+	for i := 0; i < r.workers; i++ {
+		go r.work(ctx, i)
+	}
+}
+
+// Submit queues a statement export and returns immediately. The caller
+// validates the account and period.
+func (r *Runner) Submit(ctx context.Context, accountNumber, format string, from, to time.Time) (*Job, error) {
+	if !billing.IsFormat(format) {
+		return nil, billing.ErrUnsupportedFormat
+	}
+
+	id := uuid.NewString()
+
+	job := &Job{
+		ID:            id,
+		AccountNumber: accountNumber,
+		Format:        format,
+		From:          from,
+		To:            to,
+		Status:        StatusQueued,
+		ObjectName: path.Join("exports", id,
+			path.Base(accountNumber)+"_statement."+billing.FileExtension(format)),
+	}
+
+	if err := r.repo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// Wake an idle worker; if all are busy the job waits its turn
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// Get returns a job by ID
+func (r *Runner) Get(ctx context.Context, id string) (*Job, error) {
+	return r.repo.GetJob(ctx, id)
+}
+
+// Open returns the finished file of a succeeded job
+func (r *Runner) Open(ctx context.Context, id string) (*Job, io.ReadCloser, error) {
+	job, err := r.repo.GetJob(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != StatusSucceeded {
+		return job, nil, ErrJobNotReady
+	}
+
+	file, err := r.store.Open(ctx, job.ObjectName)
+	if err != nil {
+		return job, nil, err
+	}
+
+	return job, file, nil
+}
+
+func (r *Runner) work(ctx context.Context, workerID int) {
+
+	slog.Info("report worker started", "worker_id", workerID)
+
+	for {
+		// Jobs running for twice the timeout belong to a dead worker
+		job, err := r.repo.ClaimJob(ctx, 2*r.timeout)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("report job claim failed", "worker_id", workerID, "error", err)
+		}
+
+		if job != nil {
+			r.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (r *Runner) run(ctx context.Context, job *Job) {
+
+	slog.Info("report job started",
+		"job_id", job.ID,
+		"account_number", job.AccountNumber,
+		"format", job.Format,
+	)
+
+	jobCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	err := r.reports.GenerateReport(
+		jobCtx,
+		job.AccountNumber,
+		job.From,
+		job.To,
+		job.Format,
+		billing.OutputOptions{},
+		r.store,
+		job.ObjectName,
+	)
+
+	if ctx.Err() != nil {
+		// Shutting down: leave the job running so another worker reclaims
+		// it once it goes stale, rather than failing it
+		slog.Info("report job interrupted", "job_id", job.ID)
+		return
+	}
+
+	job.Status = StatusSucceeded
+	job.Error = ""
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+	}
+
+	recordCtx, cancelRecord := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelRecord()
+
+	if err := r.repo.FinishJob(recordCtx, job); err != nil {
+		slog.Error("report job record failed", "job_id", job.ID, "error", err)
+	}
+
+	if job.Status == StatusFailed {
+		slog.Error("report job failed", "job_id", job.ID, "error", job.Error)
+		return
+	}
+
+	slog.Info("report job completed", "job_id", job.ID, "object", r.store.Location(job.ObjectName))
+}
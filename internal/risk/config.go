@@ -0,0 +1,91 @@
+package risk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Config sets the thresholds and the rules in use. A transfer scoring at
+// least BlockScore is blocked, at least ReviewScore held for review;
+// a zero threshold is never reached. A rule left out is disabled.
+type Config struct {
+	ReviewScore int `json:"review_score"`
+	BlockScore  int `json:"block_score"`
+
+	Rules struct {
+		NewAccountLargeAmount *NewAccountLargeAmount `json:"new_account_large_amount,omitempty"`
+		FanOut                *FanOut                `json:"fan_out,omitempty"`
+		RoundAmountBurst      *RoundAmountBurst      `json:"round_amount_burst,omitempty"`
+		UnusualHours          *UnusualHours          `json:"unusual_hours,omitempty"`
+	} `json:"rules"`
+}
+
+// LoadConfig reads a JSON risk configuration. An empty path means no
+// screening and returns nil.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks the thresholds and rule settings
+func (c *Config) Validate() error {
+	if c.ReviewScore < 0 || c.BlockScore < 0 {
+		return errors.New("scores must not be negative")
+	}
+	if c.ReviewScore > 0 && c.BlockScore > 0 && c.ReviewScore > c.BlockScore {
+		return errors.New("review_score must not exceed block_score")
+	}
+
+	for _, rule := range c.rules() {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("%s: %w", rule.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// rules returns the enabled rules in evaluation order
+func (c *Config) rules() []configuredRule {
+	var rules []configuredRule
+
+	if r := c.Rules.NewAccountLargeAmount; r != nil {
+		rules = append(rules, r)
+	}
+	if r := c.Rules.FanOut; r != nil {
+		rules = append(rules, r)
+	}
+	if r := c.Rules.RoundAmountBurst; r != nil {
+		rules = append(rules, r)
+	}
+	if r := c.Rules.UnusualHours; r != nil {
+		rules = append(rules, r)
+	}
+
+	return rules
+}
+
+// configuredRule is a built-in rule read from Config
+type configuredRule interface {
+	Rule
+	validate() error
+}
@@ -0,0 +1,112 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gopherpay/internal/wallet"
+)
+
+// Engine is the rule-based wallet.RiskEvaluator. Every decision, allowed
+// transfers included, is recorded with the rules that fired.
+type Engine struct {
+	repo        Repository
+	rules       []Rule
+	reviewScore int
+	blockScore  int
+	now         func() time.Time
+}
+
+// NewEngine builds an engine with the rules enabled in cfg
+func NewEngine(repo Repository, cfg *Config) *Engine {
+	e := &Engine{
+		repo:        repo,
+		reviewScore: cfg.ReviewScore,
+		blockScore:  cfg.BlockScore,
+		now:         time.Now,
+	}
+
+	for _, rule := range cfg.rules() {
+		e.rules = append(e.rules, rule)
+	}
+
+	return e
+}
+
+// AddRule plugs in a rule beyond the built-in ones
+func (e *Engine) AddRule(rule Rule) {
+	e.rules = append(e.rules, rule)
+}
+
+// Evaluate runs every rule, adds up the scores and records the decision
+func (e *Engine) Evaluate(ctx context.Context, tx *sql.Tx, t wallet.RiskTransfer) (*wallet.RiskDecision, error) {
+	env := &Env{Now: e.now(), repo: e.repo, tx: tx}
+
+	decision := &wallet.RiskDecision{
+		Action: wallet.RiskAllow,
+		Hits:   []wallet.RiskHit{},
+	}
+
+	for _, rule := range e.rules {
+		hit, err := rule.Check(ctx, env, t)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Name(), err)
+		}
+		if hit != nil {
+			decision.Hits = append(decision.Hits, *hit)
+			decision.Score += hit.Score
+		}
+	}
+
+	switch {
+	case e.blockScore > 0 && decision.Score >= e.blockScore:
+		decision.Action = wallet.RiskBlock
+	case e.reviewScore > 0 && decision.Score >= e.reviewScore:
+		decision.Action = wallet.RiskReview
+	}
+
+	if err := e.repo.SaveDecision(ctx, tx, t, decision); err != nil {
+		return nil, err
+	}
+
+	if decision.Action != wallet.RiskAllow {
+		slog.Warn("transfer flagged by risk screening",
+			"request_id", t.RequestID,
+			"from", t.FromAccountNumber,
+			"action", decision.Action,
+			"score", decision.Score,
+		)
+	}
+
+	return decision, nil
+}
+
+// Env gives rules read access to the sender's history from inside the
+// transfer transaction
+type Env struct {
+	Now time.Time
+
+	repo Repository
+	tx   *sql.Tx
+}
+
+// AccountCreatedWithin reports whether the account was opened less than
+// age ago
+func (e *Env) AccountCreatedWithin(ctx context.Context, accountID int64, age time.Duration) (bool, error) {
+	return e.repo.AccountCreatedWithin(ctx, e.tx, accountID, age)
+}
+
+// NewReceivers counts the distinct receivers from paid (or tried to pay)
+// within window that it had never paid before, plus toID if it is new too
+func (e *Env) NewReceivers(ctx context.Context, fromID, toID int64, window time.Duration) (int, error) {
+	return e.repo.CountNewReceivers(ctx, e.tx, fromID, toID, window)
+}
+
+// RoundAmounts counts from's transfers within window whose amount is a
+// multiple of multipleOf
+func (e *Env) RoundAmounts(ctx context.Context, fromID, multipleOf int64, window time.Duration) (int, error) {
+	return e.repo.CountRoundAmounts(ctx, e.tx, fromID, multipleOf, window)
+}
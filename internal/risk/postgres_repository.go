@@ -0,0 +1,134 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"gopherpay/internal/wallet"
+)
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) SaveDecision(
+	ctx context.Context,
+	tx *sql.Tx,
+	t wallet.RiskTransfer,
+	d *wallet.RiskDecision,
+) error {
+
+	hits, err := json.Marshal(d.Hits)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO risk_decisions
+	(request_id, from_account_id, to_account_id, amount, action, score, hits, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+	`
+
+	_, err = tx.ExecContext(
+		ctx,
+		query,
+		t.RequestID,
+		t.FromAccountID,
+		t.ToAccountID,
+		t.Amount,
+		d.Action,
+		d.Score,
+		string(hits),
+	)
+
+	return err
+}
+
+// Time windows are computed in the database, against the same clock that
+// stamped created_at
+
+func (r *PostgresRepository) AccountCreatedWithin(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountID int64,
+	age time.Duration,
+) (bool, error) {
+
+	query := `
+	SELECT created_at > now() - make_interval(secs => $2)
+	FROM accounts
+	WHERE id = $1
+	`
+
+	var isNew bool
+	err := tx.QueryRowContext(ctx, query, accountID, age.Seconds()).Scan(&isNew)
+	return isNew, err
+}
+
+// Held transfers count as attempts alongside completed ones
+func (r *PostgresRepository) CountNewReceivers(
+	ctx context.Context,
+	tx *sql.Tx,
+	fromID int64,
+	toID int64,
+	window time.Duration,
+) (int, error) {
+
+	query := `
+	SELECT COUNT(DISTINCT recent.to_account_id)
+	FROM (
+		SELECT to_account_id
+		FROM transactions
+		WHERE from_account_id = $1
+		  AND kind = 'transfer'
+		  AND status IN ('completed', 'review')
+		  AND created_at >= now() - make_interval(secs => $3)
+		UNION
+		SELECT $2::BIGINT
+	) recent
+	WHERE recent.to_account_id <> $1
+	  AND NOT EXISTS (
+		SELECT 1
+		FROM transactions p
+		WHERE p.from_account_id = $1
+		  AND p.to_account_id = recent.to_account_id
+		  AND p.kind = 'transfer'
+		  AND p.status = 'completed'
+		  AND p.created_at < now() - make_interval(secs => $3)
+	  )
+	`
+
+	var n int
+	err := tx.QueryRowContext(ctx, query, fromID, toID, window.Seconds()).Scan(&n)
+	return n, err
+}
+
+func (r *PostgresRepository) CountRoundAmounts(
+	ctx context.Context,
+	tx *sql.Tx,
+	fromID int64,
+	multipleOf int64,
+	window time.Duration,
+) (int, error) {
+
+	query := `
+	SELECT COUNT(*)
+	FROM transactions
+	WHERE from_account_id = $1
+	  AND to_account_id <> $1
+	  AND kind = 'transfer'
+	  AND status IN ('completed', 'review')
+	  AND amount % $2 = 0
+	  AND created_at >= now() - make_interval(secs => $3)
+	`
+
+	var n int
+	err := tx.QueryRowContext(ctx, query, fromID, multipleOf, window.Seconds()).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,27 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gopherpay/internal/wallet"
+)
+
+// Repository reads transfer history for the rules and records decisions.
+// Every method runs inside the transfer's transaction.
+type Repository interface {
+
+	// Record a screening decision and the rules that fired
+	SaveDecision(ctx context.Context, tx *sql.Tx, t wallet.RiskTransfer, d *wallet.RiskDecision) error
+
+	// Whether the account was created less than age ago
+	AccountCreatedWithin(ctx context.Context, tx *sql.Tx, accountID int64, age time.Duration) (bool, error)
+
+	// Distinct receivers paid within window, plus toID, that the sender
+	// had not paid before the window
+	CountNewReceivers(ctx context.Context, tx *sql.Tx, fromID, toID int64, window time.Duration) (int, error)
+
+	// Transfers within window whose amount is a multiple of multipleOf
+	CountRoundAmounts(ctx context.Context, tx *sql.Tx, fromID, multipleOf int64, window time.Duration) (int, error)
+}
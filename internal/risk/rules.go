@@ -0,0 +1,180 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gopherpay/internal/wallet"
+)
+
+// Rule scores one risk pattern. Check returns nil when the pattern does
+// not match. Rules read history through env, which sees the database as
+// of the transfer's own transaction.
+type Rule interface {
+	Name() string
+	Check(ctx context.Context, env *Env, t wallet.RiskTransfer) (*wallet.RiskHit, error)
+}
+
+// NewAccountLargeAmount flags a large transfer from an account opened
+// recently
+type NewAccountLargeAmount struct {
+	Score              int   `json:"score"`
+	MaxAccountAgeHours int   `json:"max_account_age_hours"`
+	MinAmount          int64 `json:"min_amount"`
+}
+
+func (r *NewAccountLargeAmount) Name() string { return "new_account_large_amount" }
+
+func (r *NewAccountLargeAmount) validate() error {
+	if r.Score <= 0 || r.MaxAccountAgeHours <= 0 || r.MinAmount <= 0 {
+		return errors.New("score, max_account_age_hours and min_amount must be positive")
+	}
+	return nil
+}
+
+func (r *NewAccountLargeAmount) Check(ctx context.Context, env *Env, t wallet.RiskTransfer) (*wallet.RiskHit, error) {
+	if t.Amount < r.MinAmount {
+		return nil, nil
+	}
+
+	age := time.Duration(r.MaxAccountAgeHours) * time.Hour
+	isNew, err := env.AccountCreatedWithin(ctx, t.FromAccountID, age)
+	if err != nil || !isNew {
+		return nil, err
+	}
+
+	return &wallet.RiskHit{
+		Rule:   r.Name(),
+		Score:  r.Score,
+		Detail: fmt.Sprintf("account opened within %dh sending %d", r.MaxAccountAgeHours, t.Amount),
+	}, nil
+}
+
+// FanOut flags a sender paying many receivers it has not paid before
+// within a short window, this transfer included
+type FanOut struct {
+	Score           int `json:"score"`
+	WindowMinutes   int `json:"window_minutes"`
+	MinNewReceivers int `json:"min_new_receivers"`
+}
+
+func (r *FanOut) Name() string { return "fan_out" }
+
+func (r *FanOut) validate() error {
+	if r.Score <= 0 || r.WindowMinutes <= 0 || r.MinNewReceivers <= 0 {
+		return errors.New("score, window_minutes and min_new_receivers must be positive")
+	}
+	return nil
+}
+
+func (r *FanOut) Check(ctx context.Context, env *Env, t wallet.RiskTransfer) (*wallet.RiskHit, error) {
+	window := time.Duration(r.WindowMinutes) * time.Minute
+
+	n, err := env.NewReceivers(ctx, t.FromAccountID, t.ToAccountID, window)
+	if err != nil || n < r.MinNewReceivers {
+		return nil, err
+	}
+
+	return &wallet.RiskHit{
+		Rule:   r.Name(),
+		Score:  r.Score,
+		Detail: fmt.Sprintf("%d new receivers in %dm", n, r.WindowMinutes),
+	}, nil
+}
+
+// RoundAmountBurst flags several transfers of round amounts (multiples of
+// MultipleOf) from one sender within a short window, this one included
+type RoundAmountBurst struct {
+	Score         int   `json:"score"`
+	WindowMinutes int   `json:"window_minutes"`
+	MinCount      int   `json:"min_count"`
+	MultipleOf    int64 `json:"multiple_of"`
+}
+
+func (r *RoundAmountBurst) Name() string { return "round_amount_burst" }
+
+func (r *RoundAmountBurst) validate() error {
+	if r.Score <= 0 || r.WindowMinutes <= 0 || r.MinCount <= 0 || r.MultipleOf <= 0 {
+		return errors.New("score, window_minutes, min_count and multiple_of must be positive")
+	}
+	return nil
+}
+
+func (r *RoundAmountBurst) Check(ctx context.Context, env *Env, t wallet.RiskTransfer) (*wallet.RiskHit, error) {
+	if t.Amount%r.MultipleOf != 0 {
+		return nil, nil
+	}
+
+	window := time.Duration(r.WindowMinutes) * time.Minute
+
+	n, err := env.RoundAmounts(ctx, t.FromAccountID, r.MultipleOf, window)
+	if err != nil {
+		return nil, err
+	}
+	n++ // this transfer
+	if n < r.MinCount {
+		return nil, nil
+	}
+
+	return &wallet.RiskHit{
+		Rule:   r.Name(),
+		Score:  r.Score,
+		Detail: fmt.Sprintf("%d multiples of %d in %dm", n, r.MultipleOf, r.WindowMinutes),
+	}, nil
+}
+
+// UnusualHours flags transfers made between FromHour and ToHour (exclusive)
+// in Timezone, default UTC. The range may wrap midnight, e.g. 22 to 5.
+type UnusualHours struct {
+	Score    int    `json:"score"`
+	FromHour int    `json:"from_hour"`
+	ToHour   int    `json:"to_hour"`
+	Timezone string `json:"timezone,omitempty"`
+
+	loc *time.Location
+}
+
+func (r *UnusualHours) Name() string { return "unusual_hours" }
+
+func (r *UnusualHours) validate() error {
+	if r.Score <= 0 {
+		return errors.New("score must be positive")
+	}
+	if r.FromHour < 0 || r.FromHour > 23 || r.ToHour < 0 || r.ToHour > 23 || r.FromHour == r.ToHour {
+		return errors.New("from_hour and to_hour must be different hours 0-23")
+	}
+
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return err
+	}
+	r.loc = loc
+
+	return nil
+}
+
+func (r *UnusualHours) Check(ctx context.Context, env *Env, t wallet.RiskTransfer) (*wallet.RiskHit, error) {
+	loc := r.loc
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	now := env.Now.In(loc)
+	hour := now.Hour()
+
+	inRange := hour >= r.FromHour && hour < r.ToHour
+	if r.FromHour > r.ToHour {
+		inRange = hour >= r.FromHour || hour < r.ToHour
+	}
+	if !inRange {
+		return nil, nil
+	}
+
+	return &wallet.RiskHit{
+		Rule:   r.Name(),
+		Score:  r.Score,
+		Detail: "transfer at " + now.Format("15:04 MST"),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package sanctions
+
+import (
+	"errors"
+	"time"
+)
+
+// Alert statuses. A dismissed alert is a false positive and is not raised
+// again for the same name; a confirmed one is a true match.
+const (
+	AlertOpen      = "open"
+	AlertDismissed = "dismissed"
+	AlertConfirmed = "confirmed"
+)
+
+var (
+	ErrAlertNotFound = errors.New("alert not found")
+	ErrAlertNotOpen  = errors.New("alert is not open")
+)
+
+// Alert is a match of an account holder against the list
+type Alert struct {
+	ID            int64      `json:"id"`
+	AccountNumber string     `json:"account_number"`
+	ScreenedName  string     `json:"screened_name"`
+	Trigger       string     `json:"trigger"`
+	RequestID     string     `json:"request_id,omitempty"`
+	EntryUID      string     `json:"entry_uid"`
+	EntryName     string     `json:"entry_name"`
+	MatchedName   string     `json:"matched_name"`
+	Programs      string     `json:"programs,omitempty"`
+	Score         float64    `json:"score"`
+	Status        string     `json:"status"`
+	ResolvedBy    string     `json:"resolved_by,omitempty"`
+	Note          string     `json:"note,omitempty"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
@@ -0,0 +1,172 @@
+package sanctions
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Entry is one listed party with the names it is known by
+type Entry struct {
+	UID      string
+	Name     string
+	Type     string
+	Programs []string
+	Aliases  []string
+}
+
+// Names returns the primary name followed by the aliases
+func (e Entry) Names() []string {
+	return append([]string{e.Name}, e.Aliases...)
+}
+
+// LoadList reads a sanctions list. Files ending in .xml are read as an
+// OFAC SDN style document, anything else as CSV.
+func LoadList(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		entries, err = readXML(f)
+	} else {
+		entries, err = readCSV(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s: no entries", path)
+	}
+
+	return entries, nil
+}
+
+// readCSV reads a list with a header row naming at least uid and name.
+// Optional columns are type, programs and aliases; programs and aliases
+// hold several values separated by semicolons.
+func readCSV(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := col["uid"]; !ok {
+		return nil, errors.New("missing uid column")
+	}
+	if _, ok := col["name"]; !ok {
+		return nil, errors.New("missing name column")
+	}
+
+	field := func(rec []string, name string) string {
+		i, ok := col[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var entries []Entry
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := Entry{
+			UID:      field(rec, "uid"),
+			Name:     field(rec, "name"),
+			Type:     field(rec, "type"),
+			Programs: splitList(field(rec, "programs")),
+			Aliases:  splitList(field(rec, "aliases")),
+		}
+		if entry.UID == "" || entry.Name == "" {
+			return nil, fmt.Errorf("line %d: uid and name are required", line)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ";") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// sdnList mirrors the parts of the OFAC SDN XML format that are screened
+type sdnList struct {
+	Entries []sdnEntry `xml:"sdnEntry"`
+}
+
+type sdnEntry struct {
+	UID       string    `xml:"uid"`
+	FirstName string    `xml:"firstName"`
+	LastName  string    `xml:"lastName"`
+	Type      string    `xml:"sdnType"`
+	Programs  []string  `xml:"programList>program"`
+	Akas      []sdnName `xml:"akaList>aka"`
+}
+
+type sdnName struct {
+	FirstName string `xml:"firstName"`
+	LastName  string `xml:"lastName"`
+}
+
+func (n sdnName) full() string {
+	return strings.TrimSpace(n.FirstName + " " + n.LastName)
+}
+
+// readXML reads an SDN style list. Namespaces are ignored, so both the
+// published files and hand-written ones without xmlns are accepted.
+func readXML(r io.Reader) ([]Entry, error) {
+	var list sdnList
+	if err := xml.NewDecoder(r).Decode(&list); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(list.Entries))
+	for i, e := range list.Entries {
+		entry := Entry{
+			UID:      strings.TrimSpace(e.UID),
+			Name:     sdnName{FirstName: e.FirstName, LastName: e.LastName}.full(),
+			Type:     strings.TrimSpace(e.Type),
+			Programs: e.Programs,
+		}
+		for _, aka := range e.Akas {
+			if name := aka.full(); name != "" {
+				entry.Aliases = append(entry.Aliases, name)
+			}
+		}
+		if entry.UID == "" || entry.Name == "" {
+			return nil, fmt.Errorf("sdnEntry %d: uid and name are required", i+1)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
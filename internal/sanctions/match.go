@@ -0,0 +1,184 @@
+package sanctions
+
+import (
+	"strings"
+)
+
+// minTokenSimilarity is the least similar two tokens may be and still
+// count towards a name match
+const minTokenSimilarity = 0.8
+
+// Match is a listed name similar to a screened one
+type Match struct {
+	Entry       Entry
+	MatchedName string
+	Score       float64
+}
+
+// nameTokens is a listed name, normalised once when the list is loaded
+type nameTokens struct {
+	name   string
+	tokens []string
+}
+
+// Matcher finds listed names similar to a given one
+type Matcher struct {
+	entries  []Entry
+	names    [][]nameTokens
+	minScore float64
+}
+
+// NewMatcher indexes entries; names scoring at least minScore (0 to 1)
+// are reported as matches
+func NewMatcher(entries []Entry, minScore float64) *Matcher {
+	m := &Matcher{
+		entries:  entries,
+		names:    make([][]nameTokens, len(entries)),
+		minScore: minScore,
+	}
+
+	for i, e := range entries {
+		for _, name := range e.Names() {
+			if tokens := Normalize(name); len(tokens) > 0 {
+				m.names[i] = append(m.names[i], nameTokens{name: name, tokens: tokens})
+			}
+		}
+	}
+
+	return m
+}
+
+// Len returns the number of listed entries
+func (m *Matcher) Len() int {
+	return len(m.entries)
+}
+
+// Match returns the entries with a name similar to name, reporting each
+// entry once with its best scoring name
+func (m *Matcher) Match(name string) []Match {
+	query := Normalize(name)
+	if len(query) == 0 {
+		return nil
+	}
+
+	var matches []Match
+	for i, names := range m.names {
+		best := Match{Entry: m.entries[i]}
+		for _, n := range names {
+			if score := tokenSimilarity(query, n.tokens); score > best.Score {
+				best.Score = score
+				best.MatchedName = n.name
+			}
+		}
+		if best.Score >= m.minScore {
+			matches = append(matches, best)
+		}
+	}
+
+	return matches
+}
+
+// tokenSimilarity compares two names token by token, ignoring order. Each
+// token of the shorter name is paired with its most similar unused token
+// in the longer one. Extra tokens, such as a middle name missing from one
+// side, cost a little; a single token is not enough to match a longer
+// name, since surnames alone are shared by too many people.
+func tokenSimilarity(a, b []string) float64 {
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	if len(short) == 1 && len(long) > 1 {
+		return 0
+	}
+
+	used := make([]bool, len(long))
+	var sum float64
+
+	for _, s := range short {
+		bestScore, bestIdx := 0.0, -1
+		for j, l := range long {
+			if used[j] {
+				continue
+			}
+			if score := jaroWinkler(s, l); score > bestScore {
+				bestScore, bestIdx = score, j
+			}
+		}
+		if bestIdx < 0 || bestScore < minTokenSimilarity {
+			return 0
+		}
+		used[bestIdx] = true
+		sum += bestScore
+	}
+
+	coverage := float64(len(short)) / float64(len(long))
+	return sum / float64(len(short)) * (0.9 + 0.1*coverage)
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of a and b, from 0 for
+// nothing in common to 1 for equal strings
+func jaroWinkler(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ar, br := []rune(a), []rune(b)
+	if len(ar) == 0 || len(br) == 0 {
+		return 0
+	}
+
+	window := max(len(ar), len(br))/2 - 1
+	window = max(window, 0)
+
+	aMatched := make([]bool, len(ar))
+	bMatched := make([]bool, len(br))
+
+	matches := 0
+	for i, r := range ar {
+		lo := max(0, i-window)
+		hi := min(len(br), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !bMatched[j] && br[j] == r {
+				aMatched[i], bMatched[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// Count matched characters that are out of order
+	transpositions := 0
+	j := 0
+	for i, r := range ar {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[j] {
+			j++
+		}
+		if r != br[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ar)) + m/float64(len(br)) + (m-float64(transpositions)/2)/m) / 3
+
+	// Boost names sharing a prefix of up to four characters
+	prefix := 0
+	for prefix < min(4, len(ar), len(br)) && ar[prefix] == br[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// normalizedKey is the cache key for a screened name
+func normalizedKey(name string) string {
+	return strings.Join(Normalize(name), " ")
+}
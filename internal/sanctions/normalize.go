@@ -0,0 +1,80 @@
+package sanctions
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// translit spells letters without an ASCII decomposition the way they are
+// usually romanised on sanctions lists
+var translit = map[rune]string{
+	// Latin letters that do not decompose
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d",
+	'þ': "th", 'ı': "i", 'ħ': "h",
+
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// honorifics carry no identifying information
+var honorifics = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "miss": true, "dr": true,
+	"sir": true, "prof": true,
+}
+
+// Normalize reduces a name to lower-case tokens: accents are stripped,
+// Cyrillic and Greek are transliterated to ASCII and punctuation splits
+// tokens, so "Müller-Lüdenscheidt" and "MULLER LUDENSCHEIDT" compare equal
+func Normalize(name string) []string {
+	var b strings.Builder
+
+	for _, r := range norm.NFKD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// combining accent left over from decomposition
+		case translit[r] != "":
+			b.WriteString(translit[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// scripts without a transliteration are compared as written
+			b.WriteRune(r)
+		case r == '\'' || r == '’':
+			// O'Brien and O’Brien are obrien
+		default:
+			b.WriteByte(' ')
+		}
+	}
+
+	var tokens []string
+	initials := false
+	for _, tok := range strings.Fields(b.String()) {
+		if honorifics[tok] {
+			initials = false
+			continue
+		}
+
+		// Runs of single letters are one initialism: L.L.C. is llc
+		single := len([]rune(tok)) == 1
+		if single && initials {
+			tokens[len(tokens)-1] += tok
+			continue
+		}
+		initials = single
+
+		tokens = append(tokens, tok)
+	}
+
+	return tokens
+}
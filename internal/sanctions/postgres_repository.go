@@ -0,0 +1,175 @@
+package sanctions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) CreateAlert(
+	ctx context.Context,
+	tx *sql.Tx,
+	alert *Alert,
+) (bool, error) {
+
+	query := `
+	INSERT INTO sanctions_alerts
+	(account_number, screened_name, trigger, request_id, entry_uid, entry_name, matched_name, programs, score)
+	SELECT $1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9
+	WHERE NOT EXISTS (
+		SELECT 1
+		FROM sanctions_alerts
+		WHERE account_number = $1
+		  AND entry_uid = $5
+		  AND (status IN ('open', 'confirmed') OR (status = 'dismissed' AND screened_name = $2))
+	)
+	ON CONFLICT DO NOTHING
+	RETURNING id, status, created_at
+	`
+
+	err := tx.QueryRowContext(
+		ctx,
+		query,
+		alert.AccountNumber,
+		alert.ScreenedName,
+		alert.Trigger,
+		alert.RequestID,
+		alert.EntryUID,
+		alert.EntryName,
+		alert.MatchedName,
+		alert.Programs,
+		alert.Score,
+	).Scan(&alert.ID, &alert.Status, &alert.CreatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+const alertColumns = `
+	id, account_number, screened_name, trigger, COALESCE(request_id, ''),
+	entry_uid, entry_name, matched_name, programs, score, status,
+	COALESCE(resolved_by, ''), COALESCE(note, ''), resolved_at, created_at
+	`
+
+func (r *PostgresRepository) ListAlerts(
+	ctx context.Context,
+	status string,
+	limit int,
+) ([]Alert, error) {
+
+	query := `
+	SELECT ` + alertColumns + `
+	FROM sanctions_alerts
+	WHERE ($1 = '' OR status = $1)
+	ORDER BY created_at DESC, id DESC
+	LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []Alert{}
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+func (r *PostgresRepository) ResolveAlert(
+	ctx context.Context,
+	id int64,
+	status string,
+	principal string,
+	note string,
+) (*Alert, error) {
+
+	query := `
+	UPDATE sanctions_alerts
+	SET status = $1,
+		resolved_by = $2,
+		note = NULLIF($3, ''),
+		resolved_at = now()
+	WHERE id = $4
+	  AND status = 'open'
+	RETURNING ` + alertColumns
+
+	alert, err := scanAlert(r.db.QueryRowContext(ctx, query, status, principal, note, id))
+	if !errors.Is(err, sql.ErrNoRows) {
+		return alert, err
+	}
+
+	// Distinguish a missing alert from one already resolved
+	var exists bool
+	err = r.db.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM sanctions_alerts WHERE id = $1)`,
+		id,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrAlertNotOpen
+	}
+	return nil, ErrAlertNotFound
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAlert(row rowScanner) (*Alert, error) {
+	var (
+		alert      Alert
+		resolvedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&alert.ID,
+		&alert.AccountNumber,
+		&alert.ScreenedName,
+		&alert.Trigger,
+		&alert.RequestID,
+		&alert.EntryUID,
+		&alert.EntryName,
+		&alert.MatchedName,
+		&alert.Programs,
+		&alert.Score,
+		&alert.Status,
+		&alert.ResolvedBy,
+		&alert.Note,
+		&resolvedAt,
+		&alert.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolvedAt.Valid {
+		alert.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &alert, nil
+}
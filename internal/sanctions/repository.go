@@ -0,0 +1,21 @@
+package sanctions
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Repository stores alerts
+type Repository interface {
+
+	// Record an alert unless the account already has an open or
+	// confirmed alert for the entry, or one dismissed for the same name;
+	// reports whether it was recorded (inside transaction)
+	CreateAlert(ctx context.Context, tx *sql.Tx, alert *Alert) (bool, error)
+
+	// List alerts newest first, optionally by status
+	ListAlerts(ctx context.Context, status string, limit int) ([]Alert, error)
+
+	// Close an open alert as dismissed or confirmed
+	ResolveAlert(ctx context.Context, id int64, status, principal, note string) (*Alert, error)
+}
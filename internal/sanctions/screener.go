@@ -0,0 +1,144 @@
+package sanctions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"gopherpay/internal/wallet"
+)
+
+// maxCached bounds the match cache; it is emptied when full
+const maxCached = 10000
+
+// Screener is the list-based wallet.SanctionsScreener. Every new match is
+// recorded as an alert; with freeze set the account is also frozen.
+type Screener struct {
+	repo    Repository
+	matcher *Matcher
+	freeze  bool
+
+	// Names screened on every transfer rarely change, so matches are
+	// cached by normalised name
+	mu    sync.Mutex
+	cache map[string][]Match
+}
+
+// NewScreener screens against entries, alerting on names scoring at least
+// minScore (0 to 1)
+func NewScreener(repo Repository, entries []Entry, minScore float64, freeze bool) *Screener {
+	return &Screener{
+		repo:    repo,
+		matcher: NewMatcher(entries, minScore),
+		freeze:  freeze,
+		cache:   make(map[string][]Match),
+	}
+}
+
+// NewScreenerFromFile loads the list at path; minScorePercent is the
+// match threshold from 1 to 100
+func NewScreenerFromFile(repo Repository, path string, minScorePercent int, freeze bool) (*Screener, error) {
+	if minScorePercent < 1 || minScorePercent > 100 {
+		return nil, fmt.Errorf("minimum score must be between 1 and 100, got %d", minScorePercent)
+	}
+
+	entries, err := LoadList(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewScreener(repo, entries, float64(minScorePercent)/100, freeze), nil
+}
+
+// Len returns the number of listed entries
+func (s *Screener) Len() int {
+	return s.matcher.Len()
+}
+
+// Check matches a name against the list without recording anything
+func (s *Screener) Check(name string) []Match {
+	key := normalizedKey(name)
+
+	s.mu.Lock()
+	matches, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok {
+		return matches
+	}
+
+	matches = s.matcher.Match(name)
+
+	s.mu.Lock()
+	if len(s.cache) >= maxCached {
+		clear(s.cache)
+	}
+	s.cache[key] = matches
+	s.mu.Unlock()
+
+	return matches
+}
+
+// Screen records an alert for every match not alerted on before
+func (s *Screener) Screen(ctx context.Context, tx *sql.Tx, subject wallet.SanctionsSubject) (*wallet.SanctionsResult, error) {
+	matches := s.Check(subject.Name)
+	result := &wallet.SanctionsResult{Matches: len(matches)}
+
+	for _, m := range matches {
+		alert := &Alert{
+			AccountNumber: subject.AccountNumber,
+			ScreenedName:  subject.Name,
+			Trigger:       subject.Trigger,
+			RequestID:     subject.RequestID,
+			EntryUID:      m.Entry.UID,
+			EntryName:     m.Entry.Name,
+			MatchedName:   m.MatchedName,
+			Programs:      strings.Join(m.Entry.Programs, ";"),
+			Score:         m.Score,
+		}
+
+		created, err := s.repo.CreateAlert(ctx, tx, alert)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			continue
+		}
+
+		result.NewAlerts++
+		slog.Warn("sanctions list match",
+			"account_number", subject.AccountNumber,
+			"trigger", subject.Trigger,
+			"entry_uid", m.Entry.UID,
+			"matched_name", m.MatchedName,
+			"score", m.Score,
+		)
+	}
+
+	result.Freeze = s.freeze && result.NewAlerts > 0
+
+	return result, nil
+}
+
+// ListAlerts returns up to limit alerts newest first; an empty status
+// lists all of them
+func (s *Screener) ListAlerts(ctx context.Context, status string, limit int) ([]Alert, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListAlerts(ctx, status, limit)
+}
+
+// ResolveAlert closes an open alert. Dismissing does not unfreeze the
+// account; that is a separate, deliberate step.
+func (s *Screener) ResolveAlert(ctx context.Context, id int64, status, principal, note string) (*Alert, error) {
+	if principal == "" {
+		return nil, wallet.ErrPrincipalRequired
+	}
+	if status != AlertDismissed && status != AlertConfirmed {
+		return nil, fmt.Errorf("alerts are resolved as %s or %s", AlertDismissed, AlertConfirmed)
+	}
+	return s.repo.ResolveAlert(ctx, id, status, principal, note)
+}
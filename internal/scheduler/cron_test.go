@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseCron(t *testing.T) {
+	valid := []string{
+		"*/15 * * * *",
+		"0 2 1 * *",
+		"@daily",
+		"@MONTHLY",
+		"0 9 * * MON-FRI",
+		"0 0 * * 7",
+		"5/20 0-6 * JAN,JUL *",
+		"0 0 29 2 *", // only in leap years, still within five years
+	}
+	for _, expr := range valid {
+		if _, err := ParseCron(expr); err != nil {
+			t.Errorf("ParseCron(%q) = %v, want nil", expr, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"0 24 * * *",
+		"0 0 0 * *",
+		"0 0 * 13 *",
+		"0 0 * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"0 0 * FOO *",
+		"@fortnightly",
+		// Valid fields that never meet
+		"0 0 30 2 *",
+		"0 0 31 4,6,9,11 *",
+	}
+	for _, expr := range invalid {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) = nil, want an error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	utc := func(y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"step", "*/15 * * * *", utc(2026, 3, 10, 10, 7).Add(30 * time.Second), utc(2026, 3, 10, 10, 15)},
+		{"strictly after", "0 2 * * *", utc(2026, 3, 10, 2, 0), utc(2026, 3, 11, 2, 0)},
+		{"monthly", "0 2 1 * *", utc(2026, 3, 15, 0, 0), utc(2026, 4, 1, 2, 0)},
+		{"year rollover", "@yearly", utc(2026, 12, 31, 23, 59), utc(2027, 1, 1, 0, 0)},
+		{"weekdays skip the weekend", "0 9 * * MON-FRI", utc(2026, 3, 13, 9, 0), utc(2026, 3, 16, 9, 0)},
+		{"7 is Sunday", "0 0 * * 7", utc(2026, 3, 2, 0, 0), utc(2026, 3, 8, 0, 0)},
+		// Both day fields restricted: either matches
+		{"day of month or weekday", "0 0 13 * FRI", utc(2026, 3, 1, 0, 0), utc(2026, 3, 6, 0, 0)},
+		{"leap day", "0 0 29 2 *", utc(2026, 3, 1, 0, 0), utc(2028, 2, 29, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			if got := cron.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCronNextDST covers the 2026 changes in New York: clocks skip from
+// 02:00 to 03:00 on 8 March and repeat 01:00-02:00 on 1 November
+func TestCronNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := func(m time.Month, d, h, min int) time.Time {
+		return time.Date(2026, m, d, h, min, 0, 0, ny)
+	}
+	// Instants in the repeated hour are ambiguous in local time
+	utc := func(m time.Month, d, h, min int) time.Time {
+		return time.Date(2026, m, d, h, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"slot in the skipped hour runs after the gap", "30 2 * * *", local(3, 7, 12, 0), local(3, 8, 3, 0)},
+		{"and at its time the next day", "30 2 * * *", local(3, 8, 3, 0), local(3, 9, 2, 30)},
+		{"slot after the gap is unaffected", "0 3 * * *", local(3, 8, 0, 0), local(3, 8, 3, 0)},
+		{"slot in the repeated hour runs in its first pass", "30 1 * * *", local(11, 1, 0, 0), utc(11, 1, 5, 30)},
+		{"and not again in the second", "30 1 * * *", utc(11, 1, 5, 30), local(11, 2, 1, 30)},
+		{"hourly slots run in both passes", "30 * * * *", utc(11, 1, 5, 30), utc(11, 1, 6, 30)},
+		{"every-minute slots keep running", "*/30 * * * *", utc(11, 1, 5, 30), utc(11, 1, 6, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			if got := cron.Next(tt.from.In(ny)); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from.In(ny), got, tt.want.In(ny))
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// Report periods, relative to the time a run was scheduled for
+const (
+	PeriodPreviousDay   = "previous_day"
+	PeriodPreviousWeek  = "previous_week"
+	PeriodPreviousMonth = "previous_month"
+	PeriodAll           = "all"
+)
+
+// Run outcomes
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// Schedule is a recurring bulk statement job, e.g. "monthly statement for
+// all accounts on the 1st at 02:00" is CronExpr "0 2 1 * *" with
+// Period previous_month.
+type Schedule struct {
+	ID            int64
+	Name          string
+	CronExpr      string
+	Format        string
+	AccountFilter string
+	Period        string
+	Enabled       bool
+	NextRunAt     *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Run is one execution of a schedule
+type Run struct {
+	ID           int64
+	ScheduleID   int64
+	ScheduledFor time.Time
+	StartedAt    time.Time
+	FinishedAt   *time.Time
+	Status       string
+	Statements   int
+	OutputDir    string
+	Error        string
+}
+
+// PeriodBounds returns the statement period [from, to) for a run scheduled
+// at t, computed in t's location
+func PeriodBounds(period string, t time.Time) (time.Time, time.Time, error) {
+	loc := t.Location()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+
+	switch period {
+	case PeriodPreviousDay:
+		return midnight.AddDate(0, 0, -1), midnight, nil
+	case PeriodPreviousWeek:
+		return midnight.AddDate(0, 0, -7), midnight, nil
+	case PeriodPreviousMonth:
+		firstOfMonth := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		return firstOfMonth.AddDate(0, -1, 0), firstOfMonth, nil
+	case PeriodAll:
+		return time.Time{}, t, nil
+	default:
+		return time.Time{}, time.Time{}, errors.New("period must be previous_day, previous_week, previous_month or all")
+	}
+}
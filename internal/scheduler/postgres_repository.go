@@ -0,0 +1,268 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"hash/fnv"
+	"time"
+)
+
+// advisoryLockClass namespaces the advisory locks taken by the scheduler
+// so they cannot collide with other users of pg_advisory_lock
+const advisoryLockClass = 0x47505343 // "GPSC"
+
+// advisoryLockKey hashes the lock class and a schedule ID into the bigint
+// key of pg_try_advisory_lock. The two-key form takes int4 keys, which
+// would fold IDs above math.MaxInt32 onto other schedules.
+func advisoryLockKey(scheduleID int64) int64 {
+	var b []byte
+	b = binary.BigEndian.AppendUint32(b, advisoryLockClass)
+	b = binary.BigEndian.AppendUint64(b, uint64(scheduleID))
+
+	h := fnv.New64a()
+	h.Write(b)
+	return int64(h.Sum64())
+}
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) ListSchedules(ctx context.Context) ([]Schedule, error) {
+
+	query := `
+	SELECT id, name, cron_expr, format, account_filter, period, enabled,
+	       next_run_at, created_at, updated_at
+	FROM report_schedules
+	ORDER BY name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+	for rows.Next() {
+		var (
+			sch     Schedule
+			nextRun sql.NullTime
+		)
+
+		if err := rows.Scan(
+			&sch.ID,
+			&sch.Name,
+			&sch.CronExpr,
+			&sch.Format,
+			&sch.AccountFilter,
+			&sch.Period,
+			&sch.Enabled,
+			&nextRun,
+			&sch.CreatedAt,
+			&sch.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if nextRun.Valid {
+			t := nextRun.Time
+			sch.NextRunAt = &t
+		}
+		schedules = append(schedules, sch)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (r *PostgresRepository) CreateSchedule(ctx context.Context, sch *Schedule) error {
+
+	query := `
+	INSERT INTO report_schedules
+	(name, cron_expr, format, account_filter, period, enabled, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, now(), now())
+	RETURNING id
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		sch.Name,
+		sch.CronExpr,
+		sch.Format,
+		sch.AccountFilter,
+		sch.Period,
+		sch.Enabled,
+	).Scan(&sch.ID)
+}
+
+func (r *PostgresRepository) DeleteSchedule(ctx context.Context, name string) error {
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM report_schedules WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) SetNextRun(ctx context.Context, scheduleID int64, next time.Time) error {
+
+	query := `
+	UPDATE report_schedules
+	SET next_run_at = $1,
+	    updated_at = now()
+	WHERE id = $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, next.UTC(), scheduleID)
+	return err
+}
+
+// TryLock takes a session-level advisory lock. Session locks belong to one
+// connection, so a dedicated connection is held out of the pool until
+// unlock releases both.
+func (r *PostgresRepository) TryLock(ctx context.Context, scheduleID int64) (func(), bool, error) {
+
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := advisoryLockKey(scheduleID)
+
+	var locked bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked)
+
+	if err != nil || !locked {
+		conn.Close()
+		return nil, false, err
+	}
+
+	unlock := func() {
+		// Use a fresh context: the run's context may already be cancelled
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, key)
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
+
+func (r *PostgresRepository) StartRun(ctx context.Context, run *Run) (bool, error) {
+
+	query := `
+	INSERT INTO report_schedule_runs
+	(schedule_id, scheduled_for, started_at, status, output_dir)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (schedule_id, scheduled_for) DO NOTHING
+	RETURNING id
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		run.ScheduleID,
+		run.ScheduledFor.UTC(),
+		run.StartedAt.UTC(),
+		run.Status,
+		run.OutputDir,
+	).Scan(&run.ID)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *PostgresRepository) FinishRun(ctx context.Context, run *Run) error {
+
+	query := `
+	UPDATE report_schedule_runs
+	SET finished_at = $1,
+	    status = $2,
+	    statements = $3,
+	    error = $4
+	WHERE id = $5
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		run.FinishedAt.UTC(),
+		run.Status,
+		run.Statements,
+		run.Error,
+		run.ID,
+	)
+
+	return err
+}
+
+func (r *PostgresRepository) ListRuns(ctx context.Context, scheduleName string, limit int) ([]Run, error) {
+
+	query := `
+	SELECT r.id, r.schedule_id, r.scheduled_for, r.started_at, r.finished_at,
+	       r.status, r.statements, r.output_dir, r.error
+	FROM report_schedule_runs r
+	JOIN report_schedules s ON s.id = r.schedule_id
+	WHERE $1 = '' OR s.name = $1
+	ORDER BY r.started_at DESC, r.id DESC
+	LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, scheduleName, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var (
+			run      Run
+			finished sql.NullTime
+		)
+
+		if err := rows.Scan(
+			&run.ID,
+			&run.ScheduleID,
+			&run.ScheduledFor,
+			&run.StartedAt,
+			&finished,
+			&run.Status,
+			&run.Statements,
+			&run.OutputDir,
+			&run.Error,
+		); err != nil {
+			return nil, err
+		}
+
+		if finished.Valid {
+			t := finished.Time
+			run.FinishedAt = &t
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
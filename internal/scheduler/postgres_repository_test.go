@@ -0,0 +1,24 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAdvisoryLockKey(t *testing.T) {
+	// IDs that the old int4 keys truncated onto the same lock
+	ids := []int64{1, 2, math.MaxInt32, math.MaxInt32 + 1, 1<<32 + 1, 1<<32 + 2, math.MaxInt64}
+
+	seen := make(map[int64]int64)
+	for _, id := range ids {
+		key := advisoryLockKey(id)
+		if other, ok := seen[key]; ok {
+			t.Errorf("schedules %d and %d share lock key %d", other, id, key)
+		}
+		seen[key] = id
+
+		if again := advisoryLockKey(id); again != key {
+			t.Errorf("lock key of schedule %d is not stable: %d, then %d", id, key, again)
+		}
+	}
+}
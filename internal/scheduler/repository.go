@@ -0,0 +1,35 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+
+	// List schedules, enabled and disabled
+	ListSchedules(ctx context.Context) ([]Schedule, error)
+
+	// Create a schedule; sets ID
+	CreateSchedule(ctx context.Context, sch *Schedule) error
+
+	// Delete a schedule and its run history by name
+	DeleteSchedule(ctx context.Context, name string) error
+
+	// Set when a schedule is next due
+	SetNextRun(ctx context.Context, scheduleID int64, next time.Time) error
+
+	// Try to take the cluster-wide lock for a schedule without waiting.
+	// When ok is true, unlock must be called once the run is over.
+	TryLock(ctx context.Context, scheduleID int64) (unlock func(), ok bool, err error)
+
+	// Claim a schedule slot by inserting a running run. Returns false if
+	// the slot was already claimed.
+	StartRun(ctx context.Context, run *Run) (bool, error)
+
+	// Record the outcome of a run
+	FinishRun(ctx context.Context, run *Run) error
+
+	// List the most recent runs, optionally for one schedule
+	ListRuns(ctx context.Context, scheduleName string, limit int) ([]Run, error)
+}
@@ -0,0 +1,213 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"time"
+
+	"gopherpay/internal/billing"
+)
+
+// Scheduler runs due report schedules in the background. Several server
+// instances may run a Scheduler against the same database: a Postgres
+// advisory lock per schedule and the unique (schedule, slot) run row make
+// sure each slot is executed once.
+type Scheduler struct {
+	repo     Repository
+	reports  *billing.ReportService
+	store    billing.ReportStore
+	interval time.Duration
+	workers  int
+	location *time.Location
+}
+
+func NewScheduler(
+	repo Repository,
+	reports *billing.ReportService,
+	store billing.ReportStore,
+	interval time.Duration,
+	workers int,
+) *Scheduler {
+
+	return &Scheduler{
+		repo:     repo,
+		reports:  reports,
+		store:    store,
+		interval: interval,
+		workers:  workers,
+		location: time.Local,
+	}
+}
+
+// Start polls for due schedules every interval until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+
+	go func() {
+
+		slog.Info("scheduler started", "interval", s.interval)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.tick(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// tick runs every schedule that is due. Schedules run one after another;
+// each is a bulk job with its own worker pool.
+func (s *Scheduler) tick(ctx context.Context) {
+
+	schedules, err := s.repo.ListSchedules(ctx)
+	if err != nil {
+		slog.Error("scheduler: list schedules failed", "error", err)
+		return
+	}
+
+	now := time.Now().In(s.location)
+
+	for _, sch := range schedules {
+		if !sch.Enabled {
+			continue
+		}
+
+		cron, err := ParseCron(sch.CronExpr)
+		if err != nil {
+			slog.Error("scheduler: invalid cron expression", "schedule", sch.Name, "error", err)
+			continue
+		}
+
+		// An expression stored before ParseCron rejected those that never
+		// match has no next slot; the schedule cannot run
+		next := cron.Next(now)
+		if next.IsZero() {
+			slog.Error("scheduler: cron expression never matches", "schedule", sch.Name, "cron", sch.CronExpr)
+			continue
+		}
+
+		// A new schedule is first due at its next slot, not immediately
+		if sch.NextRunAt == nil {
+			if err := s.repo.SetNextRun(ctx, sch.ID, next); err != nil {
+				slog.Error("scheduler: set next run failed", "schedule", sch.Name, "error", err)
+			}
+			continue
+		}
+
+		if sch.NextRunAt.After(now) {
+			continue
+		}
+
+		s.runLocked(ctx, sch, next)
+	}
+}
+
+// runLocked executes one due slot of sch while holding its advisory lock;
+// next is the slot after now
+func (s *Scheduler) runLocked(ctx context.Context, sch Schedule, next time.Time) {
+
+	unlock, ok, err := s.repo.TryLock(ctx, sch.ID)
+	if err != nil {
+		slog.Error("scheduler: lock failed", "schedule", sch.Name, "error", err)
+		return
+	}
+	if !ok {
+		// Another instance is running it
+		return
+	}
+	defer unlock()
+
+	slot := sch.NextRunAt.In(s.location)
+
+	// Missed slots (e.g. while every instance was down) collapse into
+	// this one run; the next slot is computed from now
+	if err := s.repo.SetNextRun(ctx, sch.ID, next); err != nil {
+		slog.Error("scheduler: set next run failed", "schedule", sch.Name, "error", err)
+		return
+	}
+
+	dir := path.Join("scheduled", path.Base(sch.Name), slot.Format("20060102T1504"))
+
+	run := &Run{
+		ScheduleID:   sch.ID,
+		ScheduledFor: slot,
+		StartedAt:    time.Now(),
+		Status:       RunRunning,
+		OutputDir:    s.store.Location(dir),
+	}
+
+	claimed, err := s.repo.StartRun(ctx, run)
+	if err != nil {
+		slog.Error("scheduler: start run failed", "schedule", sch.Name, "error", err)
+		return
+	}
+	if !claimed {
+		// Another instance finished this slot between our read and the lock
+		return
+	}
+
+	slog.Info("scheduled report started", "schedule", sch.Name, "slot", slot, "output_dir", run.OutputDir)
+
+	statements, err := s.execute(ctx, sch, slot, dir)
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Statements = statements
+	run.Status = RunSucceeded
+	if err != nil {
+		run.Status = RunFailed
+		run.Error = err.Error()
+	}
+
+	// Record the outcome even if the server is shutting down
+	recordCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.repo.FinishRun(recordCtx, run); err != nil {
+		slog.Error("scheduler: record run failed", "schedule", sch.Name, "error", err)
+	}
+
+	if run.Status == RunFailed {
+		slog.Error("scheduled report failed", "schedule", sch.Name, "slot", slot, "error", run.Error)
+		return
+	}
+
+	slog.Info("scheduled report completed",
+		"schedule", sch.Name,
+		"slot", slot,
+		"statements", statements,
+	)
+}
+
+// execute generates the statements for one slot of sch into dir
+func (s *Scheduler) execute(ctx context.Context, sch Schedule, slot time.Time, dir string) (int, error) {
+
+	from, to, err := PeriodBounds(sch.Period, slot)
+	if err != nil {
+		return 0, fmt.Errorf("schedule %s: %w", sch.Name, err)
+	}
+
+	manifest, err := s.reports.GenerateAll(ctx, billing.BulkOptions{
+		Store:   s.store,
+		Dir:     dir,
+		Filter:  sch.AccountFilter,
+		Format:  sch.Format,
+		From:    from.UTC(),
+		To:      to.UTC(),
+		Workers: s.workers,
+	})
+
+	if manifest == nil {
+		return 0, err
+	}
+
+	return len(manifest.Entries), err
+}
@@ -0,0 +1,132 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// KindAdjustment is a manual balance correction, booked against the
+// adjustment account so the ledger still balances
+const KindAdjustment = "adjustment"
+
+var (
+	ErrAdjustmentsDisabled       = errors.New("balance adjustments are disabled")
+	ErrAdjustmentAccountNotFound = errors.New("adjustment account not found")
+	ErrAdjustmentAccount         = errors.New("the adjustment account cannot be adjusted")
+	ErrAdjustmentAmount          = errors.New("amount must be a non-zero integer (cents)")
+	ErrAdjustmentReasonRequired  = errors.New("a reason is required to adjust a balance")
+	ErrAdjustmentOverdraw        = errors.New("adjustment would make the balance negative")
+)
+
+// Adjustment is a booked balance correction. A positive Amount credits
+// the account, a negative one debits it.
+type Adjustment struct {
+	AccountNumber string `json:"account_number"`
+	Amount        int64  `json:"amount"`
+	BalanceBefore int64  `json:"balance_before"`
+	Balance       int64  `json:"balance"`
+	RequestID     string `json:"request_id"`
+	AdjustedBy    string `json:"adjusted_by"`
+	Reason        string `json:"reason"`
+}
+
+// AdjustBalance credits or debits an account by amount cents. The money
+// comes from or goes to the adjustment account, and the movement is
+// recorded as an adjustment transaction under requestID, so statements
+// and the hash chain cover it like any transfer. Limits, fees and
+// screening do not apply.
+func (s *WalletService) AdjustBalance(
+	ctx context.Context,
+	accountNumber string,
+	amount int64,
+	principal string,
+	reason string,
+	requestID string,
+) (*Adjustment, error) {
+
+	if s.adjustmentAccount == "" {
+		return nil, ErrAdjustmentsDisabled
+	}
+	if principal == "" {
+		return nil, ErrPrincipalRequired
+	}
+	if reason == "" {
+		return nil, ErrAdjustmentReasonRequired
+	}
+	if amount == 0 {
+		return nil, ErrAdjustmentAmount
+	}
+	if accountNumber == s.adjustmentAccount {
+		return nil, ErrAdjustmentAccount
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	acc, err := s.repo.GetAccountByNumberTx(ctx, tx, accountNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if acc.ErasedAt != nil {
+		return nil, ErrAccountErased
+	}
+
+	house, err := s.repo.GetAccountByNumberTx(ctx, tx, s.adjustmentAccount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrAdjustmentAccountNotFound, s.adjustmentAccount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err := s.lockAccounts(ctx, tx, acc.ID, house.ID)
+	if err != nil {
+		return nil, err
+	}
+	accLocked, houseLocked := locked[acc.ID], locked[house.ID]
+
+	if accLocked.Balance+amount < 0 {
+		return nil, ErrAdjustmentOverdraw
+	}
+
+	// The adjustment account is the counterparty; its balance may go
+	// negative, as it stands for money entering or leaving the system
+	fromID, toID, value := houseLocked.ID, accLocked.ID, amount
+	if amount < 0 {
+		fromID, toID, value = accLocked.ID, houseLocked.ID, -amount
+	}
+
+	if err := s.repo.UpdateBalance(ctx, tx, accLocked.ID, accLocked.Balance+amount); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateBalance(ctx, tx, houseLocked.ID, houseLocked.Balance-amount); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.CreateTransaction(ctx, tx, fromID, toID, value, KindAdjustment, "completed", "", requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &Adjustment{
+		AccountNumber: acc.AccountNumber,
+		Amount:        amount,
+		BalanceBefore: accLocked.Balance,
+		Balance:       accLocked.Balance + amount,
+		RequestID:     requestID,
+		AdjustedBy:    principal,
+		Reason:        reason,
+	}, nil
+}
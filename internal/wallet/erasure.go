@@ -0,0 +1,117 @@
+package wallet
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrAccountErased is returned for changes to an account whose holder
+	// data has been erased
+	ErrAccountErased = errors.New("account has been erased")
+
+	ErrErasureBalance        = errors.New("account balance must be zero before erasure")
+	ErrErasureReasonRequired = errors.New("a reason is required to erase an account")
+)
+
+// Erasure records that an account holder's PII was pseudonymized. It
+// says who did it and why, never what was erased.
+type Erasure struct {
+	ID            int64     `json:"id"`
+	AccountNumber string    `json:"account_number"`
+	Pseudonym     string    `json:"pseudonym"`
+	ErasedBy      string    `json:"erased_by"`
+	Reason        string    `json:"reason"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// newPseudonym returns a random stand-in for an erased holder's name.
+// It is not derived from the name, so it cannot be traced back to it.
+func newPseudonym() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "erased-" + hex.EncodeToString(b), nil
+}
+
+// EraseAccount pseudonymizes an account holder's PII on principal's
+// authority: the name is replaced with a random pseudonym, email, phone,
+// date of birth and their blind indexes are cleared and the account is
+// frozen. Sanctions alerts get the pseudonym in place of the screened
+// name. The account number, balance history and transactions are kept.
+// Nothing is kept that could restore the data, so it cannot be undone.
+func (s *WalletService) EraseAccount(
+	ctx context.Context,
+	accountNumber string,
+	principal string,
+	reason string,
+) (*Erasure, error) {
+
+	if principal == "" {
+		return nil, ErrPrincipalRequired
+	}
+	if reason == "" {
+		return nil, ErrErasureReasonRequired
+	}
+
+	pseudonym, err := newPseudonym()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	acc, err := s.repo.GetAccountByNumberTx(ctx, tx, accountNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err := s.repo.GetAccountForUpdateByID(ctx, tx, acc.ID)
+	if err != nil {
+		return nil, err
+	}
+	if locked.Balance != 0 {
+		return nil, ErrErasureBalance
+	}
+
+	if err := s.repo.EraseAccountPII(ctx, tx, acc.ID, pseudonym); err != nil {
+		return nil, err
+	}
+	if err := s.repo.PseudonymizeSanctionsAlerts(ctx, tx, accountNumber, pseudonym); err != nil {
+		return nil, err
+	}
+
+	erasure := &Erasure{
+		AccountNumber: accountNumber,
+		Pseudonym:     pseudonym,
+		ErasedBy:      principal,
+		Reason:        reason,
+	}
+	if err := s.repo.CreateErasure(ctx, tx, acc.ID, erasure); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return erasure, nil
+}
+
+// ListErasures returns the erasures of an account
+func (s *WalletService) ListErasures(ctx context.Context, accountNumber string) ([]Erasure, error) {
+	return s.repo.ListErasures(ctx, accountNumber)
+}
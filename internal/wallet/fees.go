@@ -0,0 +1,279 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Fee calculation methods
+const (
+	FeeFlat       = "flat"
+	FeePercentage = "percentage"
+	FeeTiered     = "tiered"
+)
+
+// Transaction kinds; a fee is booked as its own row next to the transfer
+const (
+	KindTransfer = "transfer"
+	KindFee      = "fee"
+)
+
+const (
+	DefaultTier         = "standard"
+	DefaultTransferType = "standard"
+)
+
+// FeeAny matches every tier or transfer type in a FeeRule
+const FeeAny = "*"
+
+// maxRateBps caps a percentage at 100%
+const maxRateBps = 10000
+
+var (
+	ErrRevenueAccountNotFound = errors.New("fee revenue account not found")
+	ErrUnknownTier            = errors.New("unknown account tier")
+	ErrUnknownTransferType    = errors.New("unknown transfer type")
+)
+
+// FeeSchedule prices transfers. Fees are charged to the sender on top of
+// the amount and credited to RevenueAccount. A nil or empty schedule
+// charges nothing.
+type FeeSchedule struct {
+	RevenueAccount string    `json:"revenue_account"`
+	Rules          []FeeRule `json:"rules"`
+}
+
+// FeeRule prices transfers from accounts in Tier of the given Type. An
+// empty or "*" Tier or Type matches anything; the most specific matching
+// rule wins, and the first one listed among equally specific rules.
+//
+// Amounts are in cents and rates in basis points (150 = 1.5%). Min and
+// Max, when non-zero, clamp percentage and tiered fees.
+type FeeRule struct {
+	Tier string `json:"tier,omitempty"`
+	Type string `json:"type,omitempty"`
+	Kind string `json:"kind"`
+
+	Amount  int64     `json:"amount,omitempty"`   // flat
+	RateBps int64     `json:"rate_bps,omitempty"` // percentage
+	Min     int64     `json:"min,omitempty"`
+	Max     int64     `json:"max,omitempty"`
+	Bands   []FeeBand `json:"bands,omitempty"` // tiered
+}
+
+// FeeBand prices transfers up to and including UpTo cents; 0 means no
+// upper bound and must come last. A transfer is priced entirely by the
+// first band it fits in.
+type FeeBand struct {
+	UpTo    int64 `json:"up_to"`
+	Flat    int64 `json:"flat,omitempty"`
+	RateBps int64 `json:"rate_bps,omitempty"`
+}
+
+// Fee is the charge for one transfer. Kind is empty when no rule applied.
+type Fee struct {
+	Amount int64
+	Kind   string
+}
+
+// LoadFeeSchedule reads a JSON fee schedule. An empty path means no fees.
+func LoadFeeSchedule(path string) (*FeeSchedule, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedule FeeSchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := schedule.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &schedule, nil
+}
+
+// Enabled reports whether the schedule can charge anything
+func (s *FeeSchedule) Enabled() bool {
+	return s != nil && len(s.Rules) > 0
+}
+
+// Validate checks the rules and that fees have somewhere to go
+func (s *FeeSchedule) Validate() error {
+	if len(s.Rules) > 0 && s.RevenueAccount == "" {
+		return errors.New("revenue_account is required")
+	}
+
+	for i, rule := range s.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (r FeeRule) validate() error {
+	if r.Amount < 0 || r.RateBps < 0 || r.Min < 0 || r.Max < 0 {
+		return errors.New("amounts and rates must not be negative")
+	}
+	if r.RateBps > maxRateBps {
+		return errors.New("rate_bps must not exceed 10000 (100%)")
+	}
+	if r.Max != 0 && r.Min > r.Max {
+		return errors.New("min must not exceed max")
+	}
+
+	switch r.Kind {
+	case FeeFlat, FeePercentage:
+		return nil
+
+	case FeeTiered:
+		if len(r.Bands) == 0 {
+			return errors.New("tiered rule needs at least one band")
+		}
+		var prev int64
+		for i, band := range r.Bands {
+			if band.Flat < 0 || band.RateBps < 0 {
+				return errors.New("amounts and rates must not be negative")
+			}
+			if band.RateBps > maxRateBps {
+				return errors.New("rate_bps must not exceed 10000 (100%)")
+			}
+			if band.UpTo == 0 && i != len(r.Bands)-1 {
+				return errors.New("only the last band may be unbounded")
+			}
+			if band.UpTo != 0 && band.UpTo <= prev {
+				return errors.New("band limits must increase")
+			}
+			prev = band.UpTo
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown fee kind %q", r.Kind)
+	}
+}
+
+// TransferTypes lists the types a transfer may ask for: the default and
+// every type a rule names. The type picks the fee rule, so clients cannot
+// pass types the schedule does not know.
+func (s *FeeSchedule) TransferTypes() []string {
+	types := []string{DefaultTransferType}
+	if s == nil {
+		return types
+	}
+	for _, rule := range s.Rules {
+		if rule.Type != "" && rule.Type != FeeAny && !slices.Contains(types, rule.Type) {
+			types = append(types, rule.Type)
+		}
+	}
+	return types
+}
+
+// tiers lists the account tiers the rules name
+func (s *FeeSchedule) tiers() []string {
+	var tiers []string
+	if s == nil {
+		return tiers
+	}
+	for _, rule := range s.Rules {
+		if rule.Tier != "" && rule.Tier != FeeAny {
+			tiers = append(tiers, rule.Tier)
+		}
+	}
+	return tiers
+}
+
+// Quote returns the fee for a transfer of amount from an account in tier
+func (s *FeeSchedule) Quote(tier, transferType string, amount int64) Fee {
+	if !s.Enabled() {
+		return Fee{}
+	}
+
+	rule := s.match(tier, transferType)
+	if rule == nil {
+		return Fee{}
+	}
+
+	return Fee{Amount: rule.fee(amount), Kind: rule.Kind}
+}
+
+func (s *FeeSchedule) match(tier, transferType string) *FeeRule {
+	var (
+		best      *FeeRule
+		bestScore = -1
+	)
+
+	for i := range s.Rules {
+		rule := &s.Rules[i]
+
+		score := 0
+		switch rule.Tier {
+		case "", FeeAny:
+		case tier:
+			score += 2 // a tier match outranks a type match
+		default:
+			continue
+		}
+		switch rule.Type {
+		case "", FeeAny:
+		case transferType:
+			score++
+		default:
+			continue
+		}
+
+		if score > bestScore {
+			best, bestScore = rule, score
+		}
+	}
+
+	return best
+}
+
+func (r *FeeRule) fee(amount int64) int64 {
+	var fee int64
+
+	switch r.Kind {
+	case FeeFlat:
+		return r.Amount
+
+	case FeePercentage:
+		fee = basisPoints(amount, r.RateBps)
+
+	case FeeTiered:
+		for _, band := range r.Bands {
+			if band.UpTo == 0 || amount <= band.UpTo {
+				fee = band.Flat + basisPoints(amount, band.RateBps)
+				break
+			}
+		}
+	}
+
+	if r.Min != 0 && fee < r.Min {
+		fee = r.Min
+	}
+	if r.Max != 0 && fee > r.Max {
+		fee = r.Max
+	}
+
+	return fee
+}
+
+// basisPoints returns amount * bps / 10000 rounded half up. Splitting off
+// the remainder keeps the product in range for any amount, given bps is at
+// most maxRateBps.
+func basisPoints(amount, bps int64) int64 {
+	q, rem := amount/10000, amount%10000
+	return q*bps + (rem*bps+5000)/10000
+}
@@ -0,0 +1,154 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// KYC tiers, from least to most verified
+const (
+	KYCUnverified = "unverified"
+	KYCBasic      = "basic"
+	KYCFull       = "full"
+)
+
+var KYCTiers = []string{KYCUnverified, KYCBasic, KYCFull}
+
+// FailureKYCRequired is recorded on transfers refused until the sender
+// completes KYC
+const FailureKYCRequired = "kyc_required"
+
+// LimitMaxBalance caps what an account may hold; KYC limits are reported
+// with a kyc_ prefix, e.g. limit_kyc_max_balance
+const LimitMaxBalance = "max_balance"
+
+var (
+	// ErrKYCRequired is returned for transfers from unverified accounts
+	// above the policy's threshold
+	ErrKYCRequired = errors.New("account must complete KYC verification")
+
+	ErrInvalidKYCTier        = errors.New("invalid KYC tier")
+	ErrKYCDocumentRequired   = errors.New("a KYC document must be on file to verify an account")
+	ErrKYCDocumentIncomplete = errors.New("document type and reference are required")
+)
+
+// KYCLimits caps transfers like Limits and, with MaxBalance, what an
+// account may receive. Zero means unlimited.
+type KYCLimits struct {
+	Limits
+	MaxBalance int64 `json:"max_balance,omitempty"`
+}
+
+// KYCPolicy holds the limits of each KYC tier. Transfers of more than
+// UnverifiedMaxTransfer cents from unverified accounts are refused; 0
+// disables that check. A nil policy limits nothing.
+type KYCPolicy struct {
+	UnverifiedMaxTransfer int64                `json:"unverified_max_transfer"`
+	Tiers                 map[string]KYCLimits `json:"tiers"`
+}
+
+// KYCDocument is the metadata of a verification document; the document
+// itself is kept outside GopherPay
+type KYCDocument struct {
+	ID         int64      `json:"id"`
+	Type       string     `json:"type"`
+	Reference  string     `json:"reference"`
+	Country    string     `json:"country,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RecordedBy string     `json:"recorded_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// KYCChange is one move of an account between tiers
+type KYCChange struct {
+	ID        int64     `json:"id"`
+	FromTier  string    `json:"from_tier"`
+	ToTier    string    `json:"to_tier"`
+	ChangedBy string    `json:"changed_by"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccountKYC is an account's KYC tier with its documents, tier history
+// and the limits of the tier
+type AccountKYC struct {
+	AccountNumber string        `json:"account_number"`
+	Tier          string        `json:"tier"`
+	Limits        KYCLimits     `json:"limits"`
+	Documents     []KYCDocument `json:"documents"`
+	History       []KYCChange   `json:"history"`
+}
+
+// IsKYCTier reports whether tier is a known KYC tier
+func IsKYCTier(tier string) bool {
+	return slices.Contains(KYCTiers, tier)
+}
+
+// LoadKYCPolicy reads a JSON KYC policy. An empty path means no KYC
+// limits.
+func LoadKYCPolicy(path string) (*KYCPolicy, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy KYCPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if policy.UnverifiedMaxTransfer < 0 {
+		return nil, fmt.Errorf("%s: unverified_max_transfer must not be negative", path)
+	}
+	for tier, l := range policy.Tiers {
+		if !IsKYCTier(tier) {
+			return nil, fmt.Errorf("%s: %w %q", path, ErrInvalidKYCTier, tier)
+		}
+		if l.MaxAmount < 0 || l.DailyTotal < 0 || l.MonthlyTotal < 0 || l.HourlyCount < 0 || l.MaxBalance < 0 {
+			return nil, fmt.Errorf("%s: tier %q: limits must not be negative", path, tier)
+		}
+	}
+
+	return &policy, nil
+}
+
+// ForTier returns the limits of a KYC tier
+func (p *KYCPolicy) ForTier(tier string) KYCLimits {
+	if p == nil {
+		return KYCLimits{}
+	}
+	return p.Tiers[tier]
+}
+
+// RequiresVerification reports whether a transfer of amount from an
+// account in tier must be refused until it is verified
+func (p *KYCPolicy) RequiresVerification(tier string, amount int64) bool {
+	return p != nil &&
+		tier == KYCUnverified &&
+		p.UnverifiedMaxTransfer > 0 &&
+		amount > p.UnverifiedMaxTransfer
+}
+
+// Check returns a *LimitError when a transfer of amount on top of usage
+// breaks an outgoing limit, or would take the recipient, holding
+// recipientBalance under recipient's limits, over its maximum balance
+func (l KYCLimits) Check(amount int64, usage LimitUsage, recipient KYCLimits, recipientBalance int64) error {
+	var limitErr *LimitError
+	if errors.As(l.Limits.Check(amount, usage), &limitErr) {
+		return &LimitError{Limit: "kyc_" + limitErr.Limit, Max: limitErr.Max}
+	}
+
+	if recipient.MaxBalance > 0 && recipientBalance+amount > recipient.MaxBalance {
+		return &LimitError{Limit: "kyc_" + LimitMaxBalance, Max: recipient.MaxBalance}
+	}
+
+	return nil
+}
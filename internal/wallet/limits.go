@@ -0,0 +1,172 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrLimitExceeded is wrapped by every LimitError
+var ErrLimitExceeded = errors.New("transfer limit exceeded")
+
+// Limit names, as used in LimitError and failure reasons
+const (
+	LimitMaxAmount    = "max_amount"
+	LimitDailyTotal   = "daily_total"
+	LimitMonthlyTotal = "monthly_total"
+	LimitHourlyCount  = "hourly_count"
+)
+
+// Limits caps an account's outgoing transfers. Zero means unlimited.
+// Totals count transfer amounts, not fees; days and months are calendar
+// periods in database time and the hourly count is a rolling hour.
+type Limits struct {
+	MaxAmount    int64 `json:"max_amount,omitempty"`
+	DailyTotal   int64 `json:"daily_total,omitempty"`
+	MonthlyTotal int64 `json:"monthly_total,omitempty"`
+	HourlyCount  int64 `json:"hourly_count,omitempty"`
+}
+
+// LimitOverride replaces some of an account's tier limits. A nil field
+// inherits the tier limit; 0 lifts it.
+type LimitOverride struct {
+	MaxAmount    *int64 `json:"max_amount"`
+	DailyTotal   *int64 `json:"daily_total"`
+	MonthlyTotal *int64 `json:"monthly_total"`
+	HourlyCount  *int64 `json:"hourly_count"`
+}
+
+// Validate rejects negative limits
+func (o *LimitOverride) Validate() error {
+	for _, v := range []*int64{o.MaxAmount, o.DailyTotal, o.MonthlyTotal, o.HourlyCount} {
+		if v != nil && *v < 0 {
+			return errors.New("limits must not be negative")
+		}
+	}
+	return nil
+}
+
+// LimitUsage is what an account has already sent in the current periods
+type LimitUsage struct {
+	DailyTotal   int64
+	MonthlyTotal int64
+	HourlyCount  int64
+}
+
+// LimitPolicy holds the limits for each account tier; the "*" tier
+// applies to tiers without their own entry. A nil policy limits nothing.
+type LimitPolicy struct {
+	Tiers map[string]Limits `json:"tiers"`
+}
+
+// LimitError reports which limit a transfer would exceed
+type LimitError struct {
+	Limit string
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s is %d", ErrLimitExceeded, e.Limit, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// FailureReason is recorded on the failed transaction, e.g.
+// "limit_daily_total"
+func (e *LimitError) FailureReason() string {
+	return "limit_" + e.Limit
+}
+
+// LoadLimitPolicy reads a JSON limit policy. An empty path means no limits.
+func LoadLimitPolicy(path string) (*LimitPolicy, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy LimitPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for tier, l := range policy.Tiers {
+		if l.MaxAmount < 0 || l.DailyTotal < 0 || l.MonthlyTotal < 0 || l.HourlyCount < 0 {
+			return nil, fmt.Errorf("%s: tier %q: limits must not be negative", path, tier)
+		}
+	}
+
+	return &policy, nil
+}
+
+// ForTier returns the limits of tier before any account override
+func (p *LimitPolicy) ForTier(tier string) Limits {
+	if p == nil {
+		return Limits{}
+	}
+	if l, ok := p.Tiers[tier]; ok {
+		return l
+	}
+	return p.Tiers["*"]
+}
+
+// tiers lists the account tiers the policy names
+func (p *LimitPolicy) tiers() []string {
+	var tiers []string
+	if p == nil {
+		return tiers
+	}
+	for tier := range p.Tiers {
+		if tier != "*" {
+			tiers = append(tiers, tier)
+		}
+	}
+	return tiers
+}
+
+// Apply returns l with the override's fields replaced
+func (l Limits) Apply(o *LimitOverride) Limits {
+	if o == nil {
+		return l
+	}
+	if o.MaxAmount != nil {
+		l.MaxAmount = *o.MaxAmount
+	}
+	if o.DailyTotal != nil {
+		l.DailyTotal = *o.DailyTotal
+	}
+	if o.MonthlyTotal != nil {
+		l.MonthlyTotal = *o.MonthlyTotal
+	}
+	if o.HourlyCount != nil {
+		l.HourlyCount = *o.HourlyCount
+	}
+	return l
+}
+
+// needsUsage reports whether checking l requires the account's history
+func (l Limits) needsUsage() bool {
+	return l.DailyTotal > 0 || l.MonthlyTotal > 0 || l.HourlyCount > 0
+}
+
+// Check returns a *LimitError when a transfer of amount on top of usage
+// breaks a limit
+func (l Limits) Check(amount int64, usage LimitUsage) error {
+	switch {
+	case l.MaxAmount > 0 && amount > l.MaxAmount:
+		return &LimitError{Limit: LimitMaxAmount, Max: l.MaxAmount}
+	case l.HourlyCount > 0 && usage.HourlyCount+1 > l.HourlyCount:
+		return &LimitError{Limit: LimitHourlyCount, Max: l.HourlyCount}
+	case l.DailyTotal > 0 && usage.DailyTotal+amount > l.DailyTotal:
+		return &LimitError{Limit: LimitDailyTotal, Max: l.DailyTotal}
+	case l.MonthlyTotal > 0 && usage.MonthlyTotal+amount > l.MonthlyTotal:
+		return &LimitError{Limit: LimitMonthlyTotal, Max: l.MonthlyTotal}
+	}
+	return nil
+}
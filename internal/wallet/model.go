@@ -10,10 +10,24 @@ type Account struct {
 	Phone         string
 	DOB           time.Time
 	Balance       int64
+	Tier          string
+	KYCTier       string
+	Frozen        bool
+	ErasedAt      *time.Time // set once the holder's PII is erased
+	Version       int64
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
 
+// AccountPatch holds a partial account update. A nil field is left
+// untouched; a non-nil field replaces the stored value.
+type AccountPatch struct {
+	Name  *string
+	Email *string
+	Phone *string
+	DOB   *time.Time
+}
+
 type Transaction struct {
 	ID            int64
 	FromAccountID int64
@@ -0,0 +1,299 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gopherpay/internal/pii"
+)
+
+// ErrNoKeyring is returned when encrypted PII is read without a keyring
+var ErrNoKeyring = errors.New("account PII is encrypted but no keyring is configured")
+
+// Blind index kinds
+const (
+	indexEmail = "email"
+	indexPhone = "phone"
+)
+
+// dobLayout is how a date of birth is encrypted
+const dobLayout = "2006-01-02"
+
+// piiColumns is an account's PII as stored. With a keyring, email and
+// phone hold ciphertext, the date of birth moves from dob to dob_enc and
+// the blind indexes are set; without one everything is plaintext.
+type piiColumns struct {
+	email    sql.NullString
+	phone    sql.NullString
+	dob      sql.NullTime
+	dobEnc   sql.NullString
+	emailIdx sql.NullString
+	phoneIdx sql.NullString
+	keyID    sql.NullString
+}
+
+// piiAAD binds a value to its account and column, so ciphertext copied
+// to another row or column does not decrypt
+func piiAAD(accountNumber, column string) string {
+	return "accounts." + column + ":" + accountNumber
+}
+
+// sealPII returns acc's PII as it is to be stored
+func (r *PostgresRepository) sealPII(acc *Account) (piiColumns, error) {
+	var c piiColumns
+
+	if r.keyring == nil {
+		c.email = sql.NullString{String: acc.Email, Valid: true}
+		c.phone = sql.NullString{String: acc.Phone, Valid: true}
+		c.dob = sql.NullTime{Time: acc.DOB, Valid: true}
+		return c, nil
+	}
+
+	seal := func(value, column string) (sql.NullString, error) {
+		if value == "" {
+			return sql.NullString{Valid: true}, nil
+		}
+		sealed, err := r.keyring.Encrypt(value, piiAAD(acc.AccountNumber, column))
+		return sql.NullString{String: sealed, Valid: true}, err
+	}
+
+	var err error
+	if c.email, err = seal(acc.Email, "email"); err != nil {
+		return c, err
+	}
+	if c.phone, err = seal(acc.Phone, "phone"); err != nil {
+		return c, err
+	}
+	if c.dobEnc, err = seal(acc.DOB.Format(dobLayout), "dob"); err != nil {
+		return c, err
+	}
+
+	if email := pii.NormalizeEmail(acc.Email); email != "" {
+		c.emailIdx = sql.NullString{String: r.keyring.BlindIndex(indexEmail, email), Valid: true}
+	}
+	if phone := pii.NormalizePhone(acc.Phone); phone != "" {
+		c.phoneIdx = sql.NullString{String: r.keyring.BlindIndex(indexPhone, phone), Valid: true}
+	}
+	c.keyID = sql.NullString{String: r.keyring.ActiveKeyID(), Valid: true}
+
+	return c, nil
+}
+
+// openPII fills acc's PII from stored columns. Plaintext written before
+// encryption was enabled is read as is.
+func (r *PostgresRepository) openPII(acc *Account, c piiColumns) error {
+	open := func(value, column string) (string, error) {
+		if !pii.IsEncrypted(value) {
+			return value, nil
+		}
+		if r.keyring == nil {
+			return "", ErrNoKeyring
+		}
+		plain, err := r.keyring.Decrypt(value, piiAAD(acc.AccountNumber, column))
+		if err != nil {
+			return "", fmt.Errorf("decrypting %s of %s: %w", column, acc.AccountNumber, err)
+		}
+		return plain, nil
+	}
+
+	var err error
+	if acc.Email, err = open(c.email.String, "email"); err != nil {
+		return err
+	}
+	if acc.Phone, err = open(c.phone.String, "phone"); err != nil {
+		return err
+	}
+
+	if !c.dobEnc.Valid {
+		acc.DOB = c.dob.Time
+		return nil
+	}
+
+	dob, err := open(c.dobEnc.String, "dob")
+	if err != nil {
+		return err
+	}
+	acc.DOB, err = time.Parse(dobLayout, dob)
+	return err
+}
+
+// FindAccountsByEmail returns the accounts with exactly this email,
+// ignoring case, through its blind index when encrypted
+func (r *PostgresRepository) FindAccountsByEmail(
+	ctx context.Context,
+	email string,
+) ([]Account, error) {
+
+	return r.findAccountsByContact(ctx, "email", indexEmail, pii.NormalizeEmail(email))
+}
+
+// FindAccountsByPhone returns the accounts with this phone number,
+// ignoring spaces and punctuation
+func (r *PostgresRepository) FindAccountsByPhone(
+	ctx context.Context,
+	phone string,
+) ([]Account, error) {
+
+	return r.findAccountsByContact(ctx, "phone", indexPhone, pii.NormalizePhone(phone))
+}
+
+// findAccountsByContact matches encrypted rows on their blind index and
+// rows not yet encrypted on the normalised plaintext column. Plaintext
+// matches are checked again in Go, where normalising is exact.
+func (r *PostgresRepository) findAccountsByContact(
+	ctx context.Context,
+	column string,
+	kind string,
+	value string,
+) ([]Account, error) {
+
+	if value == "" {
+		return []Account{}, nil
+	}
+
+	normalize := pii.NormalizeEmail
+	plaintext := `lower(trim(email))`
+	if kind == indexPhone {
+		normalize = pii.NormalizePhone
+		plaintext = `regexp_replace(phone, '[^0-9+]', '', 'g')`
+	}
+
+	index := ""
+	if r.keyring != nil {
+		index = r.keyring.BlindIndex(kind, value)
+	}
+
+	// column and plaintext are constants, never user input
+	query := `
+	SELECT ` + accountColumns + `
+	FROM accounts
+	WHERE ` + column + `_bidx = $1
+	   OR (pii_key_id IS NULL AND ` + plaintext + ` = $2)
+	ORDER BY account_number
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, index, value)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []Account{}
+	for rows.Next() {
+		acc, err := r.scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		stored := acc.Email
+		if kind == indexPhone {
+			stored = acc.Phone
+		}
+		if normalize(stored) == value {
+			accounts = append(accounts, *acc)
+		}
+	}
+
+	return accounts, rows.Err()
+}
+
+// RewrapPII re-encrypts up to limit accounts whose PII is plaintext or
+// sealed with a key other than the active one, and returns how many it
+// changed. Erased accounts have nothing to encrypt and are skipped. Rows
+// are locked one batch at a time, so it runs alongside live traffic; run
+// it until it returns 0 before retiring a key.
+func (r *PostgresRepository) RewrapPII(
+	ctx context.Context,
+	limit int,
+) (int, error) {
+
+	if r.keyring == nil {
+		return 0, errors.New("no keyring configured")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+	SELECT account_number, email, phone, dob, dob_enc
+	FROM accounts
+	WHERE pii_key_id IS DISTINCT FROM $1
+	  AND erased_at IS NULL
+	ORDER BY id
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.QueryContext(ctx, query, r.keyring.ActiveKeyID(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var accounts []Account
+	for rows.Next() {
+		var (
+			acc Account
+			c   piiColumns
+		)
+		if err := rows.Scan(&acc.AccountNumber, &c.email, &c.phone, &c.dob, &c.dobEnc); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if err := r.openPII(&acc, c); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		accounts = append(accounts, acc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// The content is unchanged, so the version is left alone
+	update := `
+	UPDATE accounts
+	SET email = $1,
+		phone = $2,
+		dob = $3,
+		dob_enc = $4,
+		email_bidx = $5,
+		phone_bidx = $6,
+		pii_key_id = $7
+	WHERE account_number = $8
+	`
+
+	for i := range accounts {
+		c, err := r.sealPII(&accounts[i])
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(
+			ctx,
+			update,
+			c.email,
+			c.phone,
+			c.dob,
+			c.dobEnc,
+			c.emailIdx,
+			c.phoneIdx,
+			c.keyID,
+			accounts[i].AccountNumber,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(accounts), nil
+}
@@ -3,40 +3,51 @@ package wallet
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"gopherpay/internal/chain"
+	"gopherpay/internal/pii"
 )
 
 type PostgresRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	keyring *pii.Keyring
+	chain   *chain.PostgresRepository
 }
 
-func NewPostgresRepository(db *sql.DB) *PostgresRepository {
-	return &PostgresRepository{db: db}
+// NewPostgresRepository returns a repository that encrypts account PII
+// with keyring, or stores it as plaintext when keyring is nil
+func NewPostgresRepository(db *sql.DB, keyring *pii.Keyring) *PostgresRepository {
+	return &PostgresRepository{db: db, keyring: keyring, chain: chain.NewPostgresRepository(db)}
 }
 
-// Get account using account_number (outside transaction)
-func (r *PostgresRepository) GetAccountByNumber(
-	ctx context.Context,
-	accountNumber string,
-) (*Account, error) {
-
-	query := `
-	SELECT id, account_number, name, email, phone, dob, balance, created_at, updated_at
-	FROM accounts
-	WHERE account_number = $1
-	`
-
-	row := r.db.QueryRowContext(ctx, query, accountNumber)
+// accountColumns is the column list scanAccount expects
+const accountColumns = `id, account_number, name, email, phone, dob, dob_enc, balance, tier, kyc_tier, frozen, erased_at, version, created_at, updated_at`
 
-	var acc Account
+// scanAccount reads a row selected with accountColumns and decrypts its PII
+func (r *PostgresRepository) scanAccount(row rowScanner) (*Account, error) {
+	var (
+		acc Account
+		c   piiColumns
+	)
 
 	err := row.Scan(
 		&acc.ID,
 		&acc.AccountNumber,
 		&acc.Name,
-		&acc.Email,
-		&acc.Phone,
-		&acc.DOB,
+		&c.email,
+		&c.phone,
+		&c.dob,
+		&c.dobEnc,
 		&acc.Balance,
+		&acc.Tier,
+		&acc.KYCTier,
+		&acc.Frozen,
+		&acc.ErasedAt,
+		&acc.Version,
 		&acc.CreatedAt,
 		&acc.UpdatedAt,
 	)
@@ -45,9 +56,28 @@ func (r *PostgresRepository) GetAccountByNumber(
 		return nil, err
 	}
 
+	if err := r.openPII(&acc, c); err != nil {
+		return nil, err
+	}
+
 	return &acc, nil
 }
 
+// Get account using account_number (outside transaction)
+func (r *PostgresRepository) GetAccountByNumber(
+	ctx context.Context,
+	accountNumber string,
+) (*Account, error) {
+
+	query := `
+	SELECT ` + accountColumns + `
+	FROM accounts
+	WHERE account_number = $1
+	`
+
+	return r.scanAccount(r.db.QueryRowContext(ctx, query, accountNumber))
+}
+
 // Lock account row FOR UPDATE (inside transaction)
 func (r *PostgresRepository) GetAccountForUpdateByID(
 	ctx context.Context,
@@ -56,7 +86,7 @@ func (r *PostgresRepository) GetAccountForUpdateByID(
 ) (*Account, error) {
 
 	query := `
-	SELECT id, balance
+	SELECT id, balance, frozen
 	FROM accounts
 	WHERE id = $1
 	FOR UPDATE
@@ -69,6 +99,7 @@ func (r *PostgresRepository) GetAccountForUpdateByID(
 	err := row.Scan(
 		&acc.ID,
 		&acc.Balance,
+		&acc.Frozen,
 	)
 
 	if err != nil {
@@ -89,6 +120,7 @@ func (r *PostgresRepository) UpdateBalance(
 	query := `
 	UPDATE accounts
 	SET balance = $1,
+	    version = version + 1,
 	    updated_at = now()
 	WHERE id = $2
 	`
@@ -110,27 +142,41 @@ func (r *PostgresRepository) CreateTransaction(
 	fromID int64,
 	toID int64,
 	amount int64,
+	kind string,
 	status string,
+	failureReason string,
 	requestID string,
 ) error {
 
 	query := `
 	INSERT INTO transactions
-	(from_account_id, to_account_id, amount, status, request_id)
-	VALUES ($1, $2, $3, $4, $5)
+	(from_account_id, to_account_id, amount, kind, status, failure_reason, request_id)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+	RETURNING id
 	`
 
-	_, err := tx.ExecContext(
+	var id int64
+	err := tx.QueryRowContext(
 		ctx,
 		query,
 		fromID,
 		toID,
 		amount,
+		kind,
 		status, // dynamic status (completed / failed)
+		failureReason,
 		requestID,
-	)
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// Held rows still change; they are sealed once settled
+	if status == StatusReview {
+		return nil
+	}
+
+	return r.chain.Seal(ctx, tx, id)
 }
 
 func (r *PostgresRepository) GetAccountByNumberTx(
@@ -140,7 +186,7 @@ func (r *PostgresRepository) GetAccountByNumberTx(
 ) (*Account, error) {
 
 	query := `
-	SELECT id, account_number, balance
+	SELECT id, account_number, name, balance, tier, kyc_tier, erased_at
 	FROM accounts
 	WHERE account_number = $1
 	`
@@ -152,7 +198,11 @@ func (r *PostgresRepository) GetAccountByNumberTx(
 	err := row.Scan(
 		&acc.ID,
 		&acc.AccountNumber,
+		&acc.Name,
 		&acc.Balance,
+		&acc.Tier,
+		&acc.KYCTier,
+		&acc.ErasedAt,
 	)
 
 	if err != nil {
@@ -162,6 +212,8 @@ func (r *PostgresRepository) GetAccountByNumberTx(
 	return &acc, nil
 }
 
+// UpdateTransactionStatus sets the status of a request's rows. Rows sealed
+// into the hash chain are final and left alone.
 func (r *PostgresRepository) UpdateTransactionStatus(
 	ctx context.Context,
 	requestID string,
@@ -172,6 +224,7 @@ func (r *PostgresRepository) UpdateTransactionStatus(
 	UPDATE transactions
 	SET status = $1
 	WHERE request_id = $2
+	  AND chain_seq IS NULL
 	`
 
 	_, err := r.db.ExecContext(
@@ -190,60 +243,469 @@ func (r *PostgresRepository) CreateAccount(
 	acc *Account,
 ) error {
 
+	c, err := r.sealPII(acc)
+	if err != nil {
+		return err
+	}
+
 	query := `
 	INSERT INTO accounts
-	(account_number, name, email, phone, dob, balance, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5, $6, now(), now())
+	(account_number, name, email, phone, dob, dob_enc, email_bidx, phone_bidx, pii_key_id,
+	 balance, tier, kyc_tier, frozen, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, now(), now())
 	RETURNING id
 	`
 
-	err := r.db.QueryRowContext(
+	err = r.db.QueryRowContext(
 		ctx,
 		query,
 		acc.AccountNumber,
 		acc.Name,
-		acc.Email,
-		acc.Phone,
-		acc.DOB,
+		c.email,
+		c.phone,
+		c.dob,
+		c.dobEnc,
+		c.emailIdx,
+		c.phoneIdx,
+		c.keyID,
 		acc.Balance,
+		acc.Tier,
+		acc.KYCTier,
+		acc.Frozen,
 	).Scan(&acc.ID)
 
 	return err
 }
 
-// UpdateAccount updates an existing account identified by account_number
+// UpdateAccount updates an existing account identified by account_number.
+// acc.Version must hold the version the caller last read; the row is only
+// written when it still matches, and acc is refreshed with the new version.
+// The balance and pricing tier are never written here; acc is refreshed
+// with the stored ones. The balance only moves through transactions and
+// the tier through SetAccountTier.
 func (r *PostgresRepository) UpdateAccount(
 	ctx context.Context,
 	acc *Account,
 ) error {
 
+	c, err := r.sealPII(acc)
+	if err != nil {
+		return err
+	}
+
 	query := `
 	UPDATE accounts
 	SET name = $1,
 		email = $2,
 		phone = $3,
 		dob = $4,
-		balance = $5,
+		dob_enc = $5,
+		email_bidx = $6,
+		phone_bidx = $7,
+		pii_key_id = $8,
+		version = version + 1,
 		updated_at = now()
-	WHERE account_number = $6
-	RETURNING id
+	WHERE account_number = $9
+	  AND version = $10
+	  AND erased_at IS NULL
+	RETURNING id, balance, tier, version, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(
+	err = r.db.QueryRowContext(
 		ctx,
 		query,
 		acc.Name,
-		acc.Email,
-		acc.Phone,
-		acc.DOB,
-		acc.Balance,
+		c.email,
+		c.phone,
+		c.dob,
+		c.dobEnc,
+		c.emailIdx,
+		c.phoneIdx,
+		c.keyID,
 		acc.AccountNumber,
-	).Scan(&acc.ID)
+		acc.Version,
+	).Scan(&acc.ID, &acc.Balance, &acc.Tier, &acc.Version, &acc.CreatedAt, &acc.UpdatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		// Distinguish a missing or erased account from a stale version
+		if err := accountWritable(ctx, r.db, acc.AccountNumber); err != nil {
+			return err
+		}
+		return ErrVersionConflict
+	}
 
 	return err
 }
 
-// DeleteAccount deletes account row by account_number
+// SetAccountFrozen freezes or unfreezes an account (inside transaction).
+// Account updates leave the flag alone, so only this changes it.
+func (r *PostgresRepository) SetAccountFrozen(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountNumber string,
+	frozen bool,
+) error {
+
+	query := `
+	UPDATE accounts
+	SET frozen = $1,
+		version = version + 1,
+		updated_at = now()
+	WHERE account_number = $2
+	  AND (erased_at IS NULL OR $1)
+	`
+
+	res, err := tx.ExecContext(ctx, query, frozen, accountNumber)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		// Erased accounts stay frozen
+		if err := accountWritable(ctx, tx, accountNumber); err != nil {
+			return err
+		}
+		return ErrAccountNotFound
+	}
+
+	return nil
+}
+
+// SetAccountTier moves an account to another pricing tier. Account
+// updates leave the tier alone, so only this changes it.
+func (r *PostgresRepository) SetAccountTier(
+	ctx context.Context,
+	accountNumber string,
+	tier string,
+) error {
+
+	query := `
+	UPDATE accounts
+	SET tier = $1,
+		version = version + 1,
+		updated_at = now()
+	WHERE account_number = $2
+	  AND erased_at IS NULL
+	`
+
+	res, err := r.db.ExecContext(ctx, query, tier, accountNumber)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		if err := accountWritable(ctx, r.db, accountNumber); err != nil {
+			return err
+		}
+		return ErrAccountNotFound
+	}
+
+	return nil
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// accountWritable returns ErrAccountNotFound or ErrAccountErased when
+// the account cannot be changed, and nil otherwise
+func accountWritable(ctx context.Context, q rowQuerier, accountNumber string) error {
+	var erased bool
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT erased_at IS NOT NULL FROM accounts WHERE account_number = $1`,
+		accountNumber,
+	).Scan(&erased)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAccountNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if erased {
+		return ErrAccountErased
+	}
+
+	return nil
+}
+
+// EraseAccountPII replaces the holder's name with pseudonym, clears
+// email, phone, date of birth and the blind indexes and freezes the
+// account (inside transaction). It fails with ErrAccountErased if the
+// account was already erased.
+func (r *PostgresRepository) EraseAccountPII(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountID int64,
+	pseudonym string,
+) error {
+
+	query := `
+	UPDATE accounts
+	SET name = $1,
+		email = '',
+		phone = '',
+		dob = NULL,
+		dob_enc = NULL,
+		email_bidx = NULL,
+		phone_bidx = NULL,
+		pii_key_id = NULL,
+		frozen = TRUE,
+		erased_at = now(),
+		version = version + 1,
+		updated_at = now()
+	WHERE id = $2
+	  AND erased_at IS NULL
+	`
+
+	res, err := tx.ExecContext(ctx, query, pseudonym, accountID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAccountErased
+	}
+
+	return nil
+}
+
+// PseudonymizeSanctionsAlerts replaces the screened name on every alert
+// of an account with the pseudonym. The listed entry and the name it
+// matched are from the sanctions list and stay as they are.
+func (r *PostgresRepository) PseudonymizeSanctionsAlerts(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountNumber string,
+	pseudonym string,
+) error {
+
+	query := `
+	UPDATE sanctions_alerts
+	SET screened_name = $1
+	WHERE account_number = $2
+	`
+
+	_, err := tx.ExecContext(ctx, query, pseudonym, accountNumber)
+	return err
+}
+
+// CreateErasure records an erasure (inside transaction)
+func (r *PostgresRepository) CreateErasure(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountID int64,
+	erasure *Erasure,
+) error {
+
+	query := `
+	INSERT INTO account_erasures
+	(account_id, erased_by, reason)
+	VALUES ($1, $2, $3)
+	RETURNING id, created_at
+	`
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		accountID,
+		erasure.ErasedBy,
+		erasure.Reason,
+	).Scan(&erasure.ID, &erasure.CreatedAt)
+}
+
+// ListErasures returns an account's erasures, oldest first. The pseudonym
+// is the account's current name.
+func (r *PostgresRepository) ListErasures(
+	ctx context.Context,
+	accountNumber string,
+) ([]Erasure, error) {
+
+	query := `
+	SELECT e.id, a.account_number, a.name, e.erased_by, e.reason, e.created_at
+	FROM account_erasures e
+	JOIN accounts a ON a.id = e.account_id
+	WHERE a.account_number = $1
+	ORDER BY e.created_at, e.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	erasures := []Erasure{}
+	for rows.Next() {
+		var e Erasure
+		if err := rows.Scan(&e.ID, &e.AccountNumber, &e.Pseudonym, &e.ErasedBy, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		erasures = append(erasures, e)
+	}
+
+	return erasures, rows.Err()
+}
+
+// SetKYCTier moves an account to tier and records the change (inside
+// transaction). The account must still be in change.FromTier.
+func (r *PostgresRepository) SetKYCTier(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountID int64,
+	change *KYCChange,
+) error {
+
+	query := `
+	UPDATE accounts
+	SET kyc_tier = $1,
+		version = version + 1,
+		updated_at = now()
+	WHERE id = $2
+	  AND kyc_tier = $3
+	`
+
+	res, err := tx.ExecContext(ctx, query, change.ToTier, accountID, change.FromTier)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrVersionConflict
+	}
+
+	return tx.QueryRowContext(
+		ctx,
+		`
+	INSERT INTO kyc_tier_changes
+	(account_id, from_tier, to_tier, changed_by, reason)
+	VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	RETURNING id, created_at
+	`,
+		accountID,
+		change.FromTier,
+		change.ToTier,
+		change.ChangedBy,
+		change.Reason,
+	).Scan(&change.ID, &change.CreatedAt)
+}
+
+// ListKYCChanges returns an account's tier changes oldest first
+func (r *PostgresRepository) ListKYCChanges(
+	ctx context.Context,
+	accountID int64,
+) ([]KYCChange, error) {
+
+	query := `
+	SELECT id, from_tier, to_tier, changed_by, COALESCE(reason, ''), created_at
+	FROM kyc_tier_changes
+	WHERE account_id = $1
+	ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []KYCChange{}
+	for rows.Next() {
+		var c KYCChange
+		if err := rows.Scan(&c.ID, &c.FromTier, &c.ToTier, &c.ChangedBy, &c.Reason, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+// AddKYCDocument records document metadata and sets its ID
+func (r *PostgresRepository) AddKYCDocument(
+	ctx context.Context,
+	accountID int64,
+	doc *KYCDocument,
+) error {
+
+	query := `
+	INSERT INTO kyc_documents
+	(account_id, doc_type, reference, issuing_country, expires_at, recorded_by)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+	RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		accountID,
+		doc.Type,
+		doc.Reference,
+		doc.Country,
+		doc.ExpiresAt,
+		doc.RecordedBy,
+	).Scan(&doc.ID, &doc.CreatedAt)
+}
+
+// ListKYCDocuments returns an account's documents oldest first
+func (r *PostgresRepository) ListKYCDocuments(
+	ctx context.Context,
+	accountID int64,
+) ([]KYCDocument, error) {
+
+	query := `
+	SELECT id, doc_type, reference, COALESCE(issuing_country, ''), expires_at, recorded_by, created_at
+	FROM kyc_documents
+	WHERE account_id = $1
+	ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []KYCDocument{}
+	for rows.Next() {
+		var (
+			d         KYCDocument
+			expiresAt sql.NullTime
+		)
+		err := rows.Scan(&d.ID, &d.Type, &d.Reference, &d.Country, &expiresAt, &d.RecordedBy, &d.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if expiresAt.Valid {
+			d.ExpiresAt = &expiresAt.Time
+		}
+		docs = append(docs, d)
+	}
+
+	return docs, rows.Err()
+}
+
+// DeleteAccount deletes account row by account_number. Transactions and
+// compliance records restrict the delete, which fails with
+// ErrAccountHasRecords.
 func (r *PostgresRepository) DeleteAccount(
 	ctx context.Context,
 	accountNumber string,
@@ -255,5 +717,437 @@ func (r *PostgresRepository) DeleteAccount(
 	`
 
 	_, err := r.db.ExecContext(ctx, query, accountNumber)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+		return ErrAccountHasRecords
+	}
+	return err
+}
+
+const limitOverrideQuery = `
+	SELECT max_amount, daily_total, monthly_total, hourly_count
+	FROM account_limits
+	WHERE account_id = $1
+	`
+
+// GetLimitOverride returns nil when the account has no override
+func (r *PostgresRepository) GetLimitOverride(
+	ctx context.Context,
+	accountID int64,
+) (*LimitOverride, error) {
+
+	return scanLimitOverride(r.db.QueryRowContext(ctx, limitOverrideQuery, accountID))
+}
+
+func (r *PostgresRepository) GetLimitOverrideTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountID int64,
+) (*LimitOverride, error) {
+
+	return scanLimitOverride(tx.QueryRowContext(ctx, limitOverrideQuery, accountID))
+}
+
+func scanLimitOverride(row *sql.Row) (*LimitOverride, error) {
+	var maxAmount, daily, monthly, hourly sql.NullInt64
+
+	err := row.Scan(&maxAmount, &daily, &monthly, &hourly)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &LimitOverride{
+		MaxAmount:    nullInt64Ptr(maxAmount),
+		DailyTotal:   nullInt64Ptr(daily),
+		MonthlyTotal: nullInt64Ptr(monthly),
+		HourlyCount:  nullInt64Ptr(hourly),
+	}, nil
+}
+
+func nullInt64Ptr(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
+
+// SetLimitOverride upserts the override; nil fields are stored as NULL
+func (r *PostgresRepository) SetLimitOverride(
+	ctx context.Context,
+	accountID int64,
+	override *LimitOverride,
+) error {
+
+	query := `
+	INSERT INTO account_limits
+	(account_id, max_amount, daily_total, monthly_total, hourly_count, updated_at)
+	VALUES ($1, $2, $3, $4, $5, now())
+	ON CONFLICT (account_id) DO UPDATE
+	SET max_amount = EXCLUDED.max_amount,
+		daily_total = EXCLUDED.daily_total,
+		monthly_total = EXCLUDED.monthly_total,
+		hourly_count = EXCLUDED.hourly_count,
+		updated_at = now()
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		accountID,
+		override.MaxAmount,
+		override.DailyTotal,
+		override.MonthlyTotal,
+		override.HourlyCount,
+	)
+
+	return err
+}
+
+func (r *PostgresRepository) DeleteLimitOverride(
+	ctx context.Context,
+	accountID int64,
+) error {
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM account_limits WHERE account_id = $1`, accountID)
+	return err
+}
+
+// GetOutgoingUsage counts completed outgoing transfers, fees excluded, for
+// the current day and month and the last hour. now() is the transaction
+// start, and the caller holds the account row lock, so the figures cannot
+// change before it commits.
+func (r *PostgresRepository) GetOutgoingUsage(
+	ctx context.Context,
+	tx *sql.Tx,
+	accountID int64,
+) (LimitUsage, error) {
+
+	query := `
+	SELECT
+		COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('day', now())), 0),
+		COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('month', now())), 0),
+		COUNT(*) FILTER (WHERE created_at >= now() - interval '1 hour')
+	FROM transactions
+	WHERE from_account_id = $1
+	  AND to_account_id <> $1
+	  AND status = 'completed'
+	  AND kind = 'transfer'
+	  AND created_at >= LEAST(date_trunc('month', now()), now() - interval '1 hour')
+	`
+
+	var usage LimitUsage
+
+	err := tx.QueryRowContext(ctx, query, accountID).Scan(
+		&usage.DailyTotal,
+		&usage.MonthlyTotal,
+		&usage.HourlyCount,
+	)
+
+	return usage, err
+}
+
+// CreateReview inserts the review and sets its ID and timestamps
+func (r *PostgresRepository) CreateReview(
+	ctx context.Context,
+	tx *sql.Tx,
+	review *Review,
+) error {
+
+	adjustedBy, err := json.Marshal(nonNilStrings(review.AdjustedBy))
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO transfer_reviews
+	(request_id, from_account, to_account, amount, type, reason, risk_score, status, initiated_by, adjusted_by)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	RETURNING id, created_at, updated_at
+	`
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		review.RequestID,
+		review.FromAccount,
+		review.ToAccount,
+		review.Amount,
+		review.Type,
+		review.Reason,
+		review.RiskScore,
+		review.Status,
+		review.InitiatedBy,
+		string(adjustedBy),
+	).Scan(&review.ID, &review.CreatedAt, &review.UpdatedAt)
+}
+
+const reviewColumns = `
+	id, request_id, from_account, to_account, amount, type, reason,
+	risk_score, status, initiated_by, adjusted_by, decided_by, note,
+	decided_at, dispatched_at, created_at, updated_at
+	`
+
+func (r *PostgresRepository) GetReview(
+	ctx context.Context,
+	id int64,
+) (*Review, error) {
+
+	query := `SELECT ` + reviewColumns + ` FROM transfer_reviews WHERE id = $1`
+
+	return scanReview(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *PostgresRepository) GetReviewForUpdate(
+	ctx context.Context,
+	tx *sql.Tx,
+	id int64,
+) (*Review, error) {
+
+	query := `SELECT ` + reviewColumns + ` FROM transfer_reviews WHERE id = $1 FOR UPDATE`
+
+	return scanReview(tx.QueryRowContext(ctx, query, id))
+}
+
+// ListReviews returns reviews oldest first; an empty status lists all
+func (r *PostgresRepository) ListReviews(
+	ctx context.Context,
+	status string,
+	limit int,
+) ([]Review, error) {
+
+	query := `
+	SELECT ` + reviewColumns + `
+	FROM transfer_reviews
+	WHERE ($1 = '' OR status = $1)
+	ORDER BY created_at, id
+	LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
+func (r *PostgresRepository) UpdateReview(
+	ctx context.Context,
+	tx *sql.Tx,
+	review *Review,
+) error {
+
+	adjustedBy, err := json.Marshal(nonNilStrings(review.AdjustedBy))
+	if err != nil {
+		return err
+	}
+
+	// decided_at is stamped when the status first becomes approved or
+	// rejected; the right-hand status is the stored one
+	query := `
+	UPDATE transfer_reviews
+	SET amount = $1,
+		status = $2,
+		adjusted_by = $3,
+		decided_by = NULLIF($4, ''),
+		note = NULLIF($5, ''),
+		decided_at = CASE
+			WHEN status = 'pending' AND $2 IN ('approved', 'rejected') THEN now()
+			ELSE decided_at
+		END,
+		updated_at = now()
+	WHERE id = $6
+	RETURNING decided_at, updated_at
+	`
+
+	var decidedAt sql.NullTime
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		review.Amount,
+		review.Status,
+		string(adjustedBy),
+		review.DecidedBy,
+		review.Note,
+		review.ID,
+	).Scan(&decidedAt, &review.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if decidedAt.Valid {
+		review.DecidedAt = &decidedAt.Time
+	}
+
+	return nil
+}
+
+// ClaimApprovedReviews stamps dispatched_at on approved reviews not yet
+// dispatched, or dispatched so long ago the job was presumably lost, e.g.
+// to a restart. SKIP LOCKED lets several servers claim concurrently.
+func (r *PostgresRepository) ClaimApprovedReviews(
+	ctx context.Context,
+	limit int,
+) ([]Review, error) {
+
+	query := `
+	UPDATE transfer_reviews
+	SET dispatched_at = now()
+	WHERE id IN (
+		SELECT id
+		FROM transfer_reviews
+		WHERE status = 'approved'
+		  AND (dispatched_at IS NULL OR dispatched_at < now() - interval '5 minutes')
+		ORDER BY decided_at, id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + reviewColumns
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
+// SettleHeldTransaction completes or fails the transfer row held for
+// review and seals it into the hash chain. created_at moves to the
+// settlement time, so the transfer counts toward limits and reports when
+// the money actually moved.
+func (r *PostgresRepository) SettleHeldTransaction(
+	ctx context.Context,
+	tx *sql.Tx,
+	requestID string,
+	amount int64,
+	status string,
+	failureReason string,
+) error {
+
+	query := `
+	UPDATE transactions
+	SET amount = $1,
+		status = $2,
+		failure_reason = NULLIF($3, ''),
+		created_at = now()
+	WHERE request_id = $4
+	  AND kind = 'transfer'
+	  AND status = 'review'
+	RETURNING id
+	`
+
+	var id int64
+	err := tx.QueryRowContext(ctx, query, amount, status, failureReason, requestID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("held transaction not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.chain.Seal(ctx, tx, id)
+}
+
+// UpdateHeldTransaction changes the amount of a transfer still held for
+// review
+func (r *PostgresRepository) UpdateHeldTransaction(
+	ctx context.Context,
+	tx *sql.Tx,
+	requestID string,
+	amount int64,
+) error {
+
+	query := `
+	UPDATE transactions
+	SET amount = $1
+	WHERE request_id = $2
+	  AND kind = 'transfer'
+	  AND status = 'review'
+	`
+
+	_, err := tx.ExecContext(ctx, query, amount, requestID)
 	return err
 }
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReview(row rowScanner) (*Review, error) {
+	var (
+		review     Review
+		adjustedBy []byte
+		decidedBy  sql.NullString
+		note       sql.NullString
+		decidedAt  sql.NullTime
+		dispatched sql.NullTime
+	)
+
+	err := row.Scan(
+		&review.ID,
+		&review.RequestID,
+		&review.FromAccount,
+		&review.ToAccount,
+		&review.Amount,
+		&review.Type,
+		&review.Reason,
+		&review.RiskScore,
+		&review.Status,
+		&review.InitiatedBy,
+		&adjustedBy,
+		&decidedBy,
+		&note,
+		&decidedAt,
+		&dispatched,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(adjustedBy, &review.AdjustedBy); err != nil {
+		return nil, err
+	}
+	review.DecidedBy = decidedBy.String
+	review.Note = note.String
+	if decidedAt.Valid {
+		review.DecidedAt = &decidedAt.Time
+	}
+	if dispatched.Valid {
+		review.DispatchedAt = &dispatched.Time
+	}
+
+	return &review, nil
+}
+
+func scanReviews(rows *sql.Rows) ([]Review, error) {
+	reviews := []Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, *review)
+	}
+	return reviews, rows.Err()
+}
+
+// nonNilStrings keeps an empty list from being stored as JSON null
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -35,13 +35,18 @@ type WalletRepository interface {
 		accountNumber string,
 	) (*Account, error)
 
+	// Record a transaction of kind transfer, fee or adjustment and seal it into the
+	// hash chain, unless it is held for review; failureReason is empty
+	// unless status is failed
 	CreateTransaction(
 		ctx context.Context,
 		tx *sql.Tx,
 		fromID int64,
 		toID int64,
 		amount int64,
+		kind string,
 		status string,
+		failureReason string,
 		requestID string,
 	) error
 
@@ -51,12 +56,55 @@ type WalletRepository interface {
 		acc *Account,
 	) error
 
-	// Update account
+	// Update account if acc.Version still matches the stored version
 	UpdateAccount(
 		ctx context.Context,
 		acc *Account,
 	) error
 
+	// Freeze or unfreeze an account (inside transaction)
+	SetAccountFrozen(
+		ctx context.Context,
+		tx *sql.Tx,
+		accountNumber string,
+		frozen bool,
+	) error
+
+	// Move an account to another pricing tier
+	SetAccountTier(
+		ctx context.Context,
+		accountNumber string,
+		tier string,
+	) error
+
+	// Move an account between KYC tiers and record the change (inside
+	// transaction)
+	SetKYCTier(
+		ctx context.Context,
+		tx *sql.Tx,
+		accountID int64,
+		change *KYCChange,
+	) error
+
+	// List an account's KYC tier changes
+	ListKYCChanges(
+		ctx context.Context,
+		accountID int64,
+	) ([]KYCChange, error)
+
+	// Record KYC document metadata
+	AddKYCDocument(
+		ctx context.Context,
+		accountID int64,
+		doc *KYCDocument,
+	) error
+
+	// List an account's KYC documents
+	ListKYCDocuments(
+		ctx context.Context,
+		accountID int64,
+	) ([]KYCDocument, error)
+
 	// Delete account by account number
 	DeleteAccount(
 		ctx context.Context,
@@ -68,14 +116,146 @@ type WalletRepository interface {
 		requestID string,
 		status string,
 	) error
-}
 
-// Create transaction record
-// CreateTransaction(
-// 	ctx context.Context,
-// 	tx *sql.Tx,
-// 	fromAccountID int64,
-// 	toAccountID int64,
-// 	amount int64,
-// 	requestID string,
-// ) error
+	// Get an account's limit override; nil when it has none
+	GetLimitOverride(
+		ctx context.Context,
+		accountID int64,
+	) (*LimitOverride, error)
+
+	// Get an account's limit override (inside transaction)
+	GetLimitOverrideTx(
+		ctx context.Context,
+		tx *sql.Tx,
+		accountID int64,
+	) (*LimitOverride, error)
+
+	// Create or replace an account's limit override
+	SetLimitOverride(
+		ctx context.Context,
+		accountID int64,
+		override *LimitOverride,
+	) error
+
+	// Remove an account's limit override
+	DeleteLimitOverride(
+		ctx context.Context,
+		accountID int64,
+	) error
+
+	// Sum the account's completed outgoing transfers in the limit periods
+	// (inside transaction)
+	GetOutgoingUsage(
+		ctx context.Context,
+		tx *sql.Tx,
+		accountID int64,
+	) (LimitUsage, error)
+
+	// Record a held transfer's review (inside transaction); sets ID
+	CreateReview(
+		ctx context.Context,
+		tx *sql.Tx,
+		review *Review,
+	) error
+
+	// Get a review by ID
+	GetReview(
+		ctx context.Context,
+		id int64,
+	) (*Review, error)
+
+	// Lock a review row FOR UPDATE inside transaction
+	GetReviewForUpdate(
+		ctx context.Context,
+		tx *sql.Tx,
+		id int64,
+	) (*Review, error)
+
+	// List reviews oldest first, optionally by status
+	ListReviews(
+		ctx context.Context,
+		status string,
+		limit int,
+	) ([]Review, error)
+
+	// Save a review's amount, status and decision (inside transaction)
+	UpdateReview(
+		ctx context.Context,
+		tx *sql.Tx,
+		review *Review,
+	) error
+
+	// Mark up to limit approved reviews dispatched and return them
+	ClaimApprovedReviews(
+		ctx context.Context,
+		limit int,
+	) ([]Review, error)
+
+	// Settle a transfer held for review with its final amount and status
+	// (inside transaction)
+	SettleHeldTransaction(
+		ctx context.Context,
+		tx *sql.Tx,
+		requestID string,
+		amount int64,
+		status string,
+		failureReason string,
+	) error
+
+	// Change the amount of a transfer held for review (inside transaction)
+	UpdateHeldTransaction(
+		ctx context.Context,
+		tx *sql.Tx,
+		requestID string,
+		amount int64,
+	) error
+
+	// Accounts with this email, compared case-insensitively
+	FindAccountsByEmail(
+		ctx context.Context,
+		email string,
+	) ([]Account, error)
+
+	// Accounts with this phone number, ignoring formatting
+	FindAccountsByPhone(
+		ctx context.Context,
+		phone string,
+	) ([]Account, error)
+
+	// Re-encrypt up to limit accounts not yet under the active PII key
+	RewrapPII(
+		ctx context.Context,
+		limit int,
+	) (int, error)
+
+	// Pseudonymize an account's PII and freeze it (inside transaction)
+	EraseAccountPII(
+		ctx context.Context,
+		tx *sql.Tx,
+		accountID int64,
+		pseudonym string,
+	) error
+
+	// Replace the holder's name on an account's sanctions alerts with the
+	// pseudonym (inside transaction)
+	PseudonymizeSanctionsAlerts(
+		ctx context.Context,
+		tx *sql.Tx,
+		accountNumber string,
+		pseudonym string,
+	) error
+
+	// Record an erasure (inside transaction)
+	CreateErasure(
+		ctx context.Context,
+		tx *sql.Tx,
+		accountID int64,
+		erasure *Erasure,
+	) error
+
+	// List an account's erasures, oldest first
+	ListErasures(
+		ctx context.Context,
+		accountNumber string,
+	) ([]Erasure, error)
+}
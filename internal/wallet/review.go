@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Review statuses. An approved review is executed by the worker pool;
+// executed means the transfer ran, whatever its outcome.
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+	ReviewExecuted = "executed"
+)
+
+// Why a transfer was held
+const (
+	ReviewReasonThreshold = "threshold"
+	ReviewReasonRisk      = "risk"
+)
+
+// FailureReviewRejected is recorded on held transfers a reviewer rejected
+const FailureReviewRejected = "review_rejected"
+
+var (
+	ErrReviewNotFound    = errors.New("review not found")
+	ErrReviewNotPending  = errors.New("review is not pending")
+	ErrReviewNotApproved = errors.New("review is not approved")
+
+	// ErrSamePrincipal enforces the four-eyes principle: whoever initiated
+	// or adjusted a transfer cannot approve it
+	ErrSamePrincipal = errors.New("approver must differ from the initiator and adjusters")
+
+	// ErrUnknownInitiator is returned when approving a transfer sent without
+	// a principal: anyone could be its initiator, so it can only be rejected
+	ErrUnknownInitiator = errors.New("transfer has no initiator to check the approver against; it can only be rejected")
+
+	// ErrPrincipalRequired is returned when a review action is anonymous
+	ErrPrincipalRequired = errors.New("principal is required")
+)
+
+// Review is a transfer held for a second pair of eyes
+type Review struct {
+	ID           int64      `json:"id"`
+	RequestID    string     `json:"request_id"`
+	FromAccount  string     `json:"from_account"`
+	ToAccount    string     `json:"to_account"`
+	Amount       int64      `json:"amount"`
+	Type         string     `json:"type"`
+	Reason       string     `json:"reason"`
+	RiskScore    int        `json:"risk_score,omitempty"`
+	Status       string     `json:"status"`
+	InitiatedBy  string     `json:"initiated_by"`
+	AdjustedBy   []string   `json:"adjusted_by"`
+	DecidedBy    string     `json:"decided_by,omitempty"`
+	Note         string     `json:"note,omitempty"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	DispatchedAt *time.Time `json:"dispatched_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// canApprove reports whether principal may approve the review
+func (r *Review) canApprove(principal string) error {
+	if principal == "" {
+		return ErrPrincipalRequired
+	}
+	if r.InitiatedBy == "" {
+		return ErrUnknownInitiator
+	}
+	if principal == r.InitiatedBy || slices.Contains(r.AdjustedBy, principal) {
+		return ErrSamePrincipal
+	}
+	return nil
+}
+
+// decide records a reviewer's decision; the store stamps DecidedAt
+func (r *Review) decide(status, principal, note string) {
+	r.Status = status
+	r.DecidedBy = principal
+	r.Note = note
+}
@@ -0,0 +1,31 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanApprove(t *testing.T) {
+	tests := []struct {
+		name      string
+		review    Review
+		principal string
+		want      error
+	}{
+		{"other principal", Review{InitiatedBy: "bob"}, "alice", nil},
+		{"initiator", Review{InitiatedBy: "alice"}, "alice", ErrSamePrincipal},
+		{"adjuster", Review{InitiatedBy: "bob", AdjustedBy: []string{"carol", "alice"}}, "alice", ErrSamePrincipal},
+		{"anonymous approver", Review{InitiatedBy: "bob"}, "", ErrPrincipalRequired},
+		// Whoever sent an anonymous transfer could otherwise approve it
+		{"anonymous initiator", Review{}, "alice", ErrUnknownInitiator},
+		{"anonymous initiator, adjusted", Review{AdjustedBy: []string{"bob"}}, "alice", ErrUnknownInitiator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.review.canApprove(tt.principal); !errors.Is(err, tt.want) {
+				t.Errorf("canApprove(%q) = %v, want %v", tt.principal, err, tt.want)
+			}
+		})
+	}
+}
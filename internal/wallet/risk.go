@@ -0,0 +1,65 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Risk actions, from least to most severe
+const (
+	RiskAllow  = "allow"
+	RiskReview = "review"
+	RiskBlock  = "block"
+)
+
+// StatusReview marks a transfer held for manual review; no money has
+// moved yet
+const StatusReview = "review"
+
+// FailureRiskBlocked is recorded on transfers blocked by risk screening
+const FailureRiskBlocked = "risk_blocked"
+
+var (
+	// ErrHeldForReview is returned when a transfer is held for manual
+	// review; it is stored with StatusReview until a reviewer decides
+	ErrHeldForReview = errors.New("transfer held for review")
+
+	// ErrRiskBlocked is returned when risk screening blocks a transfer
+	ErrRiskBlocked = errors.New("transfer blocked by risk screening")
+)
+
+// RiskEvaluator screens a transfer after the accounts are locked and
+// before any balance changes. It runs inside the transfer's database
+// transaction, so what it reads and records commits or rolls back with
+// the transfer.
+type RiskEvaluator interface {
+	Evaluate(ctx context.Context, tx *sql.Tx, transfer RiskTransfer) (*RiskDecision, error)
+}
+
+// RiskTransfer is the transfer being screened
+type RiskTransfer struct {
+	RequestID         string
+	FromAccountID     int64
+	FromAccountNumber string
+	FromTier          string
+	ToAccountID       int64
+	ToAccountNumber   string
+	Amount            int64
+	Type              string
+}
+
+// RiskDecision is the outcome of screening: the total score of the rules
+// that fired and the action it maps to
+type RiskDecision struct {
+	Action string    `json:"action"`
+	Score  int       `json:"score"`
+	Hits   []RiskHit `json:"hits"`
+}
+
+// RiskHit is one rule that fired
+type RiskHit struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail,omitempty"`
+}
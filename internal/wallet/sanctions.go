@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// What triggered a sanctions screening
+const (
+	ScreenAccountCreate = "account_create"
+	ScreenAccountUpdate = "account_update"
+	ScreenTransfer      = "transfer"
+)
+
+// Failure reasons recorded on transfers stopped by sanctions controls
+const (
+	FailureAccountFrozen = "account_frozen"
+	FailureSanctionsHit  = "sanctions_hit"
+)
+
+var (
+	// ErrAccountFrozen is returned for transfers from or to a frozen account
+	ErrAccountFrozen = errors.New("account is frozen")
+
+	// ErrSanctionsHit is returned when screening freezes a party to a
+	// transfer
+	ErrSanctionsHit = errors.New("transfer party matched the sanctions list")
+)
+
+// SanctionsScreener matches account holders against a sanctions list. It
+// runs inside the caller's database transaction, so alerts it records
+// commit or roll back with the account change or transfer.
+type SanctionsScreener interface {
+	Screen(ctx context.Context, tx *sql.Tx, subject SanctionsSubject) (*SanctionsResult, error)
+}
+
+// SanctionsSubject is an account holder being screened
+type SanctionsSubject struct {
+	AccountNumber string
+	Name          string
+	Trigger       string
+	RequestID     string
+}
+
+// SanctionsResult reports the screening outcome. Matches already alerted
+// on, or dismissed for the same name, raise no new alert.
+type SanctionsResult struct {
+	Matches   int
+	NewAlerts int
+
+	// Freeze is set when the account should be frozen
+	Freeze bool
+}
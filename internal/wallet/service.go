@@ -5,41 +5,217 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+)
+
+var (
+	// ErrAccountNotFound is returned when no account matches the given number
+	ErrAccountNotFound = errors.New("account not found")
+
+	// ErrVersionConflict is returned when an update was based on a stale
+	// version of the account
+	ErrVersionConflict = errors.New("account was modified by another request")
+
+	// ErrAccountHasRecords is returned when an account cannot be deleted
+	// because records that must be retained still refer to it
+	ErrAccountHasRecords = errors.New("account has transactions or compliance records and cannot be deleted")
+)
+
+// Failure reasons recorded on failed transactions
+const (
+	FailureRecipientNotFound = "recipient_not_found"
+	FailureInsufficientFunds = "insufficient_funds"
 )
 
 type WalletService struct {
-	db   *sql.DB
-	repo WalletRepository
+	db     *sql.DB
+	repo   WalletRepository
+	fees   *FeeSchedule
+	limits *LimitPolicy
+	kyc    *KYCPolicy
+	risk   RiskEvaluator
+
+	// sanctions screens account holders; nil disables screening
+	sanctions SanctionsScreener
+
+	// reviewThreshold holds transfers of more than this many cents for
+	// review; 0 disables it
+	reviewThreshold int64
+
+	// adjustmentAccount is the counterparty of balance adjustments; empty
+	// disables them
+	adjustmentAccount string
 }
 
-func NewWalletService(db *sql.DB, repo WalletRepository) *WalletService {
+// NewWalletService creates the service. fees, limits, kyc, risk and
+// sanctions may be nil to charge nothing, limit nothing and screen
+// nothing, a zero reviewThreshold holds nothing back for review and an
+// empty adjustmentAccount disables balance adjustments.
+func NewWalletService(
+	db *sql.DB,
+	repo WalletRepository,
+	fees *FeeSchedule,
+	limits *LimitPolicy,
+	kyc *KYCPolicy,
+	risk RiskEvaluator,
+	sanctions SanctionsScreener,
+	reviewThreshold int64,
+	adjustmentAccount string,
+) *WalletService {
+
 	return &WalletService{
-		db:   db,
-		repo: repo,
+		db:                db,
+		repo:              repo,
+		fees:              fees,
+		limits:            limits,
+		kyc:               kyc,
+		risk:              risk,
+		sanctions:         sanctions,
+		reviewThreshold:   reviewThreshold,
+		adjustmentAccount: adjustmentAccount,
+	}
+}
+
+// TransferResult is the breakdown of a completed transfer: the recipient
+// receives Amount and the sender is debited TotalDebit, Fee included
+type TransferResult struct {
+	Amount     int64
+	Fee        int64
+	FeeKind    string
+	TotalDebit int64
+}
+
+// CheckFeeSchedule verifies the revenue account exists when fees are
+// configured, so a misconfiguration fails at startup rather than on the
+// first transfer
+func (s *WalletService) CheckFeeSchedule(ctx context.Context) error {
+	if !s.fees.Enabled() {
+		return nil
+	}
+
+	_, err := s.repo.GetAccountByNumber(ctx, s.fees.RevenueAccount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s", ErrRevenueAccountNotFound, s.fees.RevenueAccount)
+	}
+
+	return err
+}
+
+// QuoteFee prices a transfer without executing it. The fee actually
+// charged is computed again when the transfer runs.
+func (s *WalletService) QuoteFee(
+	ctx context.Context,
+	fromAccountNumber string,
+	transferType string,
+	amount int64,
+) (*TransferResult, error) {
+
+	from, err := s.repo.GetAccountByNumber(ctx, fromAccountNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.price(from.AccountNumber, from.Tier, transferType, amount), nil
 }
 
-// Transfer processes the transfer using requestID provided by API middleware
+// price applies the fee schedule. The revenue account pays no fees.
+func (s *WalletService) price(fromAccountNumber, tier, transferType string, amount int64) *TransferResult {
+	result := &TransferResult{Amount: amount, TotalDebit: amount}
+
+	if !s.fees.Enabled() || fromAccountNumber == s.fees.RevenueAccount {
+		return result
+	}
+
+	fee := s.fees.Quote(tier, transferType, amount)
+	result.Fee = fee.Amount
+	result.FeeKind = fee.Kind
+	result.TotalDebit = amount + fee.Amount
+
+	return result
+}
+
+// lockAccounts locks the rows of ids FOR UPDATE in ascending ID order,
+// the one order every writer uses, and returns them by ID. Repeated IDs
+// are locked once.
+func (s *WalletService) lockAccounts(ctx context.Context, tx *sql.Tx, ids ...int64) (map[int64]*Account, error) {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+
+	locked := make(map[int64]*Account, len(ids))
+	for _, id := range slices.Compact(ids) {
+		acc, err := s.repo.GetAccountForUpdateByID(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		locked[id] = acc
+	}
+
+	return locked, nil
+}
+
+// Transfer processes the transfer using requestID provided by API middleware.
+// Any fee for transferType is debited from the sender on top of amount and
+// credited to the revenue account in the same database transaction.
+// Transfers over the review threshold, or flagged by risk screening, are
+// held for review instead: ErrHeldForReview is returned and nothing moves
+// until a second principal approves. initiatedBy identifies the caller.
 func (s *WalletService) Transfer(
 	ctx context.Context,
 	fromAccountNumber string,
 	toAccountNumber string,
 	amount int64,
+	transferType string,
 	requestID string,
-) error {
+	initiatedBy string,
+) (*TransferResult, error) {
 
 	if amount <= 0 {
-		return errors.New("amount must be positive")
+		return nil, errors.New("amount must be positive")
 	}
 
 	// Prevent self-transfer
 	if fromAccountNumber == toAccountNumber {
-		return errors.New("cannot transfer to the same account")
+		return nil, errors.New("cannot transfer to the same account")
+	}
+
+	if transferType == "" {
+		transferType = DefaultTransferType
 	}
+	if !slices.Contains(s.fees.TransferTypes(), transferType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTransferType, transferType)
+	}
+
+	return s.transfer(ctx, transferInput{
+		from:         fromAccountNumber,
+		to:           toAccountNumber,
+		amount:       amount,
+		transferType: transferType,
+		requestID:    requestID,
+		initiatedBy:  initiatedBy,
+	})
+}
+
+// transferInput is a transfer to execute. reviewID is set when executing
+// an approved review; the held transaction row is then settled in place
+// and screening is skipped.
+type transferInput struct {
+	from         string
+	to           string
+	amount       int64
+	transferType string
+	requestID    string
+	initiatedBy  string
+	reviewID     int64
+}
+
+func (s *WalletService) transfer(ctx context.Context, in transferInput) (*TransferResult, error) {
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		if err != nil {
@@ -47,62 +223,212 @@ func (s *WalletService) Transfer(
 		}
 	}()
 
+	// An approved review runs once: lock it and take the transfer, with
+	// any adjusted amount, from it
+	var review *Review
+	if in.reviewID != 0 {
+		review, err = s.repo.GetReviewForUpdate(ctx, tx, in.reviewID)
+		if err != nil {
+			return nil, err
+		}
+		if review.Status != ReviewApproved {
+			err = fmt.Errorf("%w: review %d is %s", ErrReviewNotApproved, review.ID, review.Status)
+			return nil, err
+		}
+		in.from = review.FromAccount
+		in.to = review.ToAccount
+		in.amount = review.Amount
+		in.transferType = review.Type
+		in.requestID = review.RequestID
+		review.Status = ReviewExecuted
+	}
+
+	// record writes the transfer row, settling the held row of a review
+	record := func(fromID, toID int64, status, failureReason string) error {
+		if review != nil {
+			if err := s.repo.SettleHeldTransaction(ctx, tx, in.requestID, in.amount, status, failureReason); err != nil {
+				return err
+			}
+			return s.repo.UpdateReview(ctx, tx, review)
+		}
+		return s.repo.CreateTransaction(
+			ctx,
+			tx,
+			fromID,
+			toID,
+			in.amount,
+			KindTransfer,
+			status,
+			failureReason,
+			in.requestID,
+		)
+	}
+
 	// Fetch accounts inside transaction
-	fromAccount, err := s.repo.GetAccountByNumberTx(ctx, tx, fromAccountNumber)
+	fromAccount, err := s.repo.GetAccountByNumberTx(ctx, tx, in.from)
 	if err != nil {
 		// Cannot create FK-safe transaction record if sender doesn't exist
-		return fmt.Errorf("from account not found")
+		return nil, fmt.Errorf("from account not found")
 	}
 
-	toAccount, err := s.repo.GetAccountByNumberTx(ctx, tx, toAccountNumber)
+	toAccount, err := s.repo.GetAccountByNumberTx(ctx, tx, in.to)
 	if err != nil {
 		// mark failed transaction and commit it
-		_ = s.repo.CreateTransaction(
-			ctx,
-			tx,
-			fromAccount.ID,
-			fromAccount.ID,
-			amount,
-			"failed",
-			requestID,
-		)
+		_ = record(fromAccount.ID, fromAccount.ID, "failed", FailureRecipientNotFound)
 		commitErr := tx.Commit()
 		if commitErr != nil {
-			return fmt.Errorf("to account not found, commit error: %w", commitErr)
+			return nil, fmt.Errorf("to account not found, commit error: %w", commitErr)
 		}
 		err = nil // Clear error so defer doesn't try to rollback
-		return fmt.Errorf("to account not found")
+		return nil, fmt.Errorf("to account not found")
+	}
+
+	result := s.price(fromAccount.AccountNumber, fromAccount.Tier, in.transferType, in.amount)
+	if result.TotalDebit < in.amount {
+		err = errors.New("fee overflows the transfer amount")
+		return nil, err
+	}
+
+	// The revenue account takes the fee; it may also be the recipient, but
+	// never the sender, which price exempts
+	var house *Account
+	if result.Fee > 0 {
+		if s.fees.RevenueAccount == toAccount.AccountNumber {
+			house = toAccount
+		} else {
+			var houseErr error
+			house, houseErr = s.repo.GetAccountByNumberTx(ctx, tx, s.fees.RevenueAccount)
+			if errors.Is(houseErr, sql.ErrNoRows) {
+				err = ErrRevenueAccountNotFound
+				return nil, err
+			}
+			if houseErr != nil {
+				err = houseErr
+				return nil, err
+			}
+		}
 	}
 
-	// Lock rows FOR UPDATE
-	fromLocked, err := s.repo.GetAccountForUpdateByID(ctx, tx, fromAccount.ID)
+	// Lock rows FOR UPDATE in ID order, so transfers between the same
+	// accounts in either direction, and payouts from the revenue account,
+	// cannot deadlock
+	ids := []int64{fromAccount.ID, toAccount.ID}
+	if house != nil {
+		ids = append(ids, house.ID)
+	}
+	locked, err := s.lockAccounts(ctx, tx, ids...)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	fromLocked, toLocked := locked[fromAccount.ID], locked[toAccount.ID]
+	var houseLocked *Account
+	if house != nil {
+		houseLocked = locked[house.ID]
+	}
+
+	// Frozen accounts neither send nor receive
+	if fromLocked.Frozen || toLocked.Frozen {
+		_ = record(fromLocked.ID, toLocked.ID, "failed", FailureAccountFrozen)
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			return nil, fmt.Errorf("%w, commit error: %w", ErrAccountFrozen, commitErr)
+		}
+		err = nil // Clear error so defer doesn't try to rollback
+		return nil, ErrAccountFrozen
 	}
 
-	toLocked, err := s.repo.GetAccountForUpdateByID(ctx, tx, toAccount.ID)
+	// Both holders are screened on every transfer; a new match may freeze
+	// the account and stop the transfer
+	frozen, err := s.screenParties(ctx, tx, in.requestID, fromAccount, toAccount)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if frozen {
+		_ = record(fromLocked.ID, toLocked.ID, "failed", FailureSanctionsHit)
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			return nil, fmt.Errorf("%w, commit error: %w", ErrSanctionsHit, commitErr)
+		}
+		err = nil // Clear error so defer doesn't try to rollback
+		return nil, ErrSanctionsHit
+	}
+
+	// Unverified senders may only move small amounts
+	if s.kyc.RequiresVerification(fromAccount.KYCTier, in.amount) {
+		_ = record(fromLocked.ID, toLocked.ID, "failed", FailureKYCRequired)
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			return nil, fmt.Errorf("%w, commit error: %w", ErrKYCRequired, commitErr)
+		}
+		err = nil // Clear error so defer doesn't try to rollback
+		return nil, ErrKYCRequired
+	}
+
+	// Limits are checked under the sender's row lock, so concurrent
+	// transfers from the same account cannot both slip under a limit
+	limitErr, err := s.checkLimits(ctx, tx, fromAccount, toAccount, toLocked.Balance, in.amount)
+	if err != nil {
+		return nil, err
+	}
+	if limitErr != nil {
+		_ = record(fromLocked.ID, toLocked.ID, "failed", limitErr.FailureReason())
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			return nil, fmt.Errorf("%w, commit error: %w", limitErr, commitErr)
+		}
+		err = nil // Clear error so defer doesn't try to rollback
+		return nil, limitErr
 	}
 
-	// Check balance
-	if fromLocked.Balance < amount {
+	// Large transfers and those flagged by risk screening wait for review;
+	// an approved review has been through both already
+	if review == nil {
+		var hold *Review
+		hold, err = s.screen(ctx, tx, in, fromAccount, toAccount)
+		if err != nil {
+			return nil, err
+		}
+
+		if hold != nil && hold.Status == ReviewRejected {
+			// Blocked outright by risk screening
+			_ = record(fromLocked.ID, toLocked.ID, "failed", FailureRiskBlocked)
+			commitErr := tx.Commit()
+			if commitErr != nil {
+				return nil, fmt.Errorf("%w, commit error: %w", ErrRiskBlocked, commitErr)
+			}
+			err = nil // Clear error so defer doesn't try to rollback
+			return nil, ErrRiskBlocked
+		}
+
+		if hold != nil {
+			err = record(fromLocked.ID, toLocked.ID, StatusReview, "")
+			if err != nil {
+				return nil, err
+			}
+			err = s.repo.CreateReview(ctx, tx, hold)
+			if err != nil {
+				return nil, err
+			}
+			commitErr := tx.Commit()
+			if commitErr != nil {
+				return nil, fmt.Errorf("%w, commit error: %w", ErrHeldForReview, commitErr)
+			}
+			err = nil // Clear error so defer doesn't try to rollback
+			return nil, ErrHeldForReview
+		}
+	}
+
+	// Check balance, fee included
+	if fromLocked.Balance < result.TotalDebit {
 		// Mark transaction as failed and commit
-		_ = s.repo.CreateTransaction(
-			ctx,
-			tx,
-			fromLocked.ID,
-			toLocked.ID,
-			amount,
-			"failed",
-			requestID,
-		)
+		_ = record(fromLocked.ID, toLocked.ID, "failed", FailureInsufficientFunds)
 		commitErr := tx.Commit()
 		if commitErr != nil {
-			return fmt.Errorf("insufficient funds, commit error: %w", commitErr)
+			return nil, fmt.Errorf("insufficient funds, commit error: %w", commitErr)
 		}
 		err = nil // Clear error so defer doesn't try to rollback
-		return errors.New("insufficient funds")
+		return nil, errors.New("insufficient funds")
 	}
 
 	// Update balances
@@ -110,43 +436,539 @@ func (s *WalletService) Transfer(
 		ctx,
 		tx,
 		fromLocked.ID,
-		fromLocked.Balance-amount,
+		fromLocked.Balance-result.TotalDebit,
 	)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	credit := in.amount
+	if houseLocked == toLocked {
+		credit += result.Fee
 	}
 
 	err = s.repo.UpdateBalance(
 		ctx,
 		tx,
 		toLocked.ID,
-		toLocked.Balance+amount,
+		toLocked.Balance+credit,
 	)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if houseLocked != nil && houseLocked != toLocked {
+		err = s.repo.UpdateBalance(
+			ctx,
+			tx,
+			houseLocked.ID,
+			houseLocked.Balance+result.Fee,
+		)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Mark completed
-	err = s.repo.CreateTransaction(
-		ctx,
-		tx,
-		fromLocked.ID,
-		toLocked.ID,
-		amount,
-		"completed",
-		requestID,
-	)
+	err = record(fromLocked.ID, toLocked.ID, "completed", "")
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if houseLocked != nil {
+		err = s.repo.CreateTransaction(
+			ctx,
+			tx,
+			fromLocked.ID,
+			houseLocked.ID,
+			result.Fee,
+			KindFee,
+			"completed",
+			"",
+			in.requestID,
+		)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Commit on success
 	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("commit failed: %w", err)
+		return nil, fmt.Errorf("commit failed: %w", err)
 	}
 	err = nil // Clear error so defer doesn't try to rollback
 
-	return nil
+	return result, nil
+}
+
+// screenParties screens the sender and recipient of a transfer, freezing
+// those the screener says to. It reports whether any party was frozen.
+func (s *WalletService) screenParties(
+	ctx context.Context,
+	tx *sql.Tx,
+	requestID string,
+	parties ...*Account,
+) (bool, error) {
+
+	if s.sanctions == nil {
+		return false, nil
+	}
+
+	frozen := false
+	for _, acc := range parties {
+		result, err := s.sanctions.Screen(ctx, tx, SanctionsSubject{
+			AccountNumber: acc.AccountNumber,
+			Name:          acc.Name,
+			Trigger:       ScreenTransfer,
+			RequestID:     requestID,
+		})
+		if err != nil {
+			return false, fmt.Errorf("sanctions screening: %w", err)
+		}
+		if !result.Freeze {
+			continue
+		}
+
+		if err := s.repo.SetAccountFrozen(ctx, tx, acc.AccountNumber, true); err != nil {
+			return false, err
+		}
+		frozen = true
+	}
+
+	return frozen, nil
+}
+
+// screen applies the review threshold and risk screening. It returns nil
+// to let the transfer proceed, or the review to hold it for; a review with
+// status rejected means risk screening blocked the transfer.
+func (s *WalletService) screen(
+	ctx context.Context,
+	tx *sql.Tx,
+	in transferInput,
+	from *Account,
+	to *Account,
+) (*Review, error) {
+
+	hold := &Review{
+		RequestID:   in.requestID,
+		FromAccount: from.AccountNumber,
+		ToAccount:   to.AccountNumber,
+		Amount:      in.amount,
+		Type:        in.transferType,
+		Status:      ReviewPending,
+		InitiatedBy: in.initiatedBy,
+	}
+
+	if s.risk != nil {
+		decision, err := s.risk.Evaluate(ctx, tx, RiskTransfer{
+			RequestID:         in.requestID,
+			FromAccountID:     from.ID,
+			FromAccountNumber: from.AccountNumber,
+			FromTier:          from.Tier,
+			ToAccountID:       to.ID,
+			ToAccountNumber:   to.AccountNumber,
+			Amount:            in.amount,
+			Type:              in.transferType,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("risk screening: %w", err)
+		}
+
+		switch decision.Action {
+		case RiskBlock:
+			hold.Status = ReviewRejected
+			return hold, nil
+		case RiskReview:
+			hold.Reason = ReviewReasonRisk
+			hold.RiskScore = decision.Score
+			return hold, nil
+		}
+	}
+
+	if s.reviewThreshold > 0 && in.amount > s.reviewThreshold {
+		hold.Reason = ReviewReasonThreshold
+		return hold, nil
+	}
+
+	return nil, nil
+}
+
+// checkLimits returns the limit a transfer of amount from acc would break:
+// the sender's pricing tier limits with its override, then the KYC limits
+// of the sender and the recipient, which holds recipientBalance. The
+// sender's row must already be locked.
+func (s *WalletService) checkLimits(
+	ctx context.Context,
+	tx *sql.Tx,
+	acc *Account,
+	recipient *Account,
+	recipientBalance int64,
+	amount int64,
+) (*LimitError, error) {
+
+	override, err := s.repo.GetLimitOverrideTx(ctx, tx, acc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := s.limits.ForTier(acc.Tier).Apply(override)
+	kycLimits := s.kyc.ForTier(acc.KYCTier)
+
+	var usage LimitUsage
+	if limits.needsUsage() || kycLimits.needsUsage() {
+		if usage, err = s.repo.GetOutgoingUsage(ctx, tx, acc.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var limitErr *LimitError
+	if errors.As(limits.Check(amount, usage), &limitErr) {
+		return limitErr, nil
+	}
+
+	recipientLimits := s.kyc.ForTier(recipient.KYCTier)
+	if errors.As(kycLimits.Check(amount, usage, recipientLimits, recipientBalance), &limitErr) {
+		return limitErr, nil
+	}
+
+	return nil, nil
+}
+
+// AccountLimits describes the limits that apply to an account
+type AccountLimits struct {
+	AccountNumber string         `json:"account_number"`
+	Tier          string         `json:"tier"`
+	TierLimits    Limits         `json:"tier_limits"`
+	Override      *LimitOverride `json:"override"`
+	Effective     Limits         `json:"effective"`
+}
+
+// GetLimits returns the tier limits, override and effective limits of an
+// account
+func (s *WalletService) GetLimits(ctx context.Context, accountNumber string) (*AccountLimits, error) {
+	acc, err := s.repo.GetAccountByNumber(ctx, accountNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	override, err := s.repo.GetLimitOverride(ctx, acc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tierLimits := s.limits.ForTier(acc.Tier)
+
+	return &AccountLimits{
+		AccountNumber: acc.AccountNumber,
+		Tier:          acc.Tier,
+		TierLimits:    tierLimits,
+		Override:      override,
+		Effective:     tierLimits.Apply(override),
+	}, nil
+}
+
+// SetLimitOverride replaces an account's limit override, or removes it
+// when override is nil
+func (s *WalletService) SetLimitOverride(
+	ctx context.Context,
+	accountNumber string,
+	override *LimitOverride,
+) (*AccountLimits, error) {
+
+	acc, err := s.repo.GetAccountByNumber(ctx, accountNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if override == nil {
+		err = s.repo.DeleteLimitOverride(ctx, acc.ID)
+	} else {
+		if err := override.Validate(); err != nil {
+			return nil, err
+		}
+		err = s.repo.SetLimitOverride(ctx, acc.ID, override)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetLimits(ctx, accountNumber)
+}
+
+//
+// KYC
+//
+
+// GetKYC returns an account's KYC tier, documents and tier history
+func (s *WalletService) GetKYC(ctx context.Context, accountNumber string) (*AccountKYC, error) {
+	acc, err := s.repo.GetAccountByNumber(ctx, accountNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := s.repo.ListKYCDocuments(ctx, acc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.ListKYCChanges(ctx, acc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountKYC{
+		AccountNumber: acc.AccountNumber,
+		Tier:          acc.KYCTier,
+		Limits:        s.kyc.ForTier(acc.KYCTier),
+		Documents:     docs,
+		History:       history,
+	}, nil
+}
+
+// AddKYCDocument records the metadata of a document checked by principal
+func (s *WalletService) AddKYCDocument(
+	ctx context.Context,
+	accountNumber string,
+	doc *KYCDocument,
+	principal string,
+) (*KYCDocument, error) {
+
+	if principal == "" {
+		return nil, ErrPrincipalRequired
+	}
+	if doc.Type == "" || doc.Reference == "" {
+		return nil, ErrKYCDocumentIncomplete
+	}
+
+	acc, err := s.repo.GetAccountByNumber(ctx, accountNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc.RecordedBy = principal
+	if err := s.repo.AddKYCDocument(ctx, acc.ID, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// SetKYCTier moves an account to tier on principal's authority. Every
+// change is recorded; verifying an account needs a document on file.
+func (s *WalletService) SetKYCTier(
+	ctx context.Context,
+	accountNumber string,
+	tier string,
+	principal string,
+	reason string,
+) (*AccountKYC, error) {
+
+	if principal == "" {
+		return nil, ErrPrincipalRequired
+	}
+	if !IsKYCTier(tier) {
+		return nil, fmt.Errorf("%w %q", ErrInvalidKYCTier, tier)
+	}
+
+	acc, err := s.repo.GetAccountByNumber(ctx, accountNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if acc.KYCTier == tier {
+		return s.GetKYC(ctx, accountNumber)
+	}
+
+	if tier != KYCUnverified {
+		docs, err := s.repo.ListKYCDocuments(ctx, acc.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(docs) == 0 {
+			return nil, ErrKYCDocumentRequired
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	change := &KYCChange{
+		FromTier:  acc.KYCTier,
+		ToTier:    tier,
+		ChangedBy: principal,
+		Reason:    reason,
+	}
+	if err := s.repo.SetKYCTier(ctx, tx, acc.ID, change); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return s.GetKYC(ctx, accountNumber)
+}
+
+//
+// Manual review
+//
+
+// ListReviews returns up to limit reviews oldest first; an empty status
+// lists all of them
+func (s *WalletService) ListReviews(ctx context.Context, status string, limit int) ([]Review, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListReviews(ctx, status, limit)
+}
+
+func (s *WalletService) GetReview(ctx context.Context, id int64) (*Review, error) {
+	return s.repo.GetReview(ctx, id)
+}
+
+// AdjustReview changes the amount of a pending review. The adjuster is
+// recorded and may no longer approve it.
+func (s *WalletService) AdjustReview(
+	ctx context.Context,
+	id int64,
+	amount int64,
+	principal string,
+) (*Review, error) {
+
+	if amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	return s.decideReview(ctx, id, principal, func(review *Review) (string, error) {
+		review.Amount = amount
+		if !slices.Contains(review.AdjustedBy, principal) {
+			review.AdjustedBy = append(review.AdjustedBy, principal)
+		}
+		return StatusReview, nil
+	})
+}
+
+// ApproveReview approves a pending review for execution by the worker
+// pool. The approver must be someone other than the initiator and anyone
+// who adjusted the transfer.
+func (s *WalletService) ApproveReview(
+	ctx context.Context,
+	id int64,
+	principal string,
+	note string,
+) (*Review, error) {
+
+	return s.decideReview(ctx, id, principal, func(review *Review) (string, error) {
+		if err := review.canApprove(principal); err != nil {
+			return "", err
+		}
+		review.decide(ReviewApproved, principal, note)
+		return StatusReview, nil
+	})
+}
+
+// RejectReview rejects a pending review; its held transfer is failed with
+// FailureReviewRejected and no money moves
+func (s *WalletService) RejectReview(
+	ctx context.Context,
+	id int64,
+	principal string,
+	note string,
+) (*Review, error) {
+
+	return s.decideReview(ctx, id, principal, func(review *Review) (string, error) {
+		review.decide(ReviewRejected, principal, note)
+		return "failed", nil
+	})
+}
+
+// decideReview locks a pending review, applies change and saves the
+// review together with its held transaction, which change leaves in the
+// status it returns
+func (s *WalletService) decideReview(
+	ctx context.Context,
+	id int64,
+	principal string,
+	change func(review *Review) (string, error),
+) (*Review, error) {
+
+	if principal == "" {
+		return nil, ErrPrincipalRequired
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	review, err := s.repo.GetReviewForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != ReviewPending {
+		return nil, fmt.Errorf("%w: review %d is %s", ErrReviewNotPending, review.ID, review.Status)
+	}
+
+	status, err := change(review)
+	if err != nil {
+		return nil, err
+	}
+
+	failureReason := ""
+	if review.Status == ReviewRejected {
+		failureReason = FailureReviewRejected
+	}
+
+	if status != StatusReview {
+		err = s.repo.SettleHeldTransaction(ctx, tx, review.RequestID, review.Amount, status, failureReason)
+	} else {
+		err = s.repo.UpdateHeldTransaction(ctx, tx, review.RequestID, review.Amount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateReview(ctx, tx, review); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return review, nil
+}
+
+// ClaimApprovedReviews returns up to limit approved reviews for the
+// worker pool to execute, marking them dispatched
+func (s *WalletService) ClaimApprovedReviews(ctx context.Context, limit int) ([]Review, error) {
+	return s.repo.ClaimApprovedReviews(ctx, limit)
+}
+
+// ExecuteReview runs the transfer of an approved review. Limits and the
+// balance are checked again, but not the review threshold or risk
+// screening. The held transaction row is settled in place.
+func (s *WalletService) ExecuteReview(ctx context.Context, id int64) (*TransferResult, error) {
+	return s.transfer(ctx, transferInput{reviewID: id})
 }
 
 //
@@ -177,9 +999,20 @@ func (s *WalletService) MarkTransactionCompleted(
 	)
 }
 
-// CreateAccount creates a new account via repository
+// CreateAccount creates a new account via repository. The holder is
+// screened first, so a sanctions match can create the account frozen.
 func (s *WalletService) CreateAccount(ctx context.Context, acc *Account) error {
-	return s.repo.CreateAccount(ctx, acc)
+	if acc.Tier == "" {
+		acc.Tier = DefaultTier
+	}
+	if !slices.Contains(s.AccountTiers(), acc.Tier) {
+		return fmt.Errorf("%w: %s", ErrUnknownTier, acc.Tier)
+	}
+
+	// Accounts are verified through SetKYCTier, never on creation
+	acc.KYCTier = KYCUnverified
+
+	return s.saveScreened(ctx, acc, ScreenAccountCreate, s.repo.CreateAccount)
 }
 
 // GetAccountByNumber fetches account by account number
@@ -187,9 +1020,172 @@ func (s *WalletService) GetAccountByNumber(ctx context.Context, accountNumber st
 	return s.repo.GetAccountByNumber(ctx, accountNumber)
 }
 
-// UpdateAccount updates an existing account
+// FindAccountsByEmail returns the accounts registered with email
+func (s *WalletService) FindAccountsByEmail(ctx context.Context, email string) ([]Account, error) {
+	return s.repo.FindAccountsByEmail(ctx, email)
+}
+
+// FindAccountsByPhone returns the accounts registered with phone
+func (s *WalletService) FindAccountsByPhone(ctx context.Context, phone string) ([]Account, error) {
+	return s.repo.FindAccountsByPhone(ctx, phone)
+}
+
+// RewrapPII moves up to limit accounts onto the active PII key and
+// returns how many it changed
+func (s *WalletService) RewrapPII(ctx context.Context, limit int) (int, error) {
+	return s.repo.RewrapPII(ctx, limit)
+}
+
+// UpdateAccount replaces an existing account; acc.Version must match the stored version
 func (s *WalletService) UpdateAccount(ctx context.Context, acc *Account) error {
-	return s.repo.UpdateAccount(ctx, acc)
+	return s.saveScreened(ctx, acc, ScreenAccountUpdate, s.repo.UpdateAccount)
+}
+
+// saveScreened screens the account holder and saves the account with
+// save. Alerts are only committed once the account is saved.
+func (s *WalletService) saveScreened(
+	ctx context.Context,
+	acc *Account,
+	trigger string,
+	save func(ctx context.Context, acc *Account) error,
+) error {
+
+	if s.sanctions == nil {
+		return save(ctx, acc)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := s.sanctions.Screen(ctx, tx, SanctionsSubject{
+		AccountNumber: acc.AccountNumber,
+		Name:          acc.Name,
+		Trigger:       trigger,
+	})
+	if err != nil {
+		return fmt.Errorf("sanctions screening: %w", err)
+	}
+
+	// A new account is created frozen; an existing one is frozen after
+	// the update, which never touches the flag itself
+	if result.Freeze && trigger == ScreenAccountCreate {
+		acc.Frozen = true
+	}
+
+	if err := save(ctx, acc); err != nil {
+		return err
+	}
+
+	if result.Freeze && trigger != ScreenAccountCreate {
+		if err := s.repo.SetAccountFrozen(ctx, tx, acc.AccountNumber, true); err != nil {
+			return err
+		}
+		acc.Frozen = true
+		acc.Version++ // freezing bumps the version
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// SetAccountFrozen freezes or unfreezes an account by hand, e.g. after a
+// sanctions alert was dismissed
+func (s *WalletService) SetAccountFrozen(ctx context.Context, accountNumber string, frozen bool) (*Account, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.repo.SetAccountFrozen(ctx, tx, accountNumber, frozen); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return s.repo.GetAccountByNumber(ctx, accountNumber)
+}
+
+// AccountTiers lists the pricing tiers an account may be in: the default
+// and every tier the fee schedule or limit policy names
+func (s *WalletService) AccountTiers() []string {
+	tiers := append(s.fees.tiers(), s.limits.tiers()...)
+	slices.Sort(tiers)
+	tiers = slices.DeleteFunc(slices.Compact(tiers), func(t string) bool { return t == DefaultTier })
+	return append([]string{DefaultTier}, tiers...)
+}
+
+// TransferTypes lists the transfer types the fee schedule prices
+func (s *WalletService) TransferTypes() []string {
+	return s.fees.TransferTypes()
+}
+
+// SetAccountTier moves an account to another pricing tier, which selects
+// its fees and limits. Only staff may do this.
+func (s *WalletService) SetAccountTier(ctx context.Context, accountNumber, tier string) (*Account, error) {
+	if !slices.Contains(s.AccountTiers(), tier) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTier, tier)
+	}
+
+	if err := s.repo.SetAccountTier(ctx, accountNumber, tier); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetAccountByNumber(ctx, accountNumber)
+}
+
+// PatchAccount applies a partial update to the account, provided it is
+// still at the given version. It returns the account as stored afterwards.
+func (s *WalletService) PatchAccount(
+	ctx context.Context,
+	accountNumber string,
+	patch AccountPatch,
+	version int64,
+) (*Account, error) {
+
+	acc, err := s.repo.GetAccountByNumber(ctx, accountNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if acc.ErasedAt != nil {
+		return nil, ErrAccountErased
+	}
+
+	// Fail fast; the conditional UPDATE still guards against races
+	if acc.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	if patch.Name != nil {
+		acc.Name = *patch.Name
+	}
+	if patch.Email != nil {
+		acc.Email = *patch.Email
+	}
+	if patch.Phone != nil {
+		acc.Phone = *patch.Phone
+	}
+	if patch.DOB != nil {
+		acc.DOB = *patch.DOB
+	}
+
+	if err := s.saveScreened(ctx, acc, ScreenAccountUpdate, s.repo.UpdateAccount); err != nil {
+		return nil, err
+	}
+
+	return acc, nil
 }
 
 // DeleteAccount deletes an account by account number
@@ -0,0 +1,53 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+)
+
+// lockRecorder records the order in which rows are locked
+type lockRecorder struct {
+	WalletRepository
+	order []int64
+}
+
+func (r *lockRecorder) GetAccountForUpdateByID(ctx context.Context, tx *sql.Tx, accountID int64) (*Account, error) {
+	r.order = append(r.order, accountID)
+	return &Account{ID: accountID}, nil
+}
+
+func TestLockAccountsOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []int64
+		want []int64
+	}{
+		// A fee transfer A→B and a payout House→A must take A and House
+		// in the same order
+		{"fee transfer", []int64{7, 9, 3}, []int64{3, 7, 9}},
+		{"payout from revenue", []int64{3, 7}, []int64{3, 7}},
+		{"revenue is recipient", []int64{7, 3, 3}, []int64{3, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &lockRecorder{}
+			s := &WalletService{repo: repo}
+
+			locked, err := s.lockAccounts(context.Background(), nil, tt.ids...)
+			if err != nil {
+				t.Fatalf("lockAccounts: %v", err)
+			}
+			if !slices.Equal(repo.order, tt.want) {
+				t.Errorf("locked %v, want %v", repo.order, tt.want)
+			}
+			for _, id := range tt.ids {
+				if locked[id] == nil || locked[id].ID != id {
+					t.Errorf("account %d missing from result", id)
+				}
+			}
+		})
+	}
+}
@@ -5,4 +5,12 @@ type TransferJob struct {
 	FromAccountNumber string
 	ToAccountNumber   string
 	Amount            int64
+	Type              string
+
+	// Principal is who initiated the transfer, for review
+	Principal string
+
+	// ReviewID is set to execute an approved review rather than a new
+	// transfer; the other fields are then informational
+	ReviewID int64
 }
@@ -2,14 +2,21 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"time"
 
 	"gopherpay/internal/wallet"
 )
 
+// reviewPollInterval is how often approved reviews are picked up when
+// nothing wakes the dispatcher sooner
+const reviewPollInterval = 5 * time.Second
+
 type WorkerPool struct {
 	JobQueue chan TransferJob
 	service  *wallet.WalletService
+	wake     chan struct{}
 }
 
 func NewWorkerPool(
@@ -20,6 +27,7 @@ func NewWorkerPool(
 	return &WorkerPool{
 		JobQueue: make(chan TransferJob, bufferSize),
 		service:  service,
+		wake:     make(chan struct{}, 1),
 	}
 }
 
@@ -33,15 +41,30 @@ func (wp *WorkerPool) Start(ctx context.Context, workerCount int) {
 
 			for job := range wp.JobQueue {
 
-				err := wp.service.Transfer(
+				if job.ReviewID != 0 {
+					wp.executeReview(ctx, workerID, job)
+					continue
+				}
+
+				result, err := wp.service.Transfer(
 					ctx,
 					job.FromAccountNumber,
 					job.ToAccountNumber,
 					job.Amount,
+					job.Type,
 					job.RequestID,
+					job.Principal,
 				)
 
-				if err != nil {
+				if errors.Is(err, wallet.ErrHeldForReview) {
+
+					// Stored with status review; nothing to update
+					slog.Info(
+						"transfer held for review",
+						"request_id", job.RequestID,
+					)
+
+				} else if err != nil {
 
 					slog.Error(
 						"transfer failed",
@@ -65,12 +88,98 @@ func (wp *WorkerPool) Start(ctx context.Context, workerCount int) {
 					slog.Info(
 						"transfer completed",
 						"request_id", job.RequestID,
+						"fee", result.Fee,
 					)
 				}
 			}
 
 		}(i)
 	}
+
+	go wp.dispatchReviews(ctx)
+}
+
+// executeReview runs an approved review. The outcome is recorded on the
+// held transaction by the service, so there is nothing to mark here.
+func (wp *WorkerPool) executeReview(ctx context.Context, workerID int, job TransferJob) {
+	result, err := wp.service.ExecuteReview(ctx, job.ReviewID)
+	if err != nil {
+		slog.Error(
+			"reviewed transfer failed",
+			"worker_id", workerID,
+			"review_id", job.ReviewID,
+			"request_id", job.RequestID,
+			"error", err,
+		)
+		return
+	}
+
+	slog.Info(
+		"reviewed transfer completed",
+		"review_id", job.ReviewID,
+		"request_id", job.RequestID,
+		"fee", result.Fee,
+	)
+}
+
+// WakeDispatcher makes the pool look for approved reviews now rather than
+// at the next poll
+func (wp *WorkerPool) WakeDispatcher() {
+	select {
+	case wp.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchReviews hands approved reviews to the workers. Approvals may
+// come from another process, such as the admin CLI, so the database is
+// polled. Only as many reviews are claimed as the queue has room for; a
+// claimed review that never runs is claimed again after a while.
+func (wp *WorkerPool) dispatchReviews(ctx context.Context) {
+	ticker := time.NewTicker(reviewPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wp.wake:
+		}
+
+		current, capacity := wp.GetQueueLoad()
+		free := capacity - current
+		if free <= 0 {
+			continue
+		}
+
+		reviews, err := wp.service.ClaimApprovedReviews(ctx, free)
+		if err != nil {
+			slog.Error("claim approved reviews failed", "error", err)
+			continue
+		}
+
+		for _, review := range reviews {
+			job := TransferJob{
+				RequestID:         review.RequestID,
+				FromAccountNumber: review.FromAccount,
+				ToAccountNumber:   review.ToAccount,
+				Amount:            review.Amount,
+				Type:              review.Type,
+				Principal:         review.InitiatedBy,
+				ReviewID:          review.ID,
+			}
+
+			select {
+			case wp.JobQueue <- job:
+				slog.Info("approved review dispatched", "review_id", review.ID, "request_id", review.RequestID)
+			default:
+				// The API filled the queue meanwhile; the claim expires
+				// and the review is dispatched again later
+				slog.Warn("transfer queue full, review deferred", "review_id", review.ID)
+			}
+		}
+	}
 }
 
 // GetQueueLoad returns the current number of jobs in the queue and capacity
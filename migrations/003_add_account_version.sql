@@ -0,0 +1,3 @@
+-- optimistic concurrency version for accounts
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,9 @@
+-- keyset pagination indexes for transaction listings
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at_id
+    ON transactions(created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_from_created_at_id
+    ON transactions(from_account_id, created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_to_created_at_id
+    ON transactions(to_account_id, created_at, id);
@@ -0,0 +1,33 @@
+-- scheduled report jobs and their run history
+CREATE TABLE IF NOT EXISTS report_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    cron_expr TEXT NOT NULL,
+    format TEXT NOT NULL DEFAULT 'csv',
+    account_filter TEXT NOT NULL DEFAULT '',
+    period TEXT NOT NULL DEFAULT 'previous_month',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS report_schedule_runs (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL,
+    scheduled_for TIMESTAMP NOT NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP,
+    status TEXT NOT NULL,
+    statements INT NOT NULL DEFAULT 0,
+    output_dir TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+
+    CONSTRAINT fk_schedule
+        FOREIGN KEY(schedule_id)
+        REFERENCES report_schedules(id)
+        ON DELETE CASCADE,
+
+    -- one run per schedule slot, even with several server instances
+    CONSTRAINT uq_schedule_slot UNIQUE (schedule_id, scheduled_for)
+);
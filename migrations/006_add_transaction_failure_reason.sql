@@ -0,0 +1,7 @@
+-- why a transfer failed; NULL for completed transfers and older rows
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS failure_reason TEXT;
+
+-- summary reports aggregate by period across all accounts
+CREATE INDEX IF NOT EXISTS idx_transactions_status_created_at
+    ON transactions(status, created_at);
@@ -0,0 +1,18 @@
+-- asynchronous statement exports requested over the API
+CREATE TABLE IF NOT EXISTS report_jobs (
+    id UUID PRIMARY KEY,
+    account_number TEXT NOT NULL,
+    format TEXT NOT NULL,
+    period_from TIMESTAMP,
+    period_to TIMESTAMP,
+    status TEXT NOT NULL,
+    object_name TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+-- workers claim the oldest queued job
+CREATE INDEX IF NOT EXISTS idx_report_jobs_status_created_at
+    ON report_jobs(status, created_at);
@@ -0,0 +1,7 @@
+-- pricing tier used to pick an account's fee schedule
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS tier TEXT NOT NULL DEFAULT 'standard';
+
+-- fees are booked as their own rows, sharing the transfer's request_id
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'transfer';
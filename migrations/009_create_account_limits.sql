@@ -0,0 +1,14 @@
+-- per-account overrides of the tier transfer limits; NULL inherits
+CREATE TABLE IF NOT EXISTS account_limits (
+    account_id BIGINT PRIMARY KEY,
+    max_amount BIGINT,
+    daily_total BIGINT,
+    monthly_total BIGINT,
+    hourly_count BIGINT,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_account
+        FOREIGN KEY(account_id)
+        REFERENCES accounts(id)
+        ON DELETE CASCADE
+);
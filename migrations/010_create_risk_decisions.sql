@@ -0,0 +1,18 @@
+-- risk screening outcome for every screened transfer
+CREATE TABLE IF NOT EXISTS risk_decisions (
+    id BIGSERIAL PRIMARY KEY,
+    request_id TEXT,
+    from_account_id BIGINT NOT NULL,
+    to_account_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    action TEXT NOT NULL,
+    score INT NOT NULL,
+    hits JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_risk_decisions_request_id
+    ON risk_decisions(request_id);
+
+CREATE INDEX IF NOT EXISTS idx_risk_decisions_action_created_at
+    ON risk_decisions(action, created_at);
@@ -0,0 +1,24 @@
+-- transfers held for manual review; the transaction row stays in status
+-- review until an approved review is executed or the review is rejected
+CREATE TABLE IF NOT EXISTS transfer_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    request_id TEXT NOT NULL UNIQUE,
+    from_account TEXT NOT NULL,
+    to_account TEXT NOT NULL,
+    amount BIGINT NOT NULL,
+    type TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    risk_score INT NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'pending',
+    initiated_by TEXT NOT NULL DEFAULT '',
+    adjusted_by JSONB NOT NULL DEFAULT '[]',
+    decided_by TEXT,
+    note TEXT,
+    decided_at TIMESTAMP,
+    dispatched_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_reviews_status_created_at
+    ON transfer_reviews(status, created_at);
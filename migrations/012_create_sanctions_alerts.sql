@@ -0,0 +1,31 @@
+-- frozen accounts can neither send nor receive transfers
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS frozen BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- sanctions list matches awaiting or after compliance review; keyed by
+-- account number since accounts are screened before they are created
+CREATE TABLE IF NOT EXISTS sanctions_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    account_number TEXT NOT NULL,
+    screened_name TEXT NOT NULL,
+    trigger TEXT NOT NULL,
+    request_id TEXT,
+    entry_uid TEXT NOT NULL,
+    entry_name TEXT NOT NULL,
+    matched_name TEXT NOT NULL,
+    programs TEXT NOT NULL DEFAULT '',
+    score NUMERIC(4, 3) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    resolved_by TEXT,
+    note TEXT,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- at most one open alert per account and listed entry
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sanctions_alerts_open
+    ON sanctions_alerts(account_number, entry_uid)
+    WHERE status = 'open';
+
+CREATE INDEX IF NOT EXISTS idx_sanctions_alerts_status_created_at
+    ON sanctions_alerts(status, created_at);
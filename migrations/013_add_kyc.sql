@@ -0,0 +1,42 @@
+-- KYC verification tier; separate from the pricing tier
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS kyc_tier TEXT NOT NULL DEFAULT 'unverified';
+
+-- metadata of the documents an account was verified with
+CREATE TABLE IF NOT EXISTS kyc_documents (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    doc_type TEXT NOT NULL,
+    reference TEXT NOT NULL,
+    issuing_country TEXT,
+    expires_at DATE,
+    recorded_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_account
+        FOREIGN KEY(account_id)
+        REFERENCES accounts(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_documents_account_id
+    ON kyc_documents(account_id);
+
+-- audit trail of KYC tier changes
+CREATE TABLE IF NOT EXISTS kyc_tier_changes (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    from_tier TEXT NOT NULL,
+    to_tier TEXT NOT NULL,
+    changed_by TEXT NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_account
+        FOREIGN KEY(account_id)
+        REFERENCES accounts(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_tier_changes_account_id
+    ON kyc_tier_changes(account_id, created_at);
@@ -0,0 +1,20 @@
+-- encrypted date of birth; dob is cleared once an account is encrypted
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS dob_enc TEXT;
+
+-- blind indexes (keyed HMACs) for exact email and phone lookups
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS email_bidx TEXT;
+
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS phone_bidx TEXT;
+
+-- key the PII was encrypted with; NULL while still plaintext
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS pii_key_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_accounts_email_bidx
+ON accounts(email_bidx);
+
+CREATE INDEX IF NOT EXISTS idx_accounts_phone_bidx
+ON accounts(phone_bidx);
@@ -0,0 +1,21 @@
+-- set when the holder's PII was pseudonymized; the row and its
+-- transactions are kept for record retention
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS erased_at TIMESTAMP;
+
+-- who erased which account and why; never holds the erased values
+CREATE TABLE IF NOT EXISTS account_erasures (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    erased_by TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_account
+        FOREIGN KEY(account_id)
+        REFERENCES accounts(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_erasures_account
+ON account_erasures(account_id);
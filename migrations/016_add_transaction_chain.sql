@@ -0,0 +1,33 @@
+-- tamper-evident hash chain over transactions; rows are sealed in
+-- chain_seq order when written, held transfers when they are settled
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS chain_seq BIGINT UNIQUE;
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS prev_hash TEXT;
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS row_hash TEXT;
+
+-- the head of the chain; a single row, locked while a row is sealed.
+-- first_id is the last transaction that existed before the chain, so
+-- older rows are not expected to be sealed
+CREATE TABLE IF NOT EXISTS transaction_chain (
+    id INT PRIMARY KEY CHECK (id = 1),
+    seq BIGINT NOT NULL DEFAULT 0,
+    head_hash TEXT NOT NULL,
+    first_id BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO transaction_chain (id, seq, head_hash, first_id)
+SELECT 1, 0, repeat('0', 64), COALESCE(MAX(id), 0)
+FROM transactions
+ON CONFLICT (id) DO NOTHING;
+
+-- periodic checkpoints of the head, exported to be kept elsewhere
+CREATE TABLE IF NOT EXISTS transaction_chain_anchors (
+    seq BIGINT PRIMARY KEY,
+    hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
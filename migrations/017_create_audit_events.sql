@@ -0,0 +1,49 @@
+-- append-only log of administrative actions, written by the API and the
+-- admin CLI; sensitive values in diff are redacted
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    diff JSONB,
+    details JSONB,
+    request_id TEXT,
+    source_ip TEXT,
+    source TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_target
+ON audit_events(target_type, target_id, id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor
+ON audit_events(actor, id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_action
+ON audit_events(action, id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at
+ON audit_events(created_at);
+
+-- nobody may change or remove an event, the table owner included
+REVOKE UPDATE, DELETE, TRUNCATE ON audit_events FROM PUBLIC;
+REVOKE UPDATE, DELETE, TRUNCATE ON audit_events FROM CURRENT_USER;
+
+-- the owner could grant itself the privileges back, so the triggers
+-- refuse the changes as well
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_no_change ON audit_events;
+CREATE TRIGGER audit_events_no_change
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
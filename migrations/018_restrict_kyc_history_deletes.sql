@@ -0,0 +1,15 @@
+-- KYC documents and tier changes are compliance records; deleting an
+-- account must not take them with it
+ALTER TABLE kyc_documents
+    DROP CONSTRAINT IF EXISTS fk_account,
+    ADD CONSTRAINT fk_account
+        FOREIGN KEY(account_id)
+        REFERENCES accounts(id)
+        ON DELETE RESTRICT;
+
+ALTER TABLE kyc_tier_changes
+    DROP CONSTRAINT IF EXISTS fk_account,
+    ADD CONSTRAINT fk_account
+        FOREIGN KEY(account_id)
+        REFERENCES accounts(id)
+        ON DELETE RESTRICT;
@@ -0,0 +1,7 @@
+-- the erasure record must outlive the account it describes
+ALTER TABLE account_erasures
+    DROP CONSTRAINT IF EXISTS fk_account,
+    ADD CONSTRAINT fk_account
+        FOREIGN KEY(account_id)
+        REFERENCES accounts(id)
+        ON DELETE RESTRICT;